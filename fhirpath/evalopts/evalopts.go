@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/opts"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
 	"github.com/verily-src/fhirpath-go/internal/fhir"
@@ -55,6 +56,104 @@ func EnvVariable(name string, value any) opts.EvaluateOption {
 	})
 }
 
+// TraceSink is the destination of the collections logged by the FHIRPath
+// trace() function. Trace is called with the name given to trace() and the
+// traced collection.
+type TraceSink = expr.TraceSink
+
+// Trace returns an EvaluateOption that sends the output of trace() calls in
+// the evaluated expression to the given sink. By default, traces are discarded.
+func Trace(sink TraceSink) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		cfg.Context.TraceSink = sink
+		return nil
+	})
+}
+
+// Resolver locates the resources referred to by the FHIRPath resolve()
+// function. Implementations for common sources of resources are provided by
+// the resolver package.
+type Resolver = expr.Resolver
+
+// Resolve returns an EvaluateOption that uses the given Resolver to locate the
+// targets of References and canonical URLs passed to resolve(). By default,
+// nothing is resolved.
+func Resolve(resolver Resolver) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		cfg.Context.Resolver = resolver
+		return nil
+	})
+}
+
+// TerminologyProvider answers the terminology queries of FHIRPath functions
+// such as memberOf(). An in-memory implementation is provided by the
+// terminology package.
+type TerminologyProvider = expr.TerminologyProvider
+
+// SubsumptionOutcome is the relationship between two codes that is returned
+// by TerminologyProvider.Subsumes.
+type SubsumptionOutcome = expr.SubsumptionOutcome
+
+// The codes of the concept-subsumption-outcome value set.
+const (
+	SubsumptionEquivalent  = expr.SubsumptionEquivalent
+	SubsumptionSubsumes    = expr.SubsumptionSubsumes
+	SubsumptionSubsumedBy  = expr.SubsumptionSubsumedBy
+	SubsumptionNotSubsumed = expr.SubsumptionNotSubsumed
+)
+
+// Terminology returns an EvaluateOption that uses the given provider for
+// terminology functions. Without a provider, these functions raise an error.
+func Terminology(provider TerminologyProvider) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		cfg.Context.Terminology = provider
+		return nil
+	})
+}
+
+// FHIRServer is the FHIR server queried by the methods of the %server
+// constant, such as %server.read(). An in-memory implementation is provided by
+// the server package.
+type FHIRServer = expr.FHIRServer
+
+// Server returns an EvaluateOption that uses the given FHIRServer for the
+// methods of %server. Without a server, these methods raise an error.
+func Server(server FHIRServer) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		cfg.Context.Server = server
+		return nil
+	})
+}
+
+// ProfileValidator validates elements against the profiles of the FHIRPath
+// conformsTo() function. An implementation over local StructureDefinition
+// resources is provided by the profile package.
+type ProfileValidator = expr.ProfileValidator
+
+// Validator returns an EvaluateOption that uses the given ProfileValidator for
+// the conformsTo() function. Without a validator, conformsTo() raises an error.
+func Validator(validator ProfileValidator) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		cfg.Context.Validator = validator
+		return nil
+	})
+}
+
+// StructureDefinitions provides the slices and element definitions of the
+// FHIRPath slice() and elementDefinition() functions. An implementation over
+// local StructureDefinition resources is provided by the profile package.
+type StructureDefinitions = expr.StructureDefinitions
+
+// Structures returns an EvaluateOption that uses the given
+// StructureDefinitions for the slice() and elementDefinition() functions.
+// Without them, these functions raise an error.
+func Structures(structures StructureDefinitions) opts.EvaluateOption {
+	return opts.Transform(func(cfg *opts.EvaluateConfig) error {
+		cfg.Context.Structures = structures
+		return nil
+	})
+}
+
 // validateType validates that the input type is a supported
 // fhir proto or System type. If a system.Collection is passed in,
 // recursively checks each element.
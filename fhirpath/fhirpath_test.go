@@ -7,26 +7,42 @@ import (
 
 	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	clpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/claim_go_proto"
+	cspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/code_system_go_proto"
+	cmpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/concept_map_go_proto"
+	condpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
 	drpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/document_reference_go_proto"
 	epb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/encounter_go_proto"
 	lpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/list_go_proto"
 	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
 	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
 	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
 	prpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/practitioner_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
 	tpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/task_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/shopspring/decimal"
 	"github.com/verily-src/fhirpath-go/fhirpath"
 	"github.com/verily-src/fhirpath-go/fhirpath/compopts"
 	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
+	"github.com/verily-src/fhirpath-go/fhirpath/profile"
+	"github.com/verily-src/fhirpath-go/fhirpath/resolver"
+	"github.com/verily-src/fhirpath-go/fhirpath/server"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/fhirpath/terminology"
+	"github.com/verily-src/fhirpath-go/internal/bundle"
+	"github.com/verily-src/fhirpath-go/internal/containedresource"
 	"github.com/verily-src/fhirpath-go/internal/element/extension"
 	"github.com/verily-src/fhirpath-go/internal/element/reference"
 	"github.com/verily-src/fhirpath-go/internal/fhir"
 	"github.com/verily-src/fhirpath-go/internal/fhirconv"
+	"github.com/verily-src/fhirpath-go/internal/resource"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 type evaluateTestCase struct {
@@ -236,6 +252,12 @@ func TestEvaluate_PathSelection_ReturnsResult(t *testing.T) {
 			inputCollection: []fhir.Resource{patientVoldemort},
 			wantCollection:  system.Collection{nameVoldemort},
 		},
+		{
+			name:            "Delimited identifiers select fields",
+			inputPath:       "Patient.`name`.`given`",
+			inputCollection: []fhir.Resource{patientVoldemort},
+			wantCollection:  system.Collection{fhir.String("Lord")},
+		},
 		{
 			name:            "Extension with resource type returns extensions",
 			inputPath:       "Patient.extension",
@@ -704,6 +726,67 @@ func TestEvaluateEquality_ReturnsBoolean(t *testing.T) {
 	testEvaluate(t, testCases)
 }
 
+func TestEvaluateEquivalence_ReturnsBoolean(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "strings match ignoring case",
+			inputPath:       "Patient.name[0].family ~ 'CHU'",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "strings match with normalized whitespace",
+			inputPath:       "'  hello   world ' ~ 'Hello World'",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "decimals compare at lesser precision",
+			inputPath:       "1.2 / 1.8 ~ 0.67",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "mismatched date precision returns false",
+			inputPath:       "@2000-01 ~ @2000-01-03",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "empty collections are equivalent",
+			inputPath:       "Patient.maritalStatus ~ {}",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "empty collection is not equivalent to a value",
+			inputPath:       "Patient.maritalStatus ~ 'married'",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "collections compare independent of order",
+			inputPath:       "Patient.name.given ~ ('kang' | 'senpai')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "complex types compare recursively",
+			inputPath:       "Patient.name[0].given ~ Patient.contact.name.given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "inequivalence negates result",
+			inputPath:       "Patient.name[0] !~ Patient.name[1]",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
 func TestParenthesizedExpression_MaintainsPrecedence(t *testing.T) {
 	patient := &ppb.Patient{
 		Name: []*dtpb.HumanName{
@@ -956,14 +1039,12 @@ func TestFunctionInvocation_Evaluates(t *testing.T) {
 			inputPath:       "name.family.value.join('-')",
 			inputCollection: []fhir.Resource{patientChu},
 			wantCollection:  system.Collection{system.String("Chu-Chu")},
-			compileOptions:  []fhirpath.CompileOption{compopts.WithExperimentalFuncs()},
 		},
 		{
 			name:            "returns concatenated family name with join()",
 			inputPath:       "name.family.join('-')",
 			inputCollection: []fhir.Resource{patientChu},
 			wantCollection:  system.Collection{system.String("Chu-Chu")},
-			compileOptions:  []fhirpath.CompileOption{compopts.WithExperimentalFuncs()},
 		},
 	}
 
@@ -1113,9 +1194,15 @@ func TestComparisonExpression_ReturnsBool(t *testing.T) {
 			wantCollection:  system.Collection{system.Boolean(false)},
 		},
 		{
-			name:            "returns empty for quantities of different precision",
+			name:            "compares quantities of commensurable units",
 			inputPath:       "99.9 'cm' < 1 'm'",
 			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "returns empty for quantities of incommensurable units",
+			inputPath:       "99.9 'cm' < 1 'kg'",
+			inputCollection: []fhir.Resource{},
 			wantCollection:  system.Collection{},
 		},
 		{
@@ -1197,6 +1284,7 @@ func TestArithmetic_ReturnsResult(t *testing.T) {
 }
 
 func TestCompile_ReturnsError(t *testing.T) {
+	experimental := []fhirpath.CompileOption{compopts.WithExperimentalFuncs()}
 	testCases := []struct {
 		name           string
 		inputPath      string
@@ -1226,6 +1314,18 @@ func TestCompile_ReturnsError(t *testing.T) {
 			name:      "non-existent function",
 			inputPath: "Patient.notAFunc()",
 		},
+		{
+			name:      "unknown %terminologies method",
+			inputPath: "%terminologies.notAMethod('http://example.com/ValueSet/vitals')",
+		},
+		{
+			name:      "%terminologies method with wrong arity",
+			inputPath: "%terminologies.expand()",
+		},
+		{
+			name:      "experimental function without option",
+			inputPath: "Patient.name.family.trim()",
+		},
 		{
 			name:           "expanding function table with bad function",
 			inputPath:      "Patient.badFn()",
@@ -1248,6 +1348,61 @@ func TestCompile_ReturnsError(t *testing.T) {
 			name:      "resolving invalid type specifier",
 			inputPath: "1 is System.Patient",
 		},
+		{
+			name:      "resolving invalid type specifier in ofType",
+			inputPath: "Patient.name.ofType(NotAType)",
+		},
+		{
+			name:      "ofType with expression argument",
+			inputPath: "Patient.name.ofType(name.given)",
+		},
+		{
+			name:      "ofType with mismatched arity",
+			inputPath: "Patient.name.ofType()",
+		},
+		{
+			name:           "redefining a variable",
+			inputPath:      "defineVariable('x', 1).defineVariable('x', 2)",
+			compileOptions: experimental,
+		},
+		{
+			name:           "redefining an environment variable",
+			inputPath:      "defineVariable('context', 1)",
+			compileOptions: experimental,
+		},
+		{
+			name:           "using a variable before it is defined",
+			inputPath:      "Patient.name.where(given = %x).defineVariable('x', 1)",
+			compileOptions: experimental,
+		},
+		{
+			name:           "using a variable outside of its scope",
+			inputPath:      "Patient.select(defineVariable('x', 1)).select(%x)",
+			compileOptions: experimental,
+		},
+		{
+			name:           "using a variable from a sibling branch",
+			inputPath:      "defineVariable('x', 1).select(%x) = %x",
+			compileOptions: experimental,
+		},
+		{
+			name:           "defining a variable with a computed name",
+			inputPath:      "defineVariable('x' + 'y', 1)",
+			compileOptions: experimental,
+		},
+		{
+			name:           "defining a variable without a name",
+			inputPath:      "defineVariable()",
+			compileOptions: experimental,
+		},
+		{
+			name:      "defining a variable without experimental functions",
+			inputPath: "defineVariable('x', 1)",
+		},
+		{
+			name:      "resolving invalid type specifier in is function",
+			inputPath: "Patient.is(System.Patient)",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1281,6 +1436,64 @@ func TestEvaluate_ReturnsError(t *testing.T) {
 			inputCollection: []fhir.Resource{},
 			compileOptions:  []fhirpath.CompileOption{compopts.AddFunction("alwaysFails", alwaysFails)},
 		},
+		{
+			name:            "summing quantities with mismatched units",
+			inputPath:       "(1 'mg' | 2 'cm').sum()",
+			inputCollection: []fhir.Resource{},
+			compileOptions:  []fhirpath.CompileOption{compopts.WithExperimentalFuncs()},
+		},
+		{
+			name:            "memberOf without a terminology provider",
+			inputPath:       "Patient.gender.memberOf('http://hl7.org/fhir/ValueSet/administrative-gender')",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "%terminologies method without a terminology provider",
+			inputPath:       "%terminologies.expand('http://hl7.org/fhir/ValueSet/administrative-gender')",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "conformsTo without a profile validator",
+			inputPath:       "Patient.conformsTo('http://example.com/StructureDefinition/p')",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "slice without structure definitions",
+			inputPath:       "Patient.name.slice('http://example.com/StructureDefinition/p', 'official')",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "checkModifiers with unknown modifier extensions",
+			inputPath:       "Patient.checkModifiers('http://example.com/modifier')",
+			inputCollection: []fhir.Resource{&ppb.Patient{ModifierExtension: []*dtpb.Extension{fooExtension}}},
+		},
+		{
+			name:            "%server method without a FHIR server",
+			inputPath:       "%server.read('Patient', '123')",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "unsupported %server method",
+			inputPath:       "%server.create(%context)",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "navigating unknown type information property",
+			inputPath:       "Patient.type().elementType",
+			inputCollection: []fhir.Resource{patientChu},
+		},
+		{
+			name:            "defining a variable that shadows a runtime environment variable",
+			inputPath:       "defineVariable('name', 1).select(%name)",
+			inputCollection: []fhir.Resource{patientChu},
+			evaluateOptions: []fhirpath.EvaluateOption{evalopts.EnvVariable("name", system.String("Chu"))},
+			compileOptions:  []fhirpath.CompileOption{compopts.WithExperimentalFuncs()},
+		},
+		{
+			name:            "single on collection with multiple items",
+			inputPath:       "Patient.name.single()",
+			inputCollection: []fhir.Resource{patientChu},
+		},
 		{
 			name:            "evaluating is expression on non-singleton collection",
 			inputPath:       "Patient.name is string",
@@ -1325,6 +1538,11 @@ func TestEvaluate_ReturnsError(t *testing.T) {
 				evalopts.EnvVariable("collection", system.Collection{system.Integer(1), 1}),
 			},
 		},
+		{
+			name:            "membership on non-singleton operand",
+			inputPath:       "Patient.name.given in Patient.name.given",
+			inputCollection: []fhir.Resource{patientChu},
+		},
 		{
 			name:            "negating unsupported type",
 			inputPath:       "-'string'",
@@ -1374,6 +1592,24 @@ func TestExternalConstantExpression_ReturnsConstant(t *testing.T) {
 			},
 			wantCollection: system.Collection{system.String("hello"), patientChu},
 		},
+		{
+			name:            "delimited constant",
+			inputPath:       "%`us-zip`",
+			inputCollection: []fhir.Resource{},
+			evaluateOptions: []fhirpath.EvaluateOption{
+				evalopts.EnvVariable("us-zip", system.String("[0-9]{5}")),
+			},
+			wantCollection: system.Collection{system.String("[0-9]{5}")},
+		},
+		{
+			name:            "string constant",
+			inputPath:       "%'us-zip'",
+			inputCollection: []fhir.Resource{},
+			evaluateOptions: []fhirpath.EvaluateOption{
+				evalopts.EnvVariable("us-zip", system.String("[0-9]{5}")),
+			},
+			wantCollection: system.Collection{system.String("[0-9]{5}")},
+		},
 		{
 			name:            "returns input as %context variable",
 			inputPath:       "%context",
@@ -1447,6 +1683,1438 @@ func TestAll_Evaluates(t *testing.T) {
 	testEvaluate(t, testCases)
 }
 
+func TestUnion_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "merges fields with union operator",
+			inputPath:       "Patient.name.given | Patient.name.family",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection: system.Collection{
+				fhir.String("Senpai"),
+				fhir.String("Kang"),
+				fhir.String("Chu"),
+			},
+		},
+		{
+			name:            "removes duplicates across literals and fields",
+			inputPath:       "Patient.name.family | 'Chu' | 'Rodusek'",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection: system.Collection{
+				fhir.String("Chu"),
+				system.String("Rodusek"),
+			},
+		},
+		{
+			name:            "merges fields with union()",
+			inputPath:       "Patient.name.given.union(%context.contact.name.given)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection: system.Collection{
+				fhir.String("Senpai"),
+				fhir.String("Kang"),
+			},
+		},
+		{
+			name:            "keeps duplicates with combine()",
+			inputPath:       "Patient.name.family.combine(%context.contact.name.family)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection: system.Collection{
+				fhir.String("Chu"),
+				fhir.String("Chu"),
+				fhir.String("Rodusek"),
+			},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestMembership_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "returns true for literal in field",
+			inputPath:       "'Kang' in Patient.name.given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "returns false for literal not in field",
+			inputPath:       "'Lord' in Patient.name.given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "returns true for field containing code",
+			inputPath:       "Patient.name.use contains 'official'",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "propagates empty operand",
+			inputPath:       "Patient.maritalStatus in Patient.name.given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "contains() function is still callable",
+			inputPath:       "Patient.name[0].given.contains('pai')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestIterationVariables_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "filters by $index with where()",
+			inputPath:       "Patient.name.where($index > 0).given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Kang")},
+		},
+		{
+			name:            "projects $index with select()",
+			inputPath:       "Patient.name.select($index)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(0), system.Integer(1)},
+		},
+		{
+			name:            "checks $index with all()",
+			inputPath:       "Patient.name.all($index < 2)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "sums values with aggregate()",
+			inputPath:       "(1 | 2 | 3 | 4).aggregate($this + $total, 0)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(10)},
+		},
+		{
+			name:            "computes maximum with aggregate()",
+			inputPath:       "(3 | 9 | 4).aggregate(iif($total.empty(), $this, iif($this > $total, $this, $total)))",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(9)},
+		},
+		{
+			name:            "returns empty $index outside of iteration",
+			inputPath:       "$index",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestRepeat_Evaluates(t *testing.T) {
+	questionnaire := &qpb.Questionnaire{
+		Item: []*qpb.Questionnaire_Item{
+			{
+				LinkId: fhir.String("1"),
+				Item: []*qpb.Questionnaire_Item{
+					{LinkId: fhir.String("1.1")},
+					{LinkId: fhir.String("1.2"), Item: []*qpb.Questionnaire_Item{{LinkId: fhir.String("1.2.1")}}},
+				},
+			},
+			{LinkId: fhir.String("2")},
+		},
+	}
+	testCases := []evaluateTestCase{
+		{
+			name:            "walks nested questionnaire items",
+			inputPath:       "Questionnaire.repeat(item).linkId",
+			inputCollection: []fhir.Resource{questionnaire},
+			wantCollection: system.Collection{
+				fhir.String("1"),
+				fhir.String("2"),
+				fhir.String("1.1"),
+				fhir.String("1.2"),
+				fhir.String("1.2.1"),
+			},
+		},
+		{
+			name:            "stops when no new items are produced",
+			inputPath:       "(1 | 2).repeat(3)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(3)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestOfType_Evaluates(t *testing.T) {
+	quantityObs := &opb.Observation{
+		Value: &opb.Observation_ValueX{
+			Choice: &opb.Observation_ValueX_Quantity{
+				Quantity: &dtpb.Quantity{Value: fhir.Decimal(22.2)},
+			},
+		},
+	}
+	stringObs := &opb.Observation{
+		Value: &opb.Observation_ValueX{
+			Choice: &opb.Observation_ValueX_StringValue{
+				StringValue: fhir.String("positive"),
+			},
+		},
+	}
+	collection := bundle.NewCollection(bundle.WithEntries(
+		bundle.NewCollectionEntry(patientChu),
+		bundle.NewCollectionEntry(quantityObs),
+		bundle.NewCollectionEntry(stringObs),
+	))
+	testCases := []evaluateTestCase{
+		{
+			name:            "filters bundle entries by resource type",
+			inputPath:       "Bundle.entry.resource.ofType(Observation)",
+			inputCollection: []fhir.Resource{collection},
+			wantCollection:  system.Collection{quantityObs, stringObs},
+		},
+		{
+			name:            "filters bundle entries by qualified resource type",
+			inputPath:       "Bundle.entry.resource.ofType(FHIR.Patient)",
+			inputCollection: []fhir.Resource{collection},
+			wantCollection:  system.Collection{patientChu},
+		},
+		{
+			name:            "unwraps matching choice types",
+			inputPath:       "Bundle.entry.resource.ofType(Observation).value.ofType(Quantity)",
+			inputCollection: []fhir.Resource{collection},
+			wantCollection:  system.Collection{quantityObs.GetValue().GetQuantity()},
+		},
+		{
+			name:            "includes subtypes",
+			inputPath:       "Patient.name.use.ofType(FHIR.Element)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{patientChu.Name[0].Use, patientChu.Name[1].Use},
+		},
+		{
+			name:            "filters system types",
+			inputPath:       "(1 | 'a' | 2.5 | 'b').ofType(System.String)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.String("a"), system.String("b")},
+		},
+		{
+			name:            "returns empty when nothing matches",
+			inputPath:       "Patient.name.ofType(Quantity)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestSubsetting_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "single returns the only item",
+			inputPath:       "Patient.name.where(use = 'official').single().given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Kang")},
+		},
+		{
+			name:            "single returns empty for empty input",
+			inputPath:       "Patient.address.single()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "subsetOf evaluates its argument against the root",
+			inputPath:       "Patient.name[0].given.subsetOf(%context.name.given)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "subsetOf returns false for missing items",
+			inputPath:       "Patient.name.given.subsetOf(%context.name[0].given)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "supersetOf evaluates its argument against the root",
+			inputPath:       "Patient.name.given.supersetOf(%context.name[1].given)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestReflection_Evaluates(t *testing.T) {
+	testCases := []evaluateTestCase{
+		{
+			name:            "type returns class info for resources",
+			inputPath:       "Patient.type().namespace + '.' + Patient.type().name",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("FHIR.Patient")},
+		},
+		{
+			name:            "type returns the base type from the parent chain",
+			inputPath:       "Patient.type().baseType",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("FHIR.DomainResource")},
+		},
+		{
+			name:            "type returns class info elements",
+			inputPath:       "Patient.type().element.where(name = 'name').type",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("List<FHIR.HumanName>")},
+		},
+		{
+			name:            "type returns simple type info for system types",
+			inputPath:       "1.type().name",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.String("Integer")},
+		},
+		{
+			name:            "type returns type info of each item of collections",
+			inputPath:       "(1 | 'a').type().name",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.String("Integer"), system.String("String")},
+		},
+		{
+			name:            "is function returns true for subtype",
+			inputPath:       "Patient.is(FHIR.Resource)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "as function unwraps polymorphic type",
+			inputPath:       "Patient.deceased.as(boolean)",
+			inputCollection: []fhir.Resource{patientVoldemort},
+			wantCollection:  system.Collection{fhir.Boolean(true)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestDefineVariable_Evaluates(t *testing.T) {
+	experimental := []fhirpath.CompileOption{compopts.WithExperimentalFuncs()}
+	testCases := []evaluateTestCase{
+		{
+			name:            "variable is visible to the rest of the chain",
+			inputPath:       "Patient.defineVariable('official', name.where(use = 'official')).name.where(given = %official.given).given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Kang")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "variable defaults to the input collection",
+			inputPath:       "Patient.name.defineVariable('names').select(%names.count())",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(2), system.Integer(2)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "variables are visible inside nested functions",
+			inputPath:       "defineVariable('x', 1).select(defineVariable('y', %x + 1).select(%x + %y))",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(3)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "sibling branches define the same name independently",
+			inputPath:       "defineVariable('a', 1).select(%a) | defineVariable('a', 2).select(%a)",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(1), system.Integer(2)},
+			compileOptions:  experimental,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestSort_Evaluates(t *testing.T) {
+	experimental := []fhirpath.CompileOption{compopts.WithExperimentalFuncs()}
+	testCases := []evaluateTestCase{
+		{
+			name:            "sorts items in ascending order",
+			inputPath:       "(3 | 1 | 2).sort()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "sorts items in descending order",
+			inputPath:       "(3 | 1 | 2).sort(-$this)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(3), system.Integer(2), system.Integer(1)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "sorts resources by key expression",
+			inputPath:       "Patient.name.sort(given.first()).given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Kang"), fhir.String("Senpai")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "sorts resources by descending key expression",
+			inputPath:       "Patient.name.sort(-given.first()).given",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Senpai"), fhir.String("Kang")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "sorts by multiple keys",
+			inputPath:       "Patient.name.sort(family, -use).use",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{patientChu.Name[1].Use, patientChu.Name[0].Use},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "returns empty for dates with mismatched precision",
+			inputPath:       "(@2020 | @2020-01-05 | @2019-12-31 | @2020-02).sort()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "returns empty for quantities of incommensurable units",
+			inputPath:       "(1 'kg' | 1 year).sort()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{},
+			compileOptions:  experimental,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestBoundaries_Evaluates(t *testing.T) {
+	experimental := []fhirpath.CompileOption{compopts.WithExperimentalFuncs()}
+	testCases := []evaluateTestCase{
+		{
+			name:            "low boundary of partial date",
+			inputPath:       "@2020-03.lowBoundary()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseDate("2020-03-01")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "high boundary of partial date",
+			inputPath:       "@2020-03.highBoundary()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseDate("2020-03-31")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "high boundary of partial date time",
+			inputPath:       "@2020-03T.highBoundary()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseDateTime("2020-03-31T23:59:59.999")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "high boundary of decimal with precision",
+			inputPath:       "1.587.highBoundary(6)",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseDecimal("1.5875")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "checks overlap of partial date",
+			inputPath:       "Patient.birthDate >= @2000-03.lowBoundary() and Patient.birthDate <= @2000-03.highBoundary()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "precision of time",
+			inputPath:       "@T10:30.precision()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(4)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "precision of birth date",
+			inputPath:       "Patient.birthDate.precision()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(8)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "comparable quantities",
+			inputPath:       "1 'mg'.comparable(2 'mg')",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "incomparable quantities",
+			inputPath:       "1 'mg'.comparable(2 'cm')",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(false)},
+			compileOptions:  experimental,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestStringFunctions_Evaluates(t *testing.T) {
+	experimental := []fhirpath.CompileOption{compopts.WithExperimentalFuncs()}
+	testCases := []evaluateTestCase{
+		{
+			name:            "trims whitespace",
+			inputPath:       "' Kang '.trim() = 'Kang'",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "splits string on separator",
+			inputPath:       "'Lee,Jieun'.split(',')",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.String("Lee"), system.String("Jieun")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "split and join round trip",
+			inputPath:       "'a,b,c'.split(',').join('|')",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.String("a|b|c")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "finds last index of substring",
+			inputPath:       "Patient.name.given.join(' ').lastIndexOf('a')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(8)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "matches full family name",
+			inputPath:       "Patient.name.family.first().matchesFull('C[a-z]+')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "encodes and decodes base64",
+			inputPath:       "Patient.name.family.first().encode('base64').decode('base64')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("Chu")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "escapes html",
+			inputPath:       "'1 < 5'.escape('html')",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.String("1 &lt; 5")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "unescapes json",
+			inputPath:       `'\\"quoted\\"'.unescape('json')`,
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.String(`"quoted"`)},
+			compileOptions:  experimental,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestDateTimeComponents_Evaluates(t *testing.T) {
+	experimental := []fhirpath.CompileOption{compopts.WithExperimentalFuncs()}
+	testCases := []evaluateTestCase{
+		{
+			name:            "year of birth date",
+			inputPath:       "Patient.birthDate.yearOf()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Integer(2000)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "month of date time literal",
+			inputPath:       "@2012-01-02T13:30:45.123-07:00.monthOf()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(1)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "millisecond of time literal",
+			inputPath:       "@T13:30:45.123.millisecondOf()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Integer(123)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "component exceeding precision",
+			inputPath:       "@2012-01.dayOf()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "timezone offset of date time",
+			inputPath:       "@2012-01-02T13:30:45.123-07:00.timezoneOffsetOf()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseDecimal("-7")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "date of date time",
+			inputPath:       "@2012-01-02T13:30:45.123-07:00.dateOf()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseDate("2012-01-02")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "time of date time",
+			inputPath:       "@2012-01-02T13:30.timeOf()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseTime("13:30")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "timezone offset of date",
+			inputPath:       "@2012-01-02.timezoneOffsetOf()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "date of date",
+			inputPath:       "@2012-01-02.dateOf()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseDate("2012-01-02")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "date of FHIR date",
+			inputPath:       "Patient.birthDate.dateOf()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.MustParseDate("2000-03-22")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "time of time",
+			inputPath:       "@T10:00:00.timeOf()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseTime("10:00:00")},
+			compileOptions:  experimental,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestDurationDifference_Evaluates(t *testing.T) {
+	experimental := []fhirpath.CompileOption{compopts.WithExperimentalFuncs()}
+	now := time.Date(2024, time.March, 21, 12, 0, 0, 0, time.UTC)
+	testCases := []evaluateTestCase{
+		{
+			name:            "age in years the day before a birthday",
+			inputPath:       "Patient.birthDate.duration(today(), 'years')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.MustParseQuantity("23", "years")},
+			evaluateOptions: []fhirpath.EvaluateOption{evalopts.OverrideTime(now)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "age based eligibility",
+			inputPath:       "Patient.birthDate.duration(today(), 'years') >= 18 years",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			evaluateOptions: []fhirpath.EvaluateOption{evalopts.OverrideTime(now)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "calendar years crossed",
+			inputPath:       "Patient.birthDate.difference(today(), 'years')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.MustParseQuantity("24", "years")},
+			evaluateOptions: []fhirpath.EvaluateOption{evalopts.OverrideTime(now)},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "months between dates",
+			inputPath:       "@2020-01-31.duration(@2020-02-29, 'months')",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseQuantity("1", "months")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "unit exceeding precision",
+			inputPath:       "@2020-01.duration(@2020-02, 'days')",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{},
+			compileOptions:  experimental,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestAggregateMath_Evaluates(t *testing.T) {
+	experimental := []fhirpath.CompileOption{compopts.WithExperimentalFuncs()}
+	claim := &clpb.Claim{
+		Item: []*clpb.Claim_Item{
+			{Net: &dtpb.Money{Value: &dtpb.Decimal{Value: "12.50"}}},
+			{Net: &dtpb.Money{Value: &dtpb.Decimal{Value: "7.25"}}},
+			{Net: &dtpb.Money{Value: &dtpb.Decimal{Value: "30"}}},
+		},
+	}
+	testCases := []evaluateTestCase{
+		{
+			name:            "sums claim item net values",
+			inputPath:       "Claim.item.net.value.sum()",
+			inputCollection: []fhir.Resource{claim},
+			wantCollection:  system.Collection{system.MustParseDecimal("49.75")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "finds the smallest claim item net value",
+			inputPath:       "Claim.item.net.value.min()",
+			inputCollection: []fhir.Resource{claim},
+			wantCollection:  system.Collection{system.MustParseDecimal("7.25")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "finds the largest claim item net value",
+			inputPath:       "Claim.item.net.value.max()",
+			inputCollection: []fhir.Resource{claim},
+			wantCollection:  system.Collection{system.MustParseDecimal("30")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "averages integers",
+			inputPath:       "(1 | 2 | 3 | 4).avg()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseDecimal("2.5")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "sums quantities",
+			inputPath:       "(1 'mg' | 2.5 'mg').sum()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseQuantity("3.5", "mg")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "finds the latest name",
+			inputPath:       "Patient.name.given.max()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("Senpai")},
+			compileOptions:  experimental,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestResolve_Evaluates(t *testing.T) {
+	subject := reference.Weak(resource.Patient, "Patient/123")
+	performer := reference.Weak(resource.Practitioner, "Practitioner/456")
+	observation := &opb.Observation{
+		Subject:   subject,
+		Performer: []*dtpb.Reference{performer},
+	}
+	practitioner := &prpb.Practitioner{Id: fhir.ID("456")}
+	contained, err := anypb.New(containedresource.Wrap(&ppb.Patient{Id: fhir.ID("p1")}))
+	if err != nil {
+		t.Fatalf("anypb.New: %v", err)
+	}
+	containing := &opb.Observation{
+		Subject:   reference.Weak(resource.Patient, "#p1"),
+		Contained: []*anypb.Any{contained},
+	}
+	resolveOpt := evalopts.Resolve(resolver.Bundle(bundle.NewCollection(bundle.WithEntries(
+		bundle.NewCollectionEntry(patientChu),
+		bundle.NewCollectionEntry(practitioner),
+	))))
+	testCases := []evaluateTestCase{
+		{
+			name:            "filters references by the type of their target",
+			inputPath:       "Observation.subject.where(resolve() is Patient)",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{subject},
+			evaluateOptions: []fhirpath.EvaluateOption{resolveOpt},
+		},
+		{
+			name:            "navigates into resolved resources",
+			inputPath:       "Observation.subject.resolve().birthDate",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{patientChu.BirthDate},
+			evaluateOptions: []fhirpath.EvaluateOption{resolveOpt},
+		},
+		{
+			name:            "resolves a collection of references",
+			inputPath:       "(Observation.subject | Observation.performer).resolve().id",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.ID("123"), fhir.ID("456")},
+			evaluateOptions: []fhirpath.EvaluateOption{resolveOpt},
+		},
+		{
+			name:            "resolves nothing without a resolver",
+			inputPath:       "Observation.subject.resolve()",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "resolves contained resources without a resolver",
+			inputPath:       "Observation.subject.resolve().id",
+			inputCollection: []fhir.Resource{containing},
+			wantCollection:  system.Collection{fhir.ID("p1")},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestMemberOf_Evaluates(t *testing.T) {
+	genders := &vspb.ValueSet{
+		Url: fhir.URI("http://example.com/ValueSet/binary-gender"),
+		Compose: &vspb.ValueSet_Compose{
+			Include: []*vspb.ValueSet_Compose_ConceptSet{
+				{
+					System: fhir.URI("http://hl7.org/fhir/administrative-gender"),
+					Concept: []*vspb.ValueSet_Compose_ConceptSet_ConceptReference{
+						{Code: fhir.Code("female")},
+						{Code: fhir.Code("male")},
+					},
+				},
+			},
+		},
+	}
+	vitals := &vspb.ValueSet{
+		Url: fhir.URI("http://example.com/ValueSet/vitals"),
+		Compose: &vspb.ValueSet_Compose{
+			Include: []*vspb.ValueSet_Compose_ConceptSet{
+				{
+					System: fhir.URI("http://loinc.org"),
+					Concept: []*vspb.ValueSet_Compose_ConceptSet_ConceptReference{
+						{Code: fhir.Code("8867-4")},
+						{Code: fhir.Code("8310-5")},
+					},
+				},
+			},
+		},
+	}
+	provider, err := terminology.NewLocal(genders, vitals)
+	if err != nil {
+		t.Fatalf("NewLocal: unexpected err: %v", err)
+	}
+	terminologyOpt := evalopts.Terminology(provider)
+	observation := &opb.Observation{
+		Code: fhir.CodeableConcept("Heart rate", fhir.Coding("http://loinc.org", "8867-4")),
+	}
+	testCases := []evaluateTestCase{
+		{
+			name:            "code field is a member",
+			inputPath:       "Patient.gender.memberOf('http://example.com/ValueSet/binary-gender')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			evaluateOptions: []fhirpath.EvaluateOption{terminologyOpt},
+		},
+		{
+			name:            "codeable concept is a member",
+			inputPath:       "Observation.code.memberOf('http://example.com/ValueSet/vitals')",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			evaluateOptions: []fhirpath.EvaluateOption{terminologyOpt},
+		},
+		{
+			name:            "coding is not a member",
+			inputPath:       "Observation.code.coding.memberOf('http://example.com/ValueSet/binary-gender')",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.Boolean(false)},
+			evaluateOptions: []fhirpath.EvaluateOption{terminologyOpt},
+		},
+		{
+			name:            "empty input",
+			inputPath:       "Observation.bodySite.memberOf('http://example.com/ValueSet/vitals')",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{},
+			evaluateOptions: []fhirpath.EvaluateOption{terminologyOpt},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestSubsumption_Evaluates(t *testing.T) {
+	const diagnoses = "http://example.com/CodeSystem/diagnoses"
+	codeSystem := &cspb.CodeSystem{
+		Url: fhir.URI(diagnoses),
+		Concept: []*cspb.CodeSystem_ConceptDefinition{
+			{
+				Code: fhir.Code("diabetes"),
+				Concept: []*cspb.CodeSystem_ConceptDefinition{
+					{Code: fhir.Code("type-1-diabetes")},
+					{Code: fhir.Code("type-2-diabetes")},
+				},
+			},
+			{Code: fhir.Code("hypertension")},
+		},
+	}
+	provider, err := terminology.NewLocal(codeSystem)
+	if err != nil {
+		t.Fatalf("NewLocal: unexpected err: %v", err)
+	}
+	options := []fhirpath.EvaluateOption{
+		evalopts.Terminology(provider),
+		evalopts.EnvVariable("diabetes", fhir.Coding(diagnoses, "diabetes")),
+	}
+	condition := func(code string) *condpb.Condition {
+		return &condpb.Condition{
+			Code: fhir.CodeableConcept("", fhir.Coding(diagnoses, code)),
+		}
+	}
+	testCases := []evaluateTestCase{
+		{
+			name:            "diagnosis is subsumed by diabetes",
+			inputPath:       "Condition.code.subsumedBy(%diabetes)",
+			inputCollection: []fhir.Resource{condition("type-2-diabetes")},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			evaluateOptions: options,
+		},
+		{
+			name:            "diagnosis is not subsumed by diabetes",
+			inputPath:       "Condition.code.subsumedBy(%diabetes)",
+			inputCollection: []fhir.Resource{condition("hypertension")},
+			wantCollection:  system.Collection{system.Boolean(false)},
+			evaluateOptions: options,
+		},
+		{
+			name:            "diabetes subsumes diagnosis",
+			inputPath:       "%diabetes.subsumes(%context.code.coding)",
+			inputCollection: []fhir.Resource{condition("type-1-diabetes")},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			evaluateOptions: options,
+		},
+		{
+			name:            "unknown diagnosis",
+			inputPath:       "Condition.code.subsumedBy(%diabetes)",
+			inputCollection: []fhir.Resource{condition("asthma")},
+			wantCollection:  system.Collection{},
+			evaluateOptions: options,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestTerminologies_Evaluates(t *testing.T) {
+	const (
+		loinc     = "http://loinc.org"
+		vitals    = "http://example.com/ValueSet/vitals"
+		vitalsMap = "http://example.com/ConceptMap/vitals-to-snomed"
+	)
+	codeSystem := &cspb.CodeSystem{
+		Url: fhir.URI(loinc),
+		Concept: []*cspb.CodeSystem_ConceptDefinition{
+			{
+				Code:    fhir.Code("LP415671-9"),
+				Display: fhir.String("Vital signs"),
+				Concept: []*cspb.CodeSystem_ConceptDefinition{
+					{Code: fhir.Code("8867-4"), Display: fhir.String("Heart rate")},
+					{Code: fhir.Code("8310-5"), Display: fhir.String("Body temperature")},
+				},
+			},
+		},
+	}
+	valueSet := &vspb.ValueSet{
+		Url: fhir.URI(vitals),
+		Compose: &vspb.ValueSet_Compose{
+			Include: []*vspb.ValueSet_Compose_ConceptSet{
+				{
+					System: fhir.URI(loinc),
+					Concept: []*vspb.ValueSet_Compose_ConceptSet_ConceptReference{
+						{Code: fhir.Code("8867-4")},
+						{Code: fhir.Code("8310-5")},
+					},
+				},
+			},
+		},
+	}
+	conceptMap := &cmpb.ConceptMap{
+		Url: fhir.URI(vitalsMap),
+		Group: []*cmpb.ConceptMap_Group{
+			{
+				Source: fhir.URI(loinc),
+				Target: fhir.URI("http://snomed.info/sct"),
+				Element: []*cmpb.ConceptMap_Group_SourceElement{
+					{
+						Code: fhir.Code("8867-4"),
+						Target: []*cmpb.ConceptMap_Group_SourceElement_TargetElement{
+							{
+								Code: fhir.Code("364075005"),
+								Equivalence: &cmpb.ConceptMap_Group_SourceElement_TargetElement_EquivalenceCode{
+									Value: cpb.ConceptMapEquivalenceCode_EQUIVALENT,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	provider, err := terminology.NewLocal(codeSystem, valueSet, conceptMap)
+	if err != nil {
+		t.Fatalf("NewLocal: unexpected err: %v", err)
+	}
+	options := []fhirpath.EvaluateOption{evalopts.Terminology(provider)}
+	observation := &opb.Observation{
+		Code: fhir.CodeableConcept("Heart rate", fhir.Coding(loinc, "8867-4")),
+	}
+	testCases := []evaluateTestCase{
+		{
+			name:            "expands value set",
+			inputPath:       "%terminologies.expand('http://example.com/ValueSet/vitals').expansion.contains.code",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.Code("8867-4"), fhir.Code("8310-5")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "looks up display of coding",
+			inputPath:       "%terminologies.lookup(Observation.code.coding).parameter.where(name = 'display').value",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.String("Heart rate")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "validates code against value set",
+			inputPath:       "%terminologies.validateVS('http://example.com/ValueSet/vitals', Observation.code).parameter.where(name = 'result').value",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.Boolean(true)},
+			evaluateOptions: options,
+		},
+		{
+			name:            "validates code against code system, within an iterating function",
+			inputPath:       "Observation.code.coding.where(%terminologies.validateCS('http://loinc.org', $this).parameter.where(name = 'result').value).code",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.Code("8867-4")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "returns subsumption outcome",
+			inputPath:       "%terminologies.subsumes('http://loinc.org', 'LP415671-9', Observation.code.coding)",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.String("subsumes")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "translates coding",
+			inputPath:       "%terminologies.translate('http://example.com/ConceptMap/vitals-to-snomed', Observation.code.coding).parameter.where(name = 'match').part.where(name = 'concept').value.code",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.Code("364075005")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "ignores params argument",
+			inputPath:       "%terminologies.expand('http://example.com/ValueSet/vitals', 'count=1').expansion.total",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.Integer(2)},
+			evaluateOptions: options,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestServer_Evaluates(t *testing.T) {
+	observation := &opb.Observation{
+		Id:      fhir.ID("heart-rate"),
+		Code:    fhir.CodeableConcept("Heart rate", fhir.Coding("http://loinc.org", "8867-4")),
+		Subject: reference.Weak(resource.Patient, "Patient/123"),
+	}
+	store, err := server.NewMemory(patientChu, observation)
+	if err != nil {
+		t.Fatalf("NewMemory: unexpected err: %v", err)
+	}
+	options := []fhirpath.EvaluateOption{evalopts.Server(store)}
+	testCases := []evaluateTestCase{
+		{
+			name:            "reads resource",
+			inputPath:       "%server.read('Patient', '123').birthDate",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.MustParseDate("2000-03-22")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "reads resource of the input",
+			inputPath:       "%server.read('Patient', Observation.subject.reference.substring(8)).name.family.distinct()",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.String("Chu")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "reads unknown resource",
+			inputPath:       "%server.read('Patient', '456')",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{},
+			evaluateOptions: options,
+		},
+		{
+			name:            "searches resources",
+			inputPath:       "%server.search(false, 'Observation?patient=Patient/123&code=8867-4').entry.resource.id",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.ID("heart-rate")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "counts search results",
+			inputPath:       "%server.search(true, 'Patient?family=chu').total",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.UnsignedInt(1)},
+			evaluateOptions: options,
+		},
+		{
+			name:            "validates resource",
+			inputPath:       "%server.validate(%context, 'create').issue.code",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{&oopb.OperationOutcome_Issue_CodeType{Value: cpb.IssueTypeCode_DUPLICATE}},
+			evaluateOptions: options,
+		},
+		{
+			name:            "validates resource against profile",
+			inputPath:       "%server.validate(%context, 'profile', 'profile=http://example.com/StructureDefinition/p').issue.severity",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{&oopb.OperationOutcome_Issue_SeverityCode{Value: cpb.IssueSeverityCode_ERROR}},
+			evaluateOptions: options,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestConformsTo_Evaluates(t *testing.T) {
+	const (
+		named  = "http://example.com/StructureDefinition/named"
+		family = "http://example.com/StructureDefinition/family-name"
+	)
+	registry, err := profile.NewRegistry(
+		&sdpb.StructureDefinition{
+			Url:  fhir.URI(named),
+			Type: fhir.URI("Patient"),
+			Differential: &sdpb.StructureDefinition_Differential{
+				Element: []*dtpb.ElementDefinition{
+					{Path: fhir.String("Patient.name"), Min: fhir.UnsignedInt(1), Max: fhir.String("*")},
+					{Path: fhir.String("Patient.gender"), Min: fhir.UnsignedInt(1), Max: fhir.String("1")},
+				},
+			},
+		},
+		&sdpb.StructureDefinition{
+			Url:  fhir.URI(family),
+			Type: fhir.URI("HumanName"),
+			Differential: &sdpb.StructureDefinition_Differential{
+				Element: []*dtpb.ElementDefinition{
+					{
+						Path: fhir.String("HumanName"),
+						Constraint: []*dtpb.ElementDefinition_Constraint{
+							{
+								Key:        fhir.ID("fam-1"),
+								Severity:   &dtpb.ElementDefinition_Constraint_SeverityCode{Value: cpb.ConstraintSeverityCode_ERROR},
+								Expression: fhir.String("family.exists() and given.exists()"),
+							},
+						},
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry: unexpected err: %v", err)
+	}
+	options := []fhirpath.EvaluateOption{evalopts.Validator(registry)}
+	testCases := []evaluateTestCase{
+		{
+			name:            "conforming resource",
+			inputPath:       "Patient.conformsTo('http://example.com/StructureDefinition/named')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			evaluateOptions: options,
+		},
+		{
+			name:            "non-conforming resource",
+			inputPath:       "Patient.conformsTo('http://example.com/StructureDefinition/named')",
+			inputCollection: []fhir.Resource{&ppb.Patient{Name: patientChu.Name}},
+			wantCollection:  system.Collection{system.Boolean(false)},
+			evaluateOptions: options,
+		},
+		{
+			name:            "filters elements by conformance",
+			inputPath:       "Patient.contact.name.where(conformsTo('http://example.com/StructureDefinition/family-name')).family",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{fhir.String("Rodusek")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "unknown profile",
+			inputPath:       "Patient.conformsTo('http://example.com/StructureDefinition/unknown')",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{},
+			evaluateOptions: options,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestPrimitiveValues_Evaluates(t *testing.T) {
+	absentBirthDate := &ppb.Patient{
+		BirthDate: &dtpb.Date{
+			Extension: []*dtpb.Extension{
+				extension.New("https://g.co/fhir/StructureDefinition/primitiveHasNoValue", fhir.Boolean(true)),
+				extension.New("http://hl7.org/fhir/StructureDefinition/data-absent-reason", fhir.Code("unknown")),
+			},
+		},
+	}
+	narrated := &ppb.Patient{
+		Text: &dtpb.Narrative{
+			Status: &dtpb.Narrative_StatusCode{Value: cpb.NarrativeStatusCode_GENERATED},
+			Div:    &dtpb.Xhtml{Value: `<div xmlns="http://www.w3.org/1999/xhtml"><p>Senpai Chu</p></div>`},
+		},
+	}
+	scripted := &ppb.Patient{
+		Text: &dtpb.Narrative{
+			Status: &dtpb.Narrative_StatusCode{Value: cpb.NarrativeStatusCode_GENERATED},
+			Div:    &dtpb.Xhtml{Value: `<div xmlns="http://www.w3.org/1999/xhtml"><script>alert(1)</script></div>`},
+		},
+	}
+	testCases := []evaluateTestCase{
+		{
+			name:            "primitive with a value",
+			inputPath:       "Patient.birthDate.hasValue()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "primitive with only extensions",
+			inputPath:       "Patient.birthDate.hasValue()",
+			inputCollection: []fhir.Resource{absentBirthDate},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "extensions of a primitive without a value",
+			inputPath:       "Patient.birthDate.where(hasValue().not()).extension('http://hl7.org/fhir/StructureDefinition/data-absent-reason').value",
+			inputCollection: []fhir.Resource{absentBirthDate},
+			wantCollection:  system.Collection{fhir.Code("unknown")},
+		},
+		{
+			name:            "value of a date",
+			inputPath:       "Patient.birthDate.getValue()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.MustParseDate("2000-03-22")},
+		},
+		{
+			name:            "value of a primitive with only extensions",
+			inputPath:       "Patient.birthDate.getValue()",
+			inputCollection: []fhir.Resource{absentBirthDate},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "value of a code",
+			inputPath:       "Patient.gender.getValue()",
+			inputCollection: []fhir.Resource{patientChu},
+			wantCollection:  system.Collection{system.String("female")},
+		},
+		{
+			name:            "valid narrative",
+			inputPath:       "Patient.text.`div`.htmlChecks()",
+			inputCollection: []fhir.Resource{narrated},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "narrative with a script",
+			inputPath:       "Patient.text.`div`.htmlChecks()",
+			inputCollection: []fhir.Resource{scripted},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestStructureDefinitions_Evaluates(t *testing.T) {
+	const bloodPressure = "http://example.com/StructureDefinition/blood-pressure"
+	element := func(id, path string, min uint32, max string, types ...string) *dtpb.ElementDefinition {
+		ed := &dtpb.ElementDefinition{Id: fhir.String(id), Path: fhir.String(path), Min: fhir.UnsignedInt(min), Max: fhir.String(max)}
+		for _, code := range types {
+			ed.Type = append(ed.Type, &dtpb.ElementDefinition_TypeRef{Code: fhir.URI(code)})
+		}
+		return ed
+	}
+	slicing := element("Observation.component", "Observation.component", 0, "*", "BackboneElement")
+	slicing.Slicing = &dtpb.ElementDefinition_Slicing{
+		Discriminator: []*dtpb.ElementDefinition_Slicing_Discriminator{
+			{
+				Type: &dtpb.ElementDefinition_Slicing_Discriminator_TypeCode{Value: cpb.DiscriminatorTypeCode_PATTERN},
+				Path: fhir.String("code"),
+			},
+		},
+	}
+	systolic := element("Observation.component:systolic", "Observation.component", 1, "1", "BackboneElement")
+	systolic.SliceName = fhir.String("systolic")
+	systolicCode := element("Observation.component:systolic.code", "Observation.component.code", 1, "1", "CodeableConcept")
+	systolicCode.Pattern = &dtpb.ElementDefinition_PatternX{
+		Choice: &dtpb.ElementDefinition_PatternX_CodeableConcept{
+			CodeableConcept: fhir.CodeableConcept("", fhir.Coding("http://loinc.org", "8480-6")),
+		},
+	}
+	registry, err := profile.NewRegistry(
+		&sdpb.StructureDefinition{
+			Url:  fhir.URI("http://hl7.org/fhir/StructureDefinition/Observation"),
+			Type: fhir.URI("Observation"),
+			Snapshot: &sdpb.StructureDefinition_Snapshot{
+				Element: []*dtpb.ElementDefinition{
+					element("Observation", "Observation", 0, "*"),
+					element("Observation.component", "Observation.component", 0, "*", "BackboneElement"),
+					element("Observation.component.code", "Observation.component.code", 1, "1", "CodeableConcept"),
+					element("Observation.component.value[x]", "Observation.component.value[x]", 0, "1", "Quantity", "string"),
+				},
+			},
+		},
+		&sdpb.StructureDefinition{
+			Url:  fhir.URI(bloodPressure),
+			Type: fhir.URI("Observation"),
+			Snapshot: &sdpb.StructureDefinition_Snapshot{
+				Element: []*dtpb.ElementDefinition{
+					element("Observation", "Observation", 0, "*"),
+					slicing,
+					systolic,
+					systolicCode,
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry: unexpected err: %v", err)
+	}
+	component := func(code string, value float64) *opb.Observation_Component {
+		return &opb.Observation_Component{
+			Code: fhir.CodeableConcept("", fhir.Coding("http://loinc.org", code)),
+			Value: &opb.Observation_Component_ValueX{
+				Choice: &opb.Observation_Component_ValueX_Quantity{
+					Quantity: &dtpb.Quantity{Value: fhir.Decimal(value), Unit: fhir.String("mmHg")},
+				},
+			},
+		}
+	}
+	observation := &opb.Observation{
+		ModifierExtension: []*dtpb.Extension{fooExtension},
+		Component:         []*opb.Observation_Component{component("8480-6", 120), component("8462-4", 80)},
+	}
+	options := []fhirpath.EvaluateOption{evalopts.Structures(registry)}
+	testCases := []evaluateTestCase{
+		{
+			name:            "selects the members of a slice",
+			inputPath:       "Observation.component.slice('http://example.com/StructureDefinition/blood-pressure', 'systolic').value.unit",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.String("mmHg")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "unknown slice",
+			inputPath:       "Observation.component.slice('http://example.com/StructureDefinition/blood-pressure', 'diastolic')",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{},
+			evaluateOptions: options,
+		},
+		{
+			name:            "definition of an element",
+			inputPath:       "Observation.component.code.elementDefinition().min",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.UnsignedInt(1), fhir.UnsignedInt(1)},
+			evaluateOptions: options,
+		},
+		{
+			name:            "definition of a choice type element",
+			inputPath:       "Observation.component.first().value.elementDefinition().path",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{fhir.String("Observation.component.value[x]")},
+			evaluateOptions: options,
+		},
+		{
+			name:            "checks known modifier extensions",
+			inputPath:       "Observation.checkModifiers('foourl').component.count()",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.Integer(2)},
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
+func TestQuantityUnits_Evaluates(t *testing.T) {
+	experimental := []fhirpath.CompileOption{compopts.WithExperimentalFuncs()}
+	observation := &opb.Observation{
+		Value: &opb.Observation_ValueX{
+			Choice: &opb.Observation_ValueX_Quantity{
+				Quantity: &dtpb.Quantity{Value: fhir.Decimal(0.25), Code: fhir.Code("g")},
+			},
+		},
+	}
+	testCases := []evaluateTestCase{
+		{
+			name:            "compares value in a different unit",
+			inputPath:       "Observation.value > 100 'mg'",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "equates value in a different unit",
+			inputPath:       "Observation.value = 250 'mg'",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "adds quantities in the more granular unit",
+			inputPath:       "Observation.value + 100 'mg'",
+			inputCollection: []fhir.Resource{observation},
+			wantCollection:  system.Collection{system.MustParseQuantity("350", "mg")},
+		},
+		{
+			name:            "sums quantities of commensurable units",
+			inputPath:       "(1 'g' | 2.5 'mg').sum()",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.MustParseQuantity("1002.5", "mg")},
+			compileOptions:  experimental,
+		},
+		{
+			name:            "equates definite calendar durations with UCUM units",
+			inputPath:       "1 week = 7 'd'",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "equates years with months",
+			inputPath:       "1 year = 12 months",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "returns empty for equality of years and UCUM years",
+			inputPath:       "1 year = 1 'a'",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "years are equivalent to UCUM years",
+			inputPath:       "1 year ~ 1 'a'",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "quantities of commensurable units are comparable",
+			inputPath:       "1 'mg'.comparable(2 '[lb_av]')",
+			inputCollection: []fhir.Resource{},
+			wantCollection:  system.Collection{system.Boolean(true)},
+			compileOptions:  experimental,
+		},
+	}
+
+	testEvaluate(t, testCases)
+}
+
 func TestMustCompile_CompileError_Panics(t *testing.T) {
 	defer func() { _ = recover() }()
 
@@ -6,6 +6,7 @@ package fhirpathtest
 
 import (
 	"github.com/verily-src/fhirpath-go/fhirpath"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
 )
 
@@ -49,3 +50,23 @@ var (
 	// logic in paths.
 	False = Return(system.Collection{system.Boolean(false)})
 )
+
+// TraceEntry is a single collection logged by the FHIRPath trace() function.
+type TraceEntry struct {
+	Name       string
+	Collection system.Collection
+}
+
+// TraceRecorder is a trace sink that records every traced collection, in the
+// order they were traced. It can be passed to evalopts.Trace to inspect the
+// intermediate collections of an expression in tests.
+type TraceRecorder struct {
+	Entries []TraceEntry
+}
+
+var _ evalopts.TraceSink = (*TraceRecorder)(nil)
+
+// Trace records the named collection.
+func (r *TraceRecorder) Trace(name string, collection system.Collection) {
+	r.Entries = append(r.Entries, TraceEntry{Name: name, Collection: collection})
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
 	"github.com/verily-src/fhirpath-go/fhirpath/fhirpathtest"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
 	"github.com/verily-src/fhirpath-go/internal/fhir"
@@ -59,3 +61,21 @@ func TestReturnCollection_Evaluates_ReturnsCollectionOfEntries(t *testing.T) {
 		t.Errorf("ReturnCollection: want %v, got %v", want, got)
 	}
 }
+
+func TestTraceRecorder_RecordsTraces(t *testing.T) {
+	recorder := &fhirpathtest.TraceRecorder{}
+	expr := fhirpath.MustCompile("(1 | 2).trace('numbers').where($this > 1).trace('filtered', $this + 1)")
+	want := []fhirpathtest.TraceEntry{
+		{Name: "numbers", Collection: system.Collection{system.Integer(1), system.Integer(2)}},
+		{Name: "filtered", Collection: system.Collection{system.Integer(3)}},
+	}
+
+	_, err := expr.Evaluate([]fhir.Resource{}, evalopts.Trace(recorder))
+	if err != nil {
+		t.Fatalf("Trace: unexpected err: %v", err)
+	}
+
+	if diff := cmp.Diff(want, recorder.Entries); diff != "" {
+		t.Errorf("Trace: unexpected entries (-want, +got):\n%s", diff)
+	}
+}
@@ -3,7 +3,13 @@ package expr
 import (
 	"time"
 
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
 )
 
 // Context holds the global time and external constant
@@ -23,6 +29,173 @@ type Context struct {
 	// the 'LastResult' will be the unwrapped list from 'given', but we need the
 	// 'name' element that contains the 'given' list in order to alter the list.
 	BeforeLastResult system.Collection
+
+	// Index holds the value of the $index variable, which is the index of the
+	// item currently being evaluated by an iterating function such as where()
+	// or select(). It is empty outside of such functions.
+	Index system.Collection
+
+	// Total holds the value of the $total variable, which is the running total
+	// accumulated by the aggregate() function. It is empty outside of aggregate().
+	Total system.Collection
+
+	// TraceSink receives the collections logged by the trace() function.
+	TraceSink TraceSink
+
+	// Variables holds the variables bound by defineVariable() that are in scope
+	// for the expression currently being evaluated. The map is never modified in
+	// place, so that bindings don't leak between sibling expressions that share
+	// it through Clone.
+	Variables map[string]system.Collection
+
+	// Resolver locates the resources referred to by the resolve() function.
+	Resolver Resolver
+
+	// Terminology answers the terminology queries of functions such as
+	// memberOf(). It is nil unless provided through the evaluate options.
+	Terminology TerminologyProvider
+
+	// Server answers the queries of the methods of the %server constant. It is
+	// nil unless provided through the evaluate options.
+	Server FHIRServer
+
+	// Validator validates elements against profiles for the conformsTo()
+	// function. It is nil unless provided through the evaluate options.
+	Validator ProfileValidator
+
+	// Structures provides the slices and element definitions of the slice() and
+	// elementDefinition() functions. It is nil unless provided through the
+	// evaluate options.
+	Structures StructureDefinitions
+}
+
+// TraceSink is the destination of the collections logged by the trace()
+// function.
+type TraceSink interface {
+	// Trace records the named collection.
+	Trace(name string, collection system.Collection)
+}
+
+// noopTraceSink is a TraceSink that discards all traces.
+type noopTraceSink struct{}
+
+func (noopTraceSink) Trace(string, system.Collection) {}
+
+// Resolver locates the resources that are the targets of References and
+// canonical URLs, for use by the resolve() function.
+type Resolver interface {
+	// Resolve returns the resource identified by the given URL, which may be
+	// relative, absolute, or a canonical URL. Fragments such as "#id", which
+	// refer to contained resources, are resolved by resolve() itself. A nil
+	// resource is returned if the URL cannot be resolved.
+	Resolve(url string) (fhir.Resource, error)
+}
+
+// noopResolver is a Resolver that resolves nothing.
+type noopResolver struct{}
+
+func (noopResolver) Resolve(string) (fhir.Resource, error) {
+	return nil, nil
+}
+
+// TerminologyProvider answers questions about codes, value sets and code
+// systems, for use by terminology functions such as memberOf() and
+// subsumes(), and by the methods of the %terminologies constant.
+//
+// The methods returning Parameters follow the output parameters of the
+// corresponding FHIR terminology operations. They return a nil result if the
+// operation has no outcome, such as when a code system does not define a code.
+//
+// A value set, code system or concept map that is not available to the
+// provider results in an error, rather than an empty result, since nothing can
+// be concluded about its codes.
+type TerminologyProvider interface {
+	// MemberOf reports whether the coding is a member of the value set with the
+	// given canonical URL. A coding without a system is matched by code alone.
+	MemberOf(valueSet string, coding *dtpb.Coding) (bool, error)
+
+	// Subsumes returns the relationship between two codes of the code system
+	// with the given URL, where SubsumptionSubsumes means that codeA is an
+	// ancestor of codeB. An empty outcome is returned if the relationship is
+	// unknown.
+	Subsumes(system, codeA, codeB string) (SubsumptionOutcome, error)
+
+	// Expand returns the value set with the given canonical URL, with an
+	// expansion listing its codes, as the $expand operation.
+	Expand(valueSet string) (*vspb.ValueSet, error)
+
+	// Lookup returns the details of the concept of the coding, as the $lookup
+	// operation.
+	Lookup(coding *dtpb.Coding) (*ppb.Parameters, error)
+
+	// ValidateVS validates the concept against the value set with the given
+	// canonical URL, as the $validate-code operation of ValueSet.
+	ValidateVS(valueSet string, concept *dtpb.CodeableConcept) (*ppb.Parameters, error)
+
+	// ValidateCS validates the concept against the code system with the given
+	// canonical URL, as the $validate-code operation of CodeSystem.
+	ValidateCS(codeSystem string, concept *dtpb.CodeableConcept) (*ppb.Parameters, error)
+
+	// Translate translates the coding using the concept map with the given
+	// canonical URL, as the $translate operation.
+	Translate(conceptMap string, coding *dtpb.Coding) (*ppb.Parameters, error)
+}
+
+// SubsumptionOutcome is a code of the FHIR concept-subsumption-outcome value
+// set, which describes the relationship between two codes of a code system.
+type SubsumptionOutcome string
+
+const (
+	SubsumptionEquivalent  SubsumptionOutcome = "equivalent"
+	SubsumptionSubsumes    SubsumptionOutcome = "subsumes"
+	SubsumptionSubsumedBy  SubsumptionOutcome = "subsumed-by"
+	SubsumptionNotSubsumed SubsumptionOutcome = "not-subsumed"
+)
+
+// FHIRServer is a FHIR server, which is queried by the methods of the %server
+// constant, such as %server.read().
+type FHIRServer interface {
+	// Read returns the resource with the given type and id. A nil resource is
+	// returned if there is no such resource.
+	Read(resourceType, id string) (fhir.Resource, error)
+
+	// Search returns a searchset Bundle of the resources that match the given
+	// search, which is the relative URL of a search, such as
+	// "Patient?family=Chu".
+	Search(query string) (*bcrpb.Bundle, error)
+
+	// Validate validates the resource for the given mode of the $validate
+	// operation: "create", "update", "delete" or "profile". The profile is the
+	// canonical URL of the StructureDefinition to validate against, if any.
+	Validate(res fhir.Resource, mode, profile string) (*oopb.OperationOutcome, error)
+}
+
+// ProfileValidator validates resources and elements against the profiles
+// defined by StructureDefinition resources, for use by the conformsTo()
+// function.
+type ProfileValidator interface {
+	// Validate validates the element against the StructureDefinition with the
+	// given canonical URL, returning the issues found as an OperationOutcome. A
+	// nil outcome is returned if the profile is unknown.
+	Validate(element fhir.Base, profile string) (*oopb.OperationOutcome, error)
+}
+
+// StructureDefinitions provides the element definitions and slices defined by
+// StructureDefinition resources, for use by the slice() and
+// elementDefinition() functions.
+type StructureDefinitions interface {
+	// ElementDefinition returns the definition of the element at the given
+	// path, such as "Observation.valueQuantity.unit", from the base definitions
+	// of the resources and data types along the path. A nil definition is
+	// returned if the path is unknown.
+	ElementDefinition(path string) (*dtpb.ElementDefinition, error)
+
+	// InSlice reports whether the element is a member of the named slice of
+	// the StructureDefinition with the given canonical URL, according to the
+	// discriminators of the slicing. The slice is named by its slice name, or
+	// by its element id if the name is shared by several slicings. False is
+	// returned if the profile or the slice is unknown.
+	InSlice(element fhir.Base, structure, slice string) (bool, error)
 }
 
 // Clone copies this Context object to produce a new instance.
@@ -31,7 +204,36 @@ func (c *Context) Clone() *Context {
 		Now:               c.Now,
 		ExternalConstants: c.ExternalConstants,
 		LastResult:        c.LastResult,
+		Index:             c.Index,
+		Total:             c.Total,
+		TraceSink:         c.TraceSink,
+		Variables:         c.Variables,
+		Resolver:          c.Resolver,
+		Terminology:       c.Terminology,
+		Server:            c.Server,
+		Validator:         c.Validator,
+		Structures:        c.Structures,
+	}
+}
+
+// WithIndex returns a copy of this Context with the $index variable set to
+// the given index.
+func (c *Context) WithIndex(index int) *Context {
+	clone := c.Clone()
+	clone.Index = system.Collection{system.Integer(index)}
+	return clone
+}
+
+// WithVariable returns a copy of this Context with the named variable bound to
+// the given value, in addition to the variables already in scope.
+func (c *Context) WithVariable(name string, value system.Collection) *Context {
+	clone := c.Clone()
+	clone.Variables = make(map[string]system.Collection, len(c.Variables)+1)
+	for k, v := range c.Variables {
+		clone.Variables[k] = v
 	}
+	clone.Variables[name] = value
+	return clone
 }
 
 // InitializeContext returns a base context, initialized with current time and initial
@@ -43,5 +245,7 @@ func InitializeContext(input system.Collection) *Context {
 			"context": input,
 			"ucum":    system.String("http://unitsofmeasure.org"),
 		},
+		TraceSink: noopTraceSink{},
+		Resolver:  noopResolver{},
 	}
 }
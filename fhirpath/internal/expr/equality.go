@@ -0,0 +1,33 @@
+package expr
+
+import (
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"google.golang.org/protobuf/proto"
+)
+
+// ItemsEqual reports whether two items of a collection are equal. Items that
+// are convertible to System types are compared with system.TryEqual, while
+// complex types are compared by their proto representation. An equality that
+// can't be determined is treated as not equal.
+func ItemsEqual(lhs, rhs any) bool {
+	return systemEqual(lhs, rhs) || protoEqual(lhs, rhs)
+}
+
+func systemEqual(lhs, rhs any) bool {
+	l, lerr := system.From(lhs)
+	r, rerr := system.From(rhs)
+	if lerr == nil && rerr == nil {
+		got, ok := system.TryEqual(l, r)
+		return got && ok
+	}
+	return false
+}
+
+func protoEqual(lhs, rhs any) bool {
+	l, lok := lhs.(proto.Message)
+	r, rok := rhs.(proto.Message)
+	if lok && rok {
+		return proto.Equal(l, r)
+	}
+	return false
+}
@@ -28,6 +28,7 @@ var (
 	ErrToBeImplemented  = errors.New("expression not yet implemented")
 	ErrInvalidField     = errors.New("invalid field")
 	ErrConstantNotFound = errors.New("external constant not found")
+	ErrVariableDefined  = errors.New("variable already defined")
 )
 
 // Expression is the abstraction for all FHIRPath expressions,
@@ -46,21 +47,78 @@ type ExpressionSequence struct {
 // Evaluate iterates through the ExpressionSequence, feeding the output of
 // an evaluation to the next Expression.
 func (s *ExpressionSequence) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	_, output, err := s.evaluateScoped(ctx, input)
+	return output, err
+}
+
+// evaluateScoped evaluates the sequence, and additionally returns the context
+// containing the variables defined along the way, so that an enclosing
+// sequence can continue with them in scope.
+func (s *ExpressionSequence) evaluateScoped(ctx *Context, input system.Collection) (*Context, system.Collection, error) {
 	output := input
 
 	for _, expr := range s.Expressions {
-		result, err := expr.Evaluate(ctx, output)
+		var result system.Collection
+		var err error
+		if scoped, ok := expr.(scopedExpression); ok {
+			ctx, result, err = scoped.evaluateScoped(ctx, output)
+		} else {
+			result, err = expr.Evaluate(ctx, output)
+		}
 		// raise error as soon as one is encountered
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		output = result
 	}
-	return output, nil
+	return ctx, output, nil
 }
 
+var _ scopedExpression = (*ExpressionSequence)(nil)
+
 var _ Expression = (*ExpressionSequence)(nil)
 
+// scopedExpression is implemented by expressions that can bind variables for
+// the expressions that follow them in an ExpressionSequence.
+type scopedExpression interface {
+	evaluateScoped(ctx *Context, input system.Collection) (*Context, system.Collection, error)
+}
+
+// DefineVariableExpression binds the result of Value to the variable Name, so
+// that it can be read as %Name by the expressions that follow it in the same
+// sequence. If Value is nil, the input collection is bound instead.
+type DefineVariableExpression struct {
+	Name  string
+	Value Expression
+}
+
+// Evaluate returns the input collection unchanged. Outside of a sequence, the
+// variable has no expressions to be visible to, so nothing is bound.
+func (e *DefineVariableExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	_, output, err := e.evaluateScoped(ctx, input)
+	return output, err
+}
+
+func (e *DefineVariableExpression) evaluateScoped(ctx *Context, input system.Collection) (*Context, system.Collection, error) {
+	if _, ok := ctx.Variables[e.Name]; ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrVariableDefined, e.Name)
+	}
+	if _, ok := ctx.ExternalConstants[e.Name]; ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrVariableDefined, e.Name)
+	}
+	value := input
+	if e.Value != nil {
+		var err error
+		if value, err = e.Value.Evaluate(ctx.Clone(), input); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ctx.WithVariable(e.Name, value), input, nil
+}
+
+var _ Expression = (*DefineVariableExpression)(nil)
+var _ scopedExpression = (*DefineVariableExpression)(nil)
+
 // IdentityExpression encapsulates the top-level expression, ie. the
 // first step in the chain of evaluation. A no-op expression that
 // returns itself.
@@ -87,6 +145,19 @@ func (e *FieldExpression) Evaluate(ctx *Context, input system.Collection) (syste
 	output := system.Collection{}
 
 	for _, item := range input {
+		// Values of the reflection model returned by type() are navigable too.
+		if info, ok := item.(reflection.TypeInfo); ok {
+			value, ok := info.Field(e.FieldName)
+			if !ok {
+				if e.Permissive {
+					continue
+				}
+				return nil, e.errField(item)
+			}
+			output = append(output, value...)
+			continue
+		}
+
 		message, ok := item.(proto.Message)
 		if !ok {
 			if e.Permissive {
@@ -439,6 +510,37 @@ func (e *EqualityExpression) Evaluate(ctx *Context, input system.Collection) (sy
 
 var _ Expression = (*EqualityExpression)(nil)
 
+// EquivalenceExpression allows checking equivalence of the two contained
+// subexpressions.
+type EquivalenceExpression struct {
+	Left  Expression
+	Right Expression
+	Not   bool
+}
+
+// Evaluate evaluates the two subexpressions, and returns true if their
+// contents are equivalent, using the functionality of system.Collection.Equivalent.
+// Unlike equality, equivalence always returns a boolean: two empty collections
+// are equivalent, and values of mismatched precision are not.
+func (e *EquivalenceExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	leftResult, err := e.Left.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+	rightResult, err := e.Right.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := leftResult.Equivalent(rightResult)
+	if e.Not {
+		result = !result
+	}
+	return system.Collection{system.Boolean(result)}, nil
+}
+
+var _ Expression = (*EquivalenceExpression)(nil)
+
 // FunctionExpression enables evaluation of Function Invocation expressions.
 // It holds the function and function arguments.
 type FunctionExpression struct {
@@ -523,6 +625,19 @@ func (e *AsExpression) Evaluate(ctx *Context, input system.Collection) (system.C
 
 var _ Expression = (*AsExpression)(nil)
 
+// TypeSpecifierExpression holds a type specifier that was resolved at compile
+// time, for use as the argument of type functions such as ofType().
+type TypeSpecifierExpression struct {
+	Type reflection.TypeSpecifier
+}
+
+// Evaluate returns the type specifier as a singleton collection.
+func (e *TypeSpecifierExpression) Evaluate(*Context, system.Collection) (system.Collection, error) {
+	return system.Collection{e.Type}, nil
+}
+
+var _ Expression = (*TypeSpecifierExpression)(nil)
+
 // BooleanExpression enables evaluation of boolean expressions,
 // including "and", "or", "xor", and "implies".
 type BooleanExpression struct {
@@ -752,9 +867,12 @@ type ExternalConstantExpression struct {
 	Identifier string
 }
 
-// Evaluate retrieves the constant from the map located in the Context. Returns an error if the
-// constant is not present.
+// Evaluate retrieves the variable in scope, or otherwise the constant from the map located in
+// the Context. Returns an error if neither is present.
 func (e *ExternalConstantExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	if variable, ok := ctx.Variables[e.Identifier]; ok {
+		return variable, nil
+	}
 	constant, ok := ctx.ExternalConstants[e.Identifier]
 	if !ok {
 		return nil, fmt.Errorf("%w: %s", ErrConstantNotFound, e.Identifier)
@@ -808,3 +926,112 @@ func (e *NegationExpression) Evaluate(ctx *Context, input system.Collection) (sy
 }
 
 var _ Expression = (*NegationExpression)(nil)
+
+// UnionExpression enables evaluation of the union operator '|', which merges
+// the results of the two subexpressions.
+type UnionExpression struct {
+	Left  Expression
+	Right Expression
+}
+
+// Evaluate evaluates both subexpressions and merges their results, eliminating
+// any duplicate values using the same equality semantics as distinct(). Order
+// of the left result is preserved, followed by new items from the right result.
+func (e *UnionExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	leftResult, err := e.Left.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+	rightResult, err := e.Right.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := system.Collection{}
+	for _, item := range append(append(system.Collection{}, leftResult...), rightResult...) {
+		if result.Contains(item) {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+var _ Expression = (*UnionExpression)(nil)
+
+// MembershipExpression enables evaluation of the membership operators "in"
+// and "contains".
+type MembershipExpression struct {
+	Left  Expression
+	Right Expression
+	Op    Operator
+}
+
+// Evaluate evaluates the two subexpressions and determines whether the singleton
+// operand is a member of the collection operand. For "in" the singleton is the
+// left operand, and for "contains" it is the right operand. If the singleton
+// operand is empty, returns an empty collection; if it contains more than one
+// item, returns an error.
+func (e *MembershipExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	leftResult, err := e.Left.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+	rightResult, err := e.Right.Evaluate(ctx.Clone(), input)
+	if err != nil {
+		return nil, err
+	}
+
+	var element, collection system.Collection
+	switch e.Op {
+	case In:
+		element, collection = leftResult, rightResult
+	case Contains:
+		element, collection = rightResult, leftResult
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOperator, e.Op)
+	}
+
+	if len(element) == 0 {
+		return system.Collection{}, nil
+	}
+	if len(element) > 1 {
+		return nil, fmt.Errorf("%w: membership operand contains %v elements", ErrNotSingleton, len(element))
+	}
+	for _, item := range collection {
+		if ItemsEqual(element[0], item) {
+			return system.Collection{system.Boolean(true)}, nil
+		}
+	}
+	return system.Collection{system.Boolean(false)}, nil
+}
+
+var _ Expression = (*MembershipExpression)(nil)
+
+// IndexVariableExpression enables evaluation of the $index variable.
+type IndexVariableExpression struct{}
+
+// Evaluate returns the index of the item currently being iterated over, as held
+// in the Context. Returns an empty collection if no iteration is in progress.
+func (*IndexVariableExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	if ctx.Index == nil {
+		return system.Collection{}, nil
+	}
+	return ctx.Index, nil
+}
+
+var _ Expression = (*IndexVariableExpression)(nil)
+
+// TotalVariableExpression enables evaluation of the $total variable.
+type TotalVariableExpression struct{}
+
+// Evaluate returns the running total of the aggregate() function, as held in the
+// Context. Returns an empty collection if no aggregation is in progress.
+func (*TotalVariableExpression) Evaluate(ctx *Context, input system.Collection) (system.Collection, error) {
+	if ctx.Total == nil {
+		return system.Collection{}, nil
+	}
+	return ctx.Total, nil
+}
+
+var _ Expression = (*TotalVariableExpression)(nil)
@@ -286,6 +286,15 @@ func TestFieldExpression_ValidInput_GetsField(t *testing.T) {
 			want: system.Collection(
 				slices.MustConvert[any](entries),
 			),
+		}, {
+			name: "Property of type information",
+			input: system.Collection{reflection.ClassInfo{
+				Namespace: "FHIR",
+				Name:      "Patient",
+				Element:   []reflection.ClassInfoElement{{Name: "id", Type: "FHIR.id"}},
+			}},
+			field: "element",
+			want:  system.Collection{reflection.ClassInfoElement{Name: "id", Type: "FHIR.id"}},
 		}, {
 			name:  "Field on empty input returns empty",
 			input: nil,
@@ -682,6 +691,78 @@ func TestEqualityExpression_RaisesError(t *testing.T) {
 	}
 }
 
+func TestEquivalenceExpression_ReturnsResult(t *testing.T) {
+	testCases := []struct {
+		name            string
+		equivalenceExpr *expr.EquivalenceExpression
+		wantCollection  system.Collection
+	}{
+		{
+			name:            "two empty collections",
+			equivalenceExpr: &expr.EquivalenceExpression{Left: exprtest.Return(), Right: exprtest.Return()},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "one empty collection",
+			equivalenceExpr: &expr.EquivalenceExpression{Left: exprtest.Return(), Right: exprtest.Return(system.String("one"))},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "strings of different case",
+			equivalenceExpr: &expr.EquivalenceExpression{Left: exprtest.Return(fhir.String("ABC")), Right: exprtest.Return(system.String("abc"))},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name: "comparing with !~ operator",
+			equivalenceExpr: &expr.EquivalenceExpression{
+				Left:  exprtest.Return(system.MustParseDate("2020")),
+				Right: exprtest.Return(system.MustParseDate("2020-01")),
+				Not:   true,
+			},
+			wantCollection: system.Collection{system.Boolean(true)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.equivalenceExpr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if err != nil {
+				t.Fatalf("EquivalenceExpression.Evaluate raised unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got); diff != "" {
+				t.Errorf("EquivalenceExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEquivalenceExpression_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name            string
+		equivalenceExpr *expr.EquivalenceExpression
+	}{
+		{
+			name:            "subexpression one errors",
+			equivalenceExpr: &expr.EquivalenceExpression{Left: exprtest.Error(errMock), Right: exprtest.Return(system.Boolean(true))},
+		},
+		{
+			name:            "subexpression two errors",
+			equivalenceExpr: &expr.EquivalenceExpression{Left: exprtest.Return(system.Boolean(true)), Right: exprtest.Error(errMock)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.equivalenceExpr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if err == nil {
+				t.Fatalf("EquivalenceExpression.Evaluate didn't propagate error when it should have")
+			}
+		})
+	}
+}
+
 func TestIsExpression_ReturnsResult(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -1463,3 +1544,367 @@ func TestNegationExpression(t *testing.T) {
 		})
 	}
 }
+
+func TestUnionExpression(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expr    *expr.UnionExpression
+		want    system.Collection
+		wantErr error
+	}{
+		{
+			name: "returns empty collection when both sides are empty",
+			expr: &expr.UnionExpression{Left: exprtest.Return(), Right: exprtest.Return()},
+			want: system.Collection{},
+		},
+		{
+			name: "merges both sides and removes duplicates",
+			expr: &expr.UnionExpression{
+				Left:  exprtest.Return(system.Integer(1), system.Integer(1), system.Integer(2)),
+				Right: exprtest.Return(system.Integer(2), system.Integer(3)),
+			},
+			want: system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		},
+		{
+			name: "removes duplicates across proto and system types",
+			expr: &expr.UnionExpression{
+				Left:  exprtest.Return(fhir.String("a")),
+				Right: exprtest.Return(system.String("a"), system.String("b")),
+			},
+			want: system.Collection{fhir.String("a"), system.String("b")},
+		},
+		{
+			name:    "propagates error from left side",
+			expr:    &expr.UnionExpression{Left: exprtest.Error(errMock), Right: exprtest.Return()},
+			wantErr: errMock,
+		},
+		{
+			name:    "propagates error from right side",
+			expr:    &expr.UnionExpression{Left: exprtest.Return(), Right: exprtest.Error(errMock)},
+			wantErr: errMock,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.expr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("UnionExpression.Evaluate returned unexpected error: got %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("UnionExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMembershipExpression(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expr    *expr.MembershipExpression
+		want    system.Collection
+		wantErr error
+	}{
+		{
+			name: "in returns true if element is in collection",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(system.String("active")),
+				Right: exprtest.Return(fhir.Code("draft"), fhir.Code("active")),
+				Op:    expr.In,
+			},
+			want: system.Collection{system.Boolean(true)},
+		},
+		{
+			name: "in returns false if element is not in collection",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(system.Integer(3)),
+				Right: exprtest.Return(system.Integer(1), system.Integer(2)),
+				Op:    expr.In,
+			},
+			want: system.Collection{system.Boolean(false)},
+		},
+		{
+			name: "in returns false if collection is empty",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(system.Integer(3)),
+				Right: exprtest.Return(),
+				Op:    expr.In,
+			},
+			want: system.Collection{system.Boolean(false)},
+		},
+		{
+			name: "in returns empty if element is empty",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(),
+				Right: exprtest.Return(system.Integer(1)),
+				Op:    expr.In,
+			},
+			want: system.Collection{},
+		},
+		{
+			name: "contains returns true if collection contains complex element",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(fhir.Coding("system", "a"), fhir.Coding("system", "b")),
+				Right: exprtest.Return(fhir.Coding("system", "b")),
+				Op:    expr.Contains,
+			},
+			want: system.Collection{system.Boolean(true)},
+		},
+		{
+			name: "contains normalizes integers and decimals",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(fhir.Integer(1)),
+				Right: exprtest.Return(system.MustParseDecimal("1.0")),
+				Op:    expr.Contains,
+			},
+			want: system.Collection{system.Boolean(true)},
+		},
+		{
+			name: "contains returns empty if element is empty",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(system.Integer(1)),
+				Right: exprtest.Return(),
+				Op:    expr.Contains,
+			},
+			want: system.Collection{},
+		},
+		{
+			name: "in raises error if element is not a singleton",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(system.Integer(1), system.Integer(2)),
+				Right: exprtest.Return(system.Integer(1)),
+				Op:    expr.In,
+			},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name: "contains raises error if element is not a singleton",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(system.Integer(1)),
+				Right: exprtest.Return(system.Integer(1), system.Integer(2)),
+				Op:    expr.Contains,
+			},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name: "raises error on invalid operator",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Return(system.Integer(1)),
+				Right: exprtest.Return(system.Integer(1)),
+				Op:    expr.Equals,
+			},
+			wantErr: expr.ErrInvalidOperator,
+		},
+		{
+			name: "propagates error from subexpression",
+			expr: &expr.MembershipExpression{
+				Left:  exprtest.Error(errMock),
+				Right: exprtest.Return(system.Integer(1)),
+				Op:    expr.In,
+			},
+			wantErr: errMock,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.expr.Evaluate(&expr.Context{}, system.Collection{})
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("MembershipExpression.Evaluate returned unexpected error: got %v, want %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("MembershipExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIndexVariableExpression(t *testing.T) {
+	testCases := []struct {
+		name    string
+		context *expr.Context
+		want    system.Collection
+	}{
+		{
+			name:    "returns empty outside of iteration",
+			context: &expr.Context{},
+			want:    system.Collection{},
+		},
+		{
+			name:    "returns index from context",
+			context: (&expr.Context{}).WithIndex(2),
+			want:    system.Collection{system.Integer(2)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := (&expr.IndexVariableExpression{}).Evaluate(tc.context, system.Collection{})
+
+			if err != nil {
+				t.Fatalf("IndexVariableExpression.Evaluate raised unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("IndexVariableExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTotalVariableExpression(t *testing.T) {
+	testCases := []struct {
+		name    string
+		context *expr.Context
+		want    system.Collection
+	}{
+		{
+			name:    "returns empty outside of aggregation",
+			context: &expr.Context{},
+			want:    system.Collection{},
+		},
+		{
+			name:    "returns total from context",
+			context: &expr.Context{Total: system.Collection{system.Integer(10)}},
+			want:    system.Collection{system.Integer(10)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := (&expr.TotalVariableExpression{}).Evaluate(tc.context, system.Collection{})
+
+			if err != nil {
+				t.Fatalf("TotalVariableExpression.Evaluate raised unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("TotalVariableExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDefineVariableExpression(t *testing.T) {
+	readVariable := &expr.ExternalConstantExpression{Identifier: "x"}
+	testCases := []struct {
+		name       string
+		expression expr.Expression
+		input      system.Collection
+		want       system.Collection
+	}{
+		{
+			name: "binds value for the rest of the sequence",
+			expression: &expr.ExpressionSequence{Expressions: []expr.Expression{
+				&expr.DefineVariableExpression{Name: "x", Value: exprtest.Return(system.Integer(1))},
+				readVariable,
+			}},
+			input: system.Collection{system.String("input")},
+			want:  system.Collection{system.Integer(1)},
+		},
+		{
+			name: "binds input when no value is given",
+			expression: &expr.ExpressionSequence{Expressions: []expr.Expression{
+				&expr.DefineVariableExpression{Name: "x"},
+				readVariable,
+			}},
+			input: system.Collection{system.String("input")},
+			want:  system.Collection{system.String("input")},
+		},
+		{
+			name: "binding propagates out of nested sequences",
+			expression: &expr.ExpressionSequence{Expressions: []expr.Expression{
+				&expr.ExpressionSequence{Expressions: []expr.Expression{
+					&expr.IdentityExpression{},
+					&expr.DefineVariableExpression{Name: "x", Value: exprtest.Return(system.Integer(1))},
+				}},
+				readVariable,
+			}},
+			input: system.Collection{},
+			want:  system.Collection{system.Integer(1)},
+		},
+		{
+			name:       "returns input unchanged",
+			expression: &expr.DefineVariableExpression{Name: "x", Value: exprtest.Return(system.Integer(1))},
+			input:      system.Collection{system.String("input")},
+			want:       system.Collection{system.String("input")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.expression.Evaluate(&expr.Context{}, tc.input)
+
+			if err != nil {
+				t.Fatalf("DefineVariableExpression.Evaluate raised unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("DefineVariableExpression.Evaluate returned unexpected diff: (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDefineVariableExpression_DoesNotLeakToSiblings(t *testing.T) {
+	ctx := &expr.Context{}
+	union := &expr.UnionExpression{
+		Left: &expr.ExpressionSequence{Expressions: []expr.Expression{
+			&expr.DefineVariableExpression{Name: "x", Value: exprtest.Return(system.Integer(1))},
+		}},
+		Right: &expr.ExternalConstantExpression{Identifier: "x"},
+	}
+
+	_, err := union.Evaluate(ctx, system.Collection{})
+
+	if !errors.Is(err, expr.ErrConstantNotFound) {
+		t.Errorf("UnionExpression.Evaluate returned unexpected error: got %v, want %v", err, expr.ErrConstantNotFound)
+	}
+	if ctx.Variables != nil {
+		t.Errorf("DefineVariableExpression modified the caller's variables: %v", ctx.Variables)
+	}
+}
+
+func TestDefineVariableExpression_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		context    *expr.Context
+		expression expr.Expression
+		wantErr    error
+	}{
+		{
+			name:    "variable is already defined",
+			context: (&expr.Context{}).WithVariable("x", system.Collection{}),
+			expression: &expr.DefineVariableExpression{
+				Name: "x",
+			},
+			wantErr: expr.ErrVariableDefined,
+		},
+		{
+			name:    "variable shadows an environment variable",
+			context: &expr.Context{ExternalConstants: map[string]any{"x": system.Integer(1)}},
+			expression: &expr.DefineVariableExpression{
+				Name: "x",
+			},
+			wantErr: expr.ErrVariableDefined,
+		},
+		{
+			name:    "value raises error",
+			context: &expr.Context{},
+			expression: &expr.DefineVariableExpression{
+				Name:  "x",
+				Value: exprtest.Error(errMock),
+			},
+			wantErr: errMock,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.expression.Evaluate(tc.context, system.Collection{})
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("DefineVariableExpression.Evaluate returned unexpected error: got %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
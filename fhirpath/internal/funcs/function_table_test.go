@@ -47,3 +47,31 @@ func TestRegister_AddsToMap(t *testing.T) {
 		t.Errorf("FunctionTable.Register did not successfully add function to map")
 	}
 }
+
+func TestClone_ContainsFHIRFuncs(t *testing.T) {
+	table := funcs.Clone()
+
+	for _, name := range []string{"extension", "memberOf", "conformsTo", "hasValue", "slice"} {
+		if _, ok := table[name]; !ok {
+			t.Errorf("funcs.Clone() does not contain FHIR function %s", name)
+		}
+	}
+}
+
+func TestAddExperimentalFuncs_AddsToMap(t *testing.T) {
+	experimental := []string{"trim", "split", "encode", "escape", "defineVariable", "sort", "sum", "lowBoundary", "yearOf", "duration"}
+	table := funcs.Clone()
+	for _, name := range experimental {
+		if _, ok := table[name]; ok {
+			t.Fatalf("funcs.Clone() contains experimental function %s", name)
+		}
+	}
+
+	table = funcs.AddExperimentalFuncs(table)
+
+	for _, name := range experimental {
+		if _, ok := table[name]; !ok {
+			t.Errorf("funcs.AddExperimentalFuncs() did not add function %s", name)
+		}
+	}
+}
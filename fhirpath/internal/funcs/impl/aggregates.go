@@ -0,0 +1,39 @@
+package impl
+
+import (
+	"fmt"
+
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+)
+
+// Aggregate performs general-purpose aggregation by evaluating the aggregator
+// expression for each element of the input collection. Within the expression,
+// $this refers to the current item, $index to its index, and $total to the
+// result of the previous evaluation of the aggregator. $total starts as the
+// value of the optional init argument, or empty if it is not provided.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#aggregateaggregator-expression-init-value-value
+func Aggregate(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1 or 2", ErrWrongArity, len(args))
+	}
+	total := system.Collection{}
+	if len(args) == 2 {
+		init, err := args[1].Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		total = init
+	}
+	for i, item := range input {
+		itemCtx := ctx.WithIndex(i)
+		itemCtx.Total = total
+
+		output, err := args[0].Evaluate(itemCtx, system.Collection{item})
+		if err != nil {
+			return nil, err
+		}
+		total = output
+	}
+	return total, nil
+}
@@ -0,0 +1,88 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+)
+
+func TestAggregate(t *testing.T) {
+	sum := &expr.ArithmeticExpression{
+		Left:  &expr.TotalVariableExpression{},
+		Right: &expr.IdentityExpression{},
+		Op:    expr.EvaluateAdd,
+	}
+	indexSum := &expr.ArithmeticExpression{
+		Left:  &expr.TotalVariableExpression{},
+		Right: &expr.IndexVariableExpression{},
+		Op:    expr.EvaluateAdd,
+	}
+
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:    "errors if aggregator is not provided",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+		{
+			name:  "returns init if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{sum, exprtest.Return(system.Integer(0))},
+			want:  system.Collection{system.Integer(0)},
+		},
+		{
+			name:  "returns empty if input is empty and init is not provided",
+			input: system.Collection{},
+			args:  []expr.Expression{sum},
+			want:  system.Collection{},
+		},
+		{
+			name:  "sums input with running total",
+			input: system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+			args:  []expr.Expression{sum, exprtest.Return(system.Integer(0))},
+			want:  system.Collection{system.Integer(6)},
+		},
+		{
+			name:  "exposes index to aggregator",
+			input: system.Collection{system.String("a"), system.String("b"), system.String("c")},
+			args:  []expr.Expression{indexSum, exprtest.Return(system.Integer(0))},
+			want:  system.Collection{system.Integer(3)},
+		},
+		{
+			name:    "propagates aggregator error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+		{
+			name:    "propagates init error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{sum, exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Aggregate(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Aggregate() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Aggregate() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package impl
+
+import (
+	"fmt"
+
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+)
+
+// Union merges the input and other collections into a single collection,
+// eliminating any duplicate values using the same equality semantics as
+// distinct(). There is no expectation of order in the resulting collection.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#unionother-collection
+func Union(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	union := &expr.UnionExpression{Left: &expr.IdentityExpression{}, Right: args[0]}
+	return union.Evaluate(ctx, input)
+}
+
+// Combine merges the input and other collections into a single collection
+// without eliminating duplicate values. Combining an empty collection with a
+// non-empty collection will return the non-empty collection.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#combineother-collection-collection
+func Combine(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	argValues, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result := system.Collection{}
+	result = append(result, input...)
+	return append(result, argValues...), nil
+}
@@ -0,0 +1,126 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestUnion(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:    "errors if arg is not provided",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+		{
+			name:  "returns empty collection if both collections are empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns other collection if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(1))},
+			want:  system.Collection{system.Integer(1)},
+		},
+		{
+			name:  "merges collections and removes duplicates",
+			input: system.Collection{system.Integer(1), system.Integer(2)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(2), system.Integer(3))},
+			want:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		},
+		{
+			name:  "removes duplicates across proto and system types",
+			input: system.Collection{fhir.String("a"), system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return(system.String("a"), fhir.Integer(1))},
+			want:  system.Collection{fhir.String("a"), system.Integer(1)},
+		},
+		{
+			name:  "removes duplicate complex types",
+			input: system.Collection{fhir.Coding("system", "code")},
+			args:  []expr.Expression{exprtest.Return(fhir.Coding("system", "code"))},
+			want:  system.Collection{fhir.Coding("system", "code")},
+		},
+		{
+			name:    "propagates argument error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Union(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Union() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Union() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:    "errors if arg is not provided",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+		{
+			name:  "returns other collection if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1))},
+			want:  system.Collection{system.Integer(1)},
+		},
+		{
+			name:  "merges collections without removing duplicates",
+			input: system.Collection{system.Integer(1), fhir.String("a")},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1), system.String("a"))},
+			want:  system.Collection{system.Integer(1), fhir.String("a"), system.Integer(1), system.String("a")},
+		},
+		{
+			name:    "propagates argument error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Combine(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Combine() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Combine() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
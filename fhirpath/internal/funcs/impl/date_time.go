@@ -0,0 +1,246 @@
+package impl
+
+import (
+	"fmt"
+
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+)
+
+// componentValue is implemented by the System types that have date or time
+// components.
+type componentValue interface {
+	Component(system.Component) (int, bool)
+}
+
+// YearOf returns the year component of the input Date or DateTime.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#yearof--integer
+func YearOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return componentOf(input, system.YearComponent, args...)
+}
+
+// MonthOf returns the month component of the input Date or DateTime.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#monthof--integer
+func MonthOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return componentOf(input, system.MonthComponent, args...)
+}
+
+// DayOf returns the day component of the input Date or DateTime.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#dayof--integer
+func DayOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return componentOf(input, system.DayComponent, args...)
+}
+
+// HourOf returns the hour component of the input DateTime or Time.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#hourof--integer
+func HourOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return componentOf(input, system.HourComponent, args...)
+}
+
+// MinuteOf returns the minute component of the input DateTime or Time.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#minuteof--integer
+func MinuteOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return componentOf(input, system.MinuteComponent, args...)
+}
+
+// SecondOf returns the second component of the input DateTime or Time.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#secondof--integer
+func SecondOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return componentOf(input, system.SecondComponent, args...)
+}
+
+// MillisecondOf returns the millisecond component of the input DateTime or
+// Time.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#millisecondof--integer
+func MillisecondOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return componentOf(input, system.MillisecondComponent, args...)
+}
+
+// componentOf returns the given component of the single Date, DateTime or
+// Time in the input. Returns empty if the component exceeds the precision of
+// the value.
+func componentOf(input system.Collection, component system.Component, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	item, err := singletonSystemValue(input)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return system.Collection{}, nil
+	}
+	value, ok := item.(componentValue)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a Date, DateTime or Time, got %T", ErrInvalidInput, item)
+	}
+	result, ok := value.Component(component)
+	if !ok {
+		return system.Collection{}, nil
+	}
+	return system.Collection{system.Integer(result)}, nil
+}
+
+// TimezoneOffsetOf returns the timezone offset of the input DateTime in
+// hours, or empty if the DateTime has no timezone offset. Returns empty for a
+// Date or Time, which has no timezone offset.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#timezoneoffsetof--decimal
+func TimezoneOffsetOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	item, err := temporalInput(input, args...)
+	if item == nil || err != nil {
+		return system.Collection{}, err
+	}
+	if dateTime, ok := item.(system.DateTime); ok {
+		if offset, ok := dateTime.TimezoneOffset(); ok {
+			return system.Collection{offset}, nil
+		}
+	}
+	return system.Collection{}, nil
+}
+
+// DateOf returns the date part of the input DateTime. A Date is returned
+// unchanged, and a Time results in empty.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#dateof--date
+func DateOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	item, err := temporalInput(input, args...)
+	if item == nil || err != nil {
+		return system.Collection{}, err
+	}
+	switch item := item.(type) {
+	case system.Date:
+		return system.Collection{item}, nil
+	case system.DateTime:
+		if date, ok := item.Date(); ok {
+			return system.Collection{date}, nil
+		}
+	}
+	return system.Collection{}, nil
+}
+
+// TimeOf returns the time part of the input DateTime, or empty if the
+// DateTime has no time part. A Time is returned unchanged, and a Date results
+// in empty.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#timeof--time
+func TimeOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	item, err := temporalInput(input, args...)
+	if item == nil || err != nil {
+		return system.Collection{}, err
+	}
+	switch item := item.(type) {
+	case system.Time:
+		return system.Collection{item}, nil
+	case system.DateTime:
+		if t, ok := item.Time(); ok {
+			return system.Collection{t}, nil
+		}
+	}
+	return system.Collection{}, nil
+}
+
+// temporalInput returns the single Date, DateTime or Time in the input.
+// Returns nil if the input is empty.
+func temporalInput(input system.Collection, args ...expr.Expression) (system.Any, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	item, err := singletonSystemValue(input)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	switch item.(type) {
+	case system.Date, system.DateTime, system.Time:
+		return item, nil
+	default:
+		return nil, fmt.Errorf("%w: expected a Date, DateTime or Time, got %T", ErrInvalidInput, item)
+	}
+}
+
+// Duration returns the number of whole calendar periods of the unit given by
+// args[1] from the input until the value given by args[0]. Years and months
+// follow calendar semantics. Returns empty if the unit is more precise than
+// either value.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#durationvalue-t-precision-string--integer
+func Duration(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return between(ctx, input, false, args...)
+}
+
+// Difference returns the number of boundaries of the unit given by args[1]
+// that are crossed from the input until the value given by args[0]. Returns
+// empty if the unit is more precise than either value.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#differencevalue-t-precision-string--integer
+func Difference(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return between(ctx, input, true, args...)
+}
+
+// between implements both Duration and Difference.
+func between(ctx *expr.Context, input system.Collection, difference bool, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2", ErrWrongArity, len(args))
+	}
+	from, err := singletonSystemValue(input)
+	if err != nil {
+		return nil, err
+	}
+	otherResult, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	to, err := singletonSystemValue(otherResult)
+	if err != nil {
+		return nil, err
+	}
+	unit, ok, err := stringArg(ctx, input, args[1])
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil || !ok {
+		return system.Collection{}, nil
+	}
+
+	// Dates are compared with DateTimes as DateTimes with the same precision.
+	from = system.Normalize(from, to)
+	to = system.Normalize(to, from)
+
+	var result system.Quantity
+	switch lhs := from.(type) {
+	case system.Date:
+		rhs, isSameType := to.(system.Date)
+		if !isSameType {
+			return nil, fmt.Errorf("%w: %T, %T", system.ErrTypeMismatch, from, to)
+		}
+		if difference {
+			result, ok, err = lhs.Difference(rhs, unit)
+		} else {
+			result, ok, err = lhs.Duration(rhs, unit)
+		}
+	case system.DateTime:
+		rhs, isSameType := to.(system.DateTime)
+		if !isSameType {
+			return nil, fmt.Errorf("%w: %T, %T", system.ErrTypeMismatch, from, to)
+		}
+		if difference {
+			result, ok, err = lhs.Difference(rhs, unit)
+		} else {
+			result, ok, err = lhs.Duration(rhs, unit)
+		}
+	case system.Time:
+		rhs, isSameType := to.(system.Time)
+		if !isSameType {
+			return nil, fmt.Errorf("%w: %T, %T", system.ErrTypeMismatch, from, to)
+		}
+		if difference {
+			result, ok, err = lhs.Difference(rhs, unit)
+		} else {
+			result, ok, err = lhs.Duration(rhs, unit)
+		}
+	default:
+		return nil, fmt.Errorf("%w: expected a Date, DateTime or Time, got %T", ErrInvalidInput, from)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return system.Collection{}, nil
+	}
+	return system.Collection{result}, nil
+}
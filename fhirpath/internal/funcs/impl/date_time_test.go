@@ -0,0 +1,381 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+)
+
+func TestComponentOf(t *testing.T) {
+	dateTime := system.MustParseDateTime("2012-01-02T13:30:45.123-07:00")
+	testCases := []struct {
+		name  string
+		fn    func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			fn:    impl.YearOf,
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "year of date",
+			fn:    impl.YearOf,
+			input: system.Collection{system.MustParseDate("2012-01")},
+			want:  system.Collection{system.Integer(2012)},
+		},
+		{
+			name:  "month of date time",
+			fn:    impl.MonthOf,
+			input: system.Collection{dateTime},
+			want:  system.Collection{system.Integer(1)},
+		},
+		{
+			name:  "day of FHIR date",
+			fn:    impl.DayOf,
+			input: system.Collection{fhir.MustParseDate("2012-01-02")},
+			want:  system.Collection{system.Integer(2)},
+		},
+		{
+			name:  "day exceeds precision",
+			fn:    impl.DayOf,
+			input: system.Collection{system.MustParseDate("2012-01")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "hour of date time",
+			fn:    impl.HourOf,
+			input: system.Collection{dateTime},
+			want:  system.Collection{system.Integer(13)},
+		},
+		{
+			name:  "minute of time",
+			fn:    impl.MinuteOf,
+			input: system.Collection{system.MustParseTime("13:30")},
+			want:  system.Collection{system.Integer(30)},
+		},
+		{
+			name:  "second exceeds precision",
+			fn:    impl.SecondOf,
+			input: system.Collection{system.MustParseTime("13:30")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "millisecond of date time",
+			fn:    impl.MillisecondOf,
+			input: system.Collection{dateTime},
+			want:  system.Collection{system.Integer(123)},
+		},
+		{
+			name:  "hour of date",
+			fn:    impl.HourOf,
+			input: system.Collection{system.MustParseDate("2012-01-02")},
+			want:  system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("component function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("component function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestComponentOf_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fn    func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		input system.Collection
+		args  []expr.Expression
+	}{
+		{
+			name:  "input is not a date",
+			fn:    impl.YearOf,
+			input: system.Collection{system.String("2012")},
+		},
+		{
+			name:  "input is not a singleton",
+			fn:    impl.YearOf,
+			input: system.Collection{system.MustParseDate("2012"), system.MustParseDate("2013")},
+		},
+		{
+			name:  "too many arguments",
+			fn:    impl.YearOf,
+			input: system.Collection{system.MustParseDate("2012")},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1))},
+		},
+		{
+			name:  "timezone offset of string",
+			fn:    impl.TimezoneOffsetOf,
+			input: system.Collection{system.String("2012")},
+		},
+		{
+			name:  "date of integer",
+			fn:    impl.DateOf,
+			input: system.Collection{system.Integer(2012)},
+		},
+		{
+			name:  "time with arguments",
+			fn:    impl.TimeOf,
+			input: system.Collection{system.MustParseDateTime("2012-01-01T12:00")},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1))},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.fn(&expr.Context{}, tc.input, tc.args...); err == nil {
+				t.Errorf("component function didn't return error when expected")
+			}
+		})
+	}
+}
+
+func TestTimezoneOffsetOf(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "date time with offset",
+			input: system.Collection{system.MustParseDateTime("2012-01-01T12:30:00.000-07:00")},
+			want:  system.Collection{system.MustParseDecimal("-7")},
+		},
+		{
+			name:  "date time without offset",
+			input: system.Collection{system.MustParseDateTime("2012-01-01T12:30:00")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "date",
+			input: system.Collection{system.MustParseDate("2012-01-01")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "time",
+			input: system.Collection{system.MustParseTime("12:30:00")},
+			want:  system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.TimezoneOffsetOf(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("impl.TimezoneOffsetOf() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("impl.TimezoneOffsetOf() returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDateOfTimeOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    system.Collection
+		wantDate system.Collection
+		wantTime system.Collection
+	}{
+		{
+			name:     "empty input",
+			input:    system.Collection{},
+			wantDate: system.Collection{},
+			wantTime: system.Collection{},
+		},
+		{
+			name:     "date time with time",
+			input:    system.Collection{system.MustParseDateTime("2012-01-02T13:30:45.123-07:00")},
+			wantDate: system.Collection{system.MustParseDate("2012-01-02")},
+			wantTime: system.Collection{system.MustParseTime("13:30:45.123")},
+		},
+		{
+			name:     "partial date time",
+			input:    system.Collection{system.MustParseDateTime("2012-01T")},
+			wantDate: system.Collection{system.MustParseDate("2012-01")},
+			wantTime: system.Collection{},
+		},
+		{
+			name:     "FHIR instant",
+			input:    system.Collection{fhir.MustParseInstant("2012-01-01T13:30:45Z")},
+			wantDate: system.Collection{system.MustParseDate("2012-01-01")},
+			wantTime: system.Collection{system.MustParseTime("13:30:45")},
+		},
+		{
+			name:     "date",
+			input:    system.Collection{system.MustParseDate("2012-01")},
+			wantDate: system.Collection{system.MustParseDate("2012-01")},
+			wantTime: system.Collection{},
+		},
+		{
+			name:     "FHIR date",
+			input:    system.Collection{fhir.MustParseDate("2012-01-02")},
+			wantDate: system.Collection{system.MustParseDate("2012-01-02")},
+			wantTime: system.Collection{},
+		},
+		{
+			name:     "time",
+			input:    system.Collection{system.MustParseTime("13:30:45")},
+			wantDate: system.Collection{},
+			wantTime: system.Collection{system.MustParseTime("13:30:45")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotDate, err := impl.DateOf(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("impl.DateOf() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantDate, gotDate); diff != "" {
+				t.Errorf("impl.DateOf() returned unexpected diff (-want, +got):\n%s", diff)
+			}
+			gotTime, err := impl.TimeOf(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("impl.TimeOf() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantTime, gotTime); diff != "" {
+				t.Errorf("impl.TimeOf() returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDurationDifference(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          system.Collection
+		args           []expr.Expression
+		wantDuration   system.Collection
+		wantDifference system.Collection
+	}{
+		{
+			name:           "empty input",
+			input:          system.Collection{},
+			args:           []expr.Expression{exprtest.Return(system.MustParseDate("2020-01-01")), exprtest.Return(system.String("years"))},
+			wantDuration:   system.Collection{},
+			wantDifference: system.Collection{},
+		},
+		{
+			name:           "empty value",
+			input:          system.Collection{system.MustParseDate("2020-01-01")},
+			args:           []expr.Expression{exprtest.Return(), exprtest.Return(system.String("years"))},
+			wantDuration:   system.Collection{},
+			wantDifference: system.Collection{},
+		},
+		{
+			name:           "years between FHIR date and date",
+			input:          system.Collection{fhir.MustParseDate("2000-03-22")},
+			args:           []expr.Expression{exprtest.Return(system.MustParseDate("2024-03-21")), exprtest.Return(system.String("years"))},
+			wantDuration:   system.Collection{system.MustParseQuantity("23", "years")},
+			wantDifference: system.Collection{system.MustParseQuantity("24", "years")},
+		},
+		{
+			name:           "date and date time",
+			input:          system.Collection{system.MustParseDate("2020-03-01")},
+			args:           []expr.Expression{exprtest.Return(system.MustParseDateTime("2020-03-03T10:00")), exprtest.Return(system.String("days"))},
+			wantDuration:   system.Collection{system.MustParseQuantity("2", "days")},
+			wantDifference: system.Collection{system.MustParseQuantity("2", "days")},
+		},
+		{
+			name:           "times",
+			input:          system.Collection{system.MustParseTime("10:30")},
+			args:           []expr.Expression{exprtest.Return(system.MustParseTime("12:15")), exprtest.Return(system.String("hours"))},
+			wantDuration:   system.Collection{system.MustParseQuantity("1", "hours")},
+			wantDifference: system.Collection{system.MustParseQuantity("2", "hours")},
+		},
+		{
+			name:           "unit exceeds precision",
+			input:          system.Collection{system.MustParseDate("2020-03")},
+			args:           []expr.Expression{exprtest.Return(system.MustParseDate("2020-05")), exprtest.Return(system.String("days"))},
+			wantDuration:   system.Collection{},
+			wantDifference: system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotDuration, err := impl.Duration(&expr.Context{}, tc.input, tc.args...)
+			if err != nil {
+				t.Fatalf("impl.Duration() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantDuration, gotDuration); diff != "" {
+				t.Errorf("impl.Duration() returned unexpected diff (-want, +got):\n%s", diff)
+			}
+			gotDifference, err := impl.Difference(&expr.Context{}, tc.input, tc.args...)
+			if err != nil {
+				t.Fatalf("impl.Difference() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantDifference, gotDifference); diff != "" {
+				t.Errorf("impl.Difference() returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDurationDifference_RaisesError(t *testing.T) {
+	date := system.MustParseDate("2020-03-01")
+	testCases := []struct {
+		name  string
+		input system.Collection
+		args  []expr.Expression
+	}{
+		{
+			name:  "wrong number of arguments",
+			input: system.Collection{date},
+			args:  []expr.Expression{exprtest.Return(date)},
+		},
+		{
+			name:  "mismatched types",
+			input: system.Collection{date},
+			args:  []expr.Expression{exprtest.Return(system.MustParseTime("10:00")), exprtest.Return(system.String("hours"))},
+		},
+		{
+			name:  "input is not a date",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(2)), exprtest.Return(system.String("days"))},
+		},
+		{
+			name:  "unit is not a calendar duration",
+			input: system.Collection{date},
+			args:  []expr.Expression{exprtest.Return(date), exprtest.Return(system.String("mg"))},
+		},
+		{
+			name:  "value raises error",
+			input: system.Collection{date},
+			args:  []expr.Expression{exprtest.Error(errors.New("some error")), exprtest.Return(system.String("days"))},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Duration(&expr.Context{}, tc.input, tc.args...); err == nil {
+				t.Errorf("impl.Duration() didn't return error when expected")
+			}
+			if _, err := impl.Difference(&expr.Context{}, tc.input, tc.args...); err == nil {
+				t.Errorf("impl.Difference() didn't return error when expected")
+			}
+		})
+	}
+}
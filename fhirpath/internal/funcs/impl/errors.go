@@ -6,4 +6,10 @@ import "errors"
 var (
 	ErrWrongArity        = errors.New("incorrect function arity")
 	ErrInvalidReturnType = errors.New("invalid return type")
+	ErrNoTerminology     = errors.New("no terminology provider")
+	ErrNoServer          = errors.New("no FHIR server")
+	ErrUnsupportedMethod = errors.New("unsupported method")
+	ErrNoValidator       = errors.New("no profile validator")
+	ErrNoStructures      = errors.New("no structure definitions")
+	ErrUnknownModifier   = errors.New("unknown modifier extension")
 )
@@ -4,7 +4,10 @@ import (
 	"fmt"
 
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/reflection"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/protofields"
 )
 
 // Where evaluates the expression args[0] on each input item, collects the items that cause
@@ -15,8 +18,8 @@ func Where(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	}
 	e := args[0]
 	result := system.Collection{}
-	for _, item := range input {
-		output, err := e.Evaluate(ctx, system.Collection{item})
+	for i, item := range input {
+		output, err := e.Evaluate(ctx.WithIndex(i), system.Collection{item})
 		if err != nil {
 			return nil, err
 		}
@@ -33,3 +36,31 @@ func Where(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	}
 	return result, nil
 }
+
+// OfType returns the items in the input collection that are of the type given
+// by args[0], or of a subtype of it. Polymorphic choice values are unwrapped
+// into their concrete type.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#oftypetype-type-specifier-collection
+func OfType(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	want, err := typeSpecifierArg(ctx, input, args...)
+	if err != nil {
+		return nil, err
+	}
+	result := system.Collection{}
+	for _, item := range input {
+		got, err := reflection.TypeOf(item)
+		if err != nil {
+			return nil, err
+		}
+		if !got.Is(want) {
+			continue
+		}
+		if message, ok := item.(fhir.Base); ok {
+			if oneOf := protofields.UnwrapOneofField(message, "choice"); oneOf != nil {
+				item = oneOf
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
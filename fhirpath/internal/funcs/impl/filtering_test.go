@@ -5,10 +5,12 @@ import (
 	"testing"
 
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/reflection"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
 	"github.com/verily-src/fhirpath-go/internal/fhir"
 	"github.com/verily-src/fhirpath-go/internal/slices"
@@ -112,3 +114,95 @@ func TestWhere_RaisesError(t *testing.T) {
 		})
 	}
 }
+
+func TestOfType_Evaluates(t *testing.T) {
+	quantity := reflection.MustCreateTypeSpecifier("FHIR", "Quantity")
+	systemString := reflection.MustCreateTypeSpecifier("System", "String")
+	age := &dtpb.Quantity{Value: fhir.Decimal(42), Unit: fhir.String("a")}
+	observationValue := &opb.Observation_ValueX{
+		Choice: &opb.Observation_ValueX_Quantity{Quantity: age},
+	}
+
+	testCases := []struct {
+		name            string
+		inputCollection system.Collection
+		inputArgs       []expr.Expression
+		wantCollection  system.Collection
+	}{
+		{
+			name:            "filters items of the given type",
+			inputCollection: system.Collection{age, fhir.String("a"), age},
+			inputArgs:       []expr.Expression{exprtest.Return(quantity)},
+			wantCollection:  system.Collection{age, age},
+		},
+		{
+			name:            "filters system types",
+			inputCollection: system.Collection{system.String("a"), system.Integer(1), fhir.String("b")},
+			inputArgs:       []expr.Expression{exprtest.Return(systemString)},
+			wantCollection:  system.Collection{system.String("a")},
+		},
+		{
+			name:            "unwraps choice types",
+			inputCollection: system.Collection{observationValue},
+			inputArgs:       []expr.Expression{exprtest.Return(quantity)},
+			wantCollection:  system.Collection{age},
+		},
+		{
+			name:            "returns empty for empty input",
+			inputCollection: system.Collection{},
+			inputArgs:       []expr.Expression{exprtest.Return(quantity)},
+			wantCollection:  system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.OfType(&expr.Context{}, tc.inputCollection, tc.inputArgs...)
+			if err != nil {
+				t.Fatalf("OfType function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got, protocmp.Transform()); diff != "" {
+				t.Errorf("OfType function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOfType_RaisesError(t *testing.T) {
+	quantity := reflection.MustCreateTypeSpecifier("FHIR", "Quantity")
+
+	testCases := []struct {
+		name            string
+		inputArgs       []expr.Expression
+		inputCollection system.Collection
+	}{
+		{
+			name:            "no arguments",
+			inputArgs:       []expr.Expression{},
+			inputCollection: system.Collection{system.String("a")},
+		},
+		{
+			name:            "argument is not a type specifier",
+			inputArgs:       []expr.Expression{exprtest.Return(system.String("Quantity"))},
+			inputCollection: system.Collection{system.String("a")},
+		},
+		{
+			name:            "argument expression raises error",
+			inputArgs:       []expr.Expression{exprtest.Error(errors.New("some error"))},
+			inputCollection: system.Collection{system.String("a")},
+		},
+		{
+			name:            "input item has no type",
+			inputArgs:       []expr.Expression{exprtest.Return(quantity)},
+			inputCollection: system.Collection{1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.OfType(&expr.Context{}, tc.inputCollection, tc.inputArgs...); err == nil {
+				t.Fatalf("evaluating OfType function didn't return error when expected")
+			}
+		})
+	}
+}
@@ -365,3 +365,151 @@ func powInt32(base, exp int32) int32 {
 	}
 	return result
 }
+
+// Sum returns the sum of the numbers in the input collection. Integers are
+// promoted to Decimals when summed with Decimals, and Quantities must have
+// matching units. Returns empty if the input is empty.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#sum--integer--long--decimal--quantity
+func Sum(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if input.IsEmpty() {
+		return system.Collection{}, nil
+	}
+	total, err := sumValues(input)
+	if err != nil {
+		return nil, err
+	}
+	return system.Collection{total}, nil
+}
+
+// Min returns the smallest value in the input collection, which may contain
+// numbers, Quantities, Strings, Dates, DateTimes or Times. Returns empty if
+// the input is empty.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#min--integer--long--decimal--quantity--date--datetime--time--string
+func Min(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return extremum(input, false, args...)
+}
+
+// Max returns the largest value in the input collection, which may contain
+// numbers, Quantities, Strings, Dates, DateTimes or Times. Returns empty if
+// the input is empty.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#max--integer--long--decimal--quantity--date--datetime--time--string
+func Max(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return extremum(input, true, args...)
+}
+
+// Avg returns the average of the numbers in the input collection as a Decimal,
+// or as a Quantity if the input contains Quantities. Returns empty if the input
+// is empty.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#avg--decimal--quantity
+func Avg(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if input.IsEmpty() {
+		return system.Collection{}, nil
+	}
+	total, err := sumValues(input)
+	if err != nil {
+		return nil, err
+	}
+	count := system.Decimal(decimal.NewFromInt(int64(len(input))))
+	switch v := total.(type) {
+	case system.Integer:
+		return system.Collection{system.Normalize(v, count).(system.Decimal).Div(count)}, nil
+	case system.Decimal:
+		return system.Collection{v.Div(count)}, nil
+	case system.Quantity:
+		return system.Collection{v.Div(count)}, nil
+	}
+	return nil, fmt.Errorf("%w: cannot average %T", ErrInvalidInput, total)
+}
+
+// sumValues adds all of the numbers in the non-empty input collection.
+func sumValues(input system.Collection) (system.Any, error) {
+	var total system.Any
+	for _, item := range input {
+		value, err := system.From(item)
+		if err != nil {
+			return nil, err
+		}
+		switch value.(type) {
+		case system.Integer, system.Decimal, system.Quantity:
+		default:
+			return nil, fmt.Errorf("%w: cannot sum %T", ErrInvalidInput, value)
+		}
+		if total == nil {
+			total = value
+			continue
+		}
+		if total, err = addValues(total, value); err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+// addValues returns lhs + rhs, after implicitly converting them to the same
+// type.
+func addValues(lhs, rhs system.Any) (system.Any, error) {
+	lhs = system.Normalize(lhs, rhs)
+	rhs = system.Normalize(rhs, lhs)
+	switch l := lhs.(type) {
+	case system.Integer:
+		if r, ok := rhs.(system.Integer); ok {
+			return l.Add(r)
+		}
+	case system.Decimal:
+		if r, ok := rhs.(system.Decimal); ok {
+			return l.Add(r), nil
+		}
+	case system.Quantity:
+		if r, ok := rhs.(system.Quantity); ok {
+			return l.Add(r)
+		}
+	}
+	return nil, fmt.Errorf("%w: cannot sum %T and %T", ErrInvalidInput, lhs, rhs)
+}
+
+// extremum returns the largest value in the input if largest is true, or the
+// smallest value otherwise.
+func extremum(input system.Collection, largest bool, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if input.IsEmpty() {
+		return system.Collection{}, nil
+	}
+	var result system.Any
+	for i, item := range input {
+		value, err := system.From(item)
+		if err != nil {
+			return nil, err
+		}
+		switch value.(type) {
+		case system.Integer, system.Decimal, system.Quantity, system.String, system.Date, system.DateTime, system.Time:
+		default:
+			return nil, fmt.Errorf("%w: cannot compare %T", ErrInvalidInput, value)
+		}
+		if i == 0 {
+			result = value
+			continue
+		}
+		lhs, rhs := system.Normalize(value, result), system.Normalize(result, value)
+		if largest {
+			lhs, rhs = rhs, lhs
+		}
+		less, err := lhs.Less(rhs)
+		if err != nil {
+			return nil, err
+		}
+		if less {
+			result = system.Normalize(value, result)
+		} else {
+			result = system.Normalize(result, value)
+		}
+	}
+	return system.Collection{result}, nil
+}
@@ -1057,3 +1057,210 @@ func TestTruncate(t *testing.T) {
 		})
 	}
 }
+
+func TestSum(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:    "returns an empty collection if input is empty",
+			input:   system.Collection{},
+			want:    system.Collection{},
+			wantErr: false,
+		},
+		{
+			name:    "sums Integers",
+			input:   system.Collection{system.Integer(1), system.Integer(2), fhir.Integer(3)},
+			want:    system.Collection{system.Integer(6)},
+			wantErr: false,
+		},
+		{
+			name:    "promotes Integers to Decimals",
+			input:   system.Collection{system.Integer(1), system.MustParseDecimal("2.5")},
+			want:    system.Collection{system.MustParseDecimal("3.5")},
+			wantErr: false,
+		},
+		{
+			name:    "sums Quantities",
+			input:   system.Collection{system.MustParseQuantity("1.5", "kg"), system.MustParseQuantity("2", "kg")},
+			want:    system.Collection{system.MustParseQuantity("3.5", "kg")},
+			wantErr: false,
+		},
+		{
+			name:    "errors on mismatched Quantity units",
+			input:   system.Collection{system.MustParseQuantity("1.5", "kg"), system.MustParseQuantity("2", "cm")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "errors on Strings",
+			input:   system.Collection{system.String("1"), system.String("2")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "errors on Integer overflow",
+			input:   system.Collection{system.Integer(2147483647), system.Integer(1)},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "errors if args length is more than 0",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Return(system.Integer(1))},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Sum(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Sum() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Sum() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		wantMin system.Collection
+		wantMax system.Collection
+		wantErr bool
+	}{
+		{
+			name:    "returns an empty collection if input is empty",
+			input:   system.Collection{},
+			wantMin: system.Collection{},
+			wantMax: system.Collection{},
+		},
+		{
+			name:    "Integers",
+			input:   system.Collection{system.Integer(2), system.Integer(1), fhir.Integer(3)},
+			wantMin: system.Collection{system.Integer(1)},
+			wantMax: system.Collection{system.Integer(3)},
+		},
+		{
+			name:    "promotes Integers to Decimals",
+			input:   system.Collection{system.Integer(2), system.MustParseDecimal("1.5")},
+			wantMin: system.Collection{system.MustParseDecimal("1.5")},
+			wantMax: system.Collection{system.MustParseDecimal("2")},
+		},
+		{
+			name:    "Strings",
+			input:   system.Collection{system.String("b"), fhir.String("a"), system.String("c")},
+			wantMin: system.Collection{system.String("a")},
+			wantMax: system.Collection{system.String("c")},
+		},
+		{
+			name:    "Dates",
+			input:   system.Collection{system.MustParseDate("2020-03-01"), fhir.MustParseDate("2019-12-31")},
+			wantMin: system.Collection{system.MustParseDate("2019-12-31")},
+			wantMax: system.Collection{system.MustParseDate("2020-03-01")},
+		},
+		{
+			name:    "Quantities",
+			input:   system.Collection{system.MustParseQuantity("2", "kg"), system.MustParseQuantity("1", "kg")},
+			wantMin: system.Collection{system.MustParseQuantity("1", "kg")},
+			wantMax: system.Collection{system.MustParseQuantity("2", "kg")},
+		},
+		{
+			name:    "errors on mismatched Quantity units",
+			input:   system.Collection{system.MustParseQuantity("2", "kg"), system.MustParseQuantity("1", "cm")},
+			wantErr: true,
+		},
+		{
+			name:    "errors on mismatched types",
+			input:   system.Collection{system.Integer(1), system.String("a")},
+			wantErr: true,
+		},
+		{
+			name:    "errors on Booleans",
+			input:   system.Collection{system.Boolean(true)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotMin, err := impl.Min(&expr.Context{}, tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Min() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantMin, gotMin, protocmp.Transform()); diff != "" {
+				t.Errorf("Min() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+			gotMax, err := impl.Max(&expr.Context{}, tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Max() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantMax, gotMax, protocmp.Transform()); diff != "" {
+				t.Errorf("Max() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAvg(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:    "returns an empty collection if input is empty",
+			input:   system.Collection{},
+			want:    system.Collection{},
+			wantErr: false,
+		},
+		{
+			name:    "averages Integers as a Decimal",
+			input:   system.Collection{system.Integer(1), system.Integer(2)},
+			want:    system.Collection{system.MustParseDecimal("1.5")},
+			wantErr: false,
+		},
+		{
+			name:    "averages Decimals",
+			input:   system.Collection{system.MustParseDecimal("1.5"), system.MustParseDecimal("2.5"), system.Integer(5)},
+			want:    system.Collection{system.MustParseDecimal("3")},
+			wantErr: false,
+		},
+		{
+			name:    "averages Quantities",
+			input:   system.Collection{system.MustParseQuantity("1", "kg"), system.MustParseQuantity("2", "kg")},
+			want:    system.Collection{system.MustParseQuantity("1.5", "kg")},
+			wantErr: false,
+		},
+		{
+			name:    "errors on mismatched Quantity units",
+			input:   system.Collection{system.MustParseQuantity("1", "kg"), system.MustParseQuantity("2", "cm")},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Avg(&expr.Context{}, tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Avg() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Avg() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package impl
+
+import (
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	"github.com/iancoleman/strcase"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/containedresource"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/protofields"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// elementPath returns the path of the element within the %context resources,
+// in the form of the paths of element definitions, except that choice types
+// are named after their type, such as "Observation.valueQuantity". Contained
+// resources start a new path from their resource type. False is returned if
+// the element isn't within the %context resources.
+func elementPath(ctx *expr.Context, element fhir.Base) (string, bool) {
+	roots, _ := ctx.ExternalConstants["context"].(system.Collection)
+	for _, root := range roots {
+		message, ok := root.(fhir.Base)
+		if !ok {
+			continue
+		}
+		if path, ok := locate(message, element, typeName(message)); ok {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// locate searches the message and its fields for the target message, which
+// is identified by pointer, returning its path relative to the given path of
+// the message.
+func locate(message, target proto.Message, path string) (string, bool) {
+	if message == target {
+		return path, true
+	}
+	var result string
+	found := false
+	message.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		name := path + "." + strcase.ToLowerCamel(string(fd.Name()))
+		visit := func(child proto.Message) {
+			if contained, ok := child.(*bcrpb.ContainedResource); ok {
+				if res := containedresource.Unwrap(contained); res != nil {
+					result, found = locate(res, target, typeName(res))
+				}
+				return
+			}
+			if oneOf := protofields.UnwrapOneofField(child, "choice"); oneOf != nil {
+				result, found = locate(oneOf, target, name+typeName(oneOf))
+				return
+			}
+			result, found = locate(child, target, name)
+		}
+		if fd.IsList() {
+			list := value.List()
+			for i := 0; i < list.Len() && !found; i++ {
+				visit(list.Get(i).Message().Interface())
+			}
+		} else {
+			visit(value.Message().Interface())
+		}
+		return !found
+	})
+	return result, found
+}
+
+// typeName returns the name of the FHIR type of the message, capitalized as
+// in the names of choice type elements.
+func typeName(message proto.Message) string {
+	return string(message.ProtoReflect().Descriptor().Name())
+}
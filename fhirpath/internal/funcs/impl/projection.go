@@ -18,8 +18,8 @@ func Select(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	e := args[0]
 	result := system.Collection{}
 	var fieldErrs []error
-	for _, item := range input {
-		output, err := e.Evaluate(ctx, system.Collection{item})
+	for i, item := range input {
+		output, err := e.Evaluate(ctx.WithIndex(i), system.Collection{item})
 		// If the error is ErrInvalidField, don't immediately raise it
 		if err != nil {
 			if errors.Is(err, expr.ErrInvalidField) {
@@ -36,3 +36,47 @@ func Select(ctx *expr.Context, input system.Collection, args ...expr.Expression)
 	}
 	return result, nil
 }
+
+// Repeat evaluates the projection expression args[0] on each input item, and
+// then repeatedly on each newly produced item, until no new items are found.
+// Items that are equal to an item already in the output, using the same
+// equality semantics as distinct(), are not projected again. This guarantees
+// termination on cyclic data.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#repeatprojection-expression-collection
+func Repeat(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	e := args[0]
+	result := system.Collection{}
+	queue := input
+	for first := true; len(queue) > 0; first = false {
+		var next system.Collection
+		var fieldErrs []error
+		for i, item := range queue {
+			output, err := e.Evaluate(ctx.WithIndex(i), system.Collection{item})
+			// Projected items may not define the projected field, so
+			// ErrInvalidField is not immediately raised.
+			if err != nil {
+				if errors.Is(err, expr.ErrInvalidField) {
+					fieldErrs = append(fieldErrs, err)
+					continue
+				}
+				return nil, err
+			}
+			for _, value := range output {
+				if result.Contains(value) {
+					continue
+				}
+				result = append(result, value)
+				next = append(next, value)
+			}
+		}
+		// Raise field errors if one was raised for each of the original inputs.
+		if first && len(fieldErrs) == len(queue) {
+			return nil, errors.Join(fieldErrs...)
+		}
+		queue = next
+	}
+	return result, nil
+}
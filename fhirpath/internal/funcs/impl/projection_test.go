@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
@@ -120,3 +121,95 @@ func TestSelect_RaisesError(t *testing.T) {
 		})
 	}
 }
+
+func TestRepeat_Evaluates(t *testing.T) {
+	leaf := &qpb.Questionnaire_Item{LinkId: fhir.String("1.1.1")}
+	child := &qpb.Questionnaire_Item{LinkId: fhir.String("1.1"), Item: []*qpb.Questionnaire_Item{leaf}}
+	root := &qpb.Questionnaire_Item{LinkId: fhir.String("1"), Item: []*qpb.Questionnaire_Item{child}}
+	sibling := &qpb.Questionnaire_Item{LinkId: fhir.String("2")}
+	questionnaire := &qpb.Questionnaire{Item: []*qpb.Questionnaire_Item{root, sibling}}
+
+	// cycle maps each integer n to (n + 1) mod 3.
+	cycle := &exprtest.MockExpression{
+		Eval: func(_ *expr.Context, input system.Collection) (system.Collection, error) {
+			n := input[0].(system.Integer)
+			return system.Collection{(n + 1) % 3}, nil
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		inputCollection system.Collection
+		inputArgs       []expr.Expression
+		wantCollection  system.Collection
+	}{
+		{
+			name:            "walks recursive structure",
+			inputCollection: system.Collection{questionnaire},
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "item"}},
+			wantCollection:  system.Collection{root, sibling, child, leaf},
+		},
+		{
+			name:            "terminates on cyclic data",
+			inputCollection: system.Collection{system.Integer(0)},
+			inputArgs:       []expr.Expression{cycle},
+			wantCollection:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(0)},
+		},
+		{
+			name:            "does not raise error if projected items do not have the field",
+			inputCollection: system.Collection{address[0]},
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "line"}},
+			wantCollection:  slices.MustConvert[any](address[0].GetLine()),
+		},
+		{
+			name:            "returns empty for empty input",
+			inputCollection: system.Collection{},
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "item"}},
+			wantCollection:  system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Repeat(&expr.Context{}, tc.inputCollection, tc.inputArgs...)
+			if err != nil {
+				t.Fatalf("Repeat function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Repeat function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepeat_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name            string
+		inputArgs       []expr.Expression
+		inputCollection system.Collection
+	}{
+		{
+			name:            "multiple arguments",
+			inputArgs:       []expr.Expression{exprtest.Return(1), exprtest.Return(1)},
+			inputCollection: slices.MustConvert[any](address),
+		},
+		{
+			name:            "argument expression raises error",
+			inputArgs:       []expr.Expression{exprtest.Error(errors.New("some error"))},
+			inputCollection: slices.MustConvert[any](address),
+		},
+		{
+			name:            "invalid field as argument expression",
+			inputArgs:       []expr.Expression{&expr.FieldExpression{FieldName: "invalid"}},
+			inputCollection: slices.MustConvert[any](address),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Repeat(&expr.Context{}, tc.inputCollection, tc.inputArgs...); err == nil {
+				t.Fatalf("evaluating Repeat function didn't return error when expected")
+			}
+		})
+	}
+}
@@ -2,10 +2,18 @@ package impl
 
 import (
 	"fmt"
+	"strings"
 
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/containedresource"
+	"github.com/verily-src/fhirpath-go/internal/element/reference"
 	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/protofields"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // Extension is syntactic sugar over `extension.where(url = ...)`, and is
@@ -40,3 +48,501 @@ func Extension(ctx *expr.Context, input system.Collection, args ...expr.Expressi
 	}
 	return result, nil
 }
+
+// Resolve returns the resources referred to by each Reference, canonical, uri,
+// url or string in the input collection. Fragment references, such as "#id",
+// are resolved to the resources contained in the resource being evaluated.
+// Other references are located by the Resolver of the evaluation context.
+// Items that are not references, and references that cannot be resolved, are
+// ignored.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func Resolve(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	result := system.Collection{}
+	for _, item := range input {
+		url, ok := referenceURL(item)
+		if !ok {
+			continue
+		}
+		var res fhir.Resource
+		var err error
+		if id, ok := strings.CutPrefix(url, "#"); ok {
+			res, err = containedResource(ctx, id)
+		} else if ctx.Resolver != nil {
+			res, err = ctx.Resolver.Resolve(url)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if res != nil {
+			result = append(result, res)
+		}
+	}
+	return result, nil
+}
+
+// containedResource returns the contained resource with the given id, from
+// the resources being evaluated. Those are %resource if it is defined, and
+// otherwise the input of the evaluation, of which the first that contains a
+// resource with the id is used. The empty id, of the fragment "#", refers to
+// the containing resource itself, if there is a single one. Returns nil if
+// there is no such resource.
+func containedResource(ctx *expr.Context, id string) (fhir.Resource, error) {
+	constant, ok := ctx.ExternalConstants["resource"]
+	if !ok {
+		constant = ctx.ExternalConstants["context"]
+	}
+	items, ok := constant.(system.Collection)
+	if !ok {
+		items = system.Collection{constant}
+	}
+	var containers []fhir.DomainResource
+	for _, item := range items {
+		if container, ok := item.(fhir.DomainResource); ok {
+			containers = append(containers, container)
+		}
+	}
+	if id == "" {
+		if len(containers) == 1 {
+			return containers[0], nil
+		}
+		return nil, nil
+	}
+	for _, container := range containers {
+		for _, anyMsg := range container.GetContained() {
+			cr := &bcrpb.ContainedResource{}
+			if err := anyMsg.UnmarshalTo(cr); err != nil {
+				return nil, fmt.Errorf("unpacking contained resource: %w", err)
+			}
+			if res := containedresource.Unwrap(cr); res != nil && res.GetId().GetValue() == id {
+				return res, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// referenceURL returns the URL that a resolve() input item refers to, and
+// whether the item is a literal reference at all.
+func referenceURL(item any) (string, bool) {
+	var url string
+	switch v := item.(type) {
+	case *dtpb.Reference:
+		lit, err := reference.LiteralInfoOf(v)
+		if err != nil {
+			return "", false
+		}
+		url = lit.URIString()
+	case *dtpb.Canonical:
+		url = v.GetValue()
+	case *dtpb.Uri:
+		url = v.GetValue()
+	case *dtpb.Url:
+		url = v.GetValue()
+	case system.String:
+		url = string(v)
+	}
+	return url, url != ""
+}
+
+// MemberOf returns true if the input code, Coding or CodeableConcept is a
+// member of the value set with the given canonical URL. A CodeableConcept is a
+// member if any of its codings are. Membership is determined by the
+// TerminologyProvider of the evaluation context.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func MemberOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	if ctx.Terminology == nil {
+		return nil, fmt.Errorf("%w: memberOf() requires a terminology provider", ErrNoTerminology)
+	}
+	if length := len(input); length == 0 {
+		return system.Collection{}, nil
+	} else if length > 1 {
+		return nil, fmt.Errorf("%w: received %v inputs", expr.ErrNotSingleton, length)
+	}
+	valueSet, ok, err := stringArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	codings, ok := codingsOf(input[0])
+	if !ok {
+		return system.Collection{}, nil
+	}
+	for _, coding := range codings {
+		member, err := ctx.Terminology.MemberOf(valueSet, coding)
+		if err != nil {
+			return nil, err
+		}
+		if member {
+			return system.Collection{system.Boolean(true)}, nil
+		}
+	}
+	return system.Collection{system.Boolean(false)}, nil
+}
+
+// codingsOf returns the codings of a Coding or CodeableConcept, or a coding
+// without a system for a code or string. Returns false for any other item.
+func codingsOf(item any) ([]*dtpb.Coding, bool) {
+	switch v := item.(type) {
+	case *dtpb.Coding:
+		return []*dtpb.Coding{v}, true
+	case *dtpb.CodeableConcept:
+		return v.GetCoding(), true
+	}
+	value, err := system.From(item)
+	if err != nil {
+		return nil, false
+	}
+	code, ok := value.(system.String)
+	if !ok {
+		return nil, false
+	}
+	return []*dtpb.Coding{{Code: fhir.Code(string(code))}}, true
+}
+
+// Subsumes returns true if the input Coding or CodeableConcept is equivalent
+// to, or an ancestor of, the given Coding or CodeableConcept in the hierarchy
+// of their code system. Returns false if the codes are known to be unrelated,
+// and empty if the relationship is unknown or either collection is not a
+// singleton. Relationships are determined by the TerminologyProvider of the
+// evaluation context.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func Subsumes(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return subsumption(ctx, input, false, args...)
+}
+
+// SubsumedBy returns true if the input Coding or CodeableConcept is equivalent
+// to, or a descendant of, the given Coding or CodeableConcept. It is the
+// inverse of Subsumes.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func SubsumedBy(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return subsumption(ctx, input, true, args...)
+}
+
+// subsumption implements Subsumes, or SubsumedBy when inverse is true. Codings
+// of concepts are compared pairwise; the result is true if any pair is
+// related, and false only if some pair is known to be unrelated.
+func subsumption(ctx *expr.Context, input system.Collection, inverse bool, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	if ctx.Terminology == nil {
+		return nil, fmt.Errorf("%w: subsumption requires a terminology provider", ErrNoTerminology)
+	}
+	if len(input) != 1 {
+		return system.Collection{}, nil
+	}
+	arg, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(arg) != 1 {
+		return system.Collection{}, nil
+	}
+	sources, ok := codingsOf(input[0])
+	if !ok {
+		return system.Collection{}, nil
+	}
+	targets, ok := codingsOf(arg[0])
+	if !ok {
+		return system.Collection{}, nil
+	}
+	known := false
+	for _, source := range sources {
+		for _, target := range targets {
+			ancestor, descendant := source, target
+			if inverse {
+				ancestor, descendant = target, source
+			}
+			codeSystem := ancestor.GetSystem().GetValue()
+			if codeSystem == "" || codeSystem != descendant.GetSystem().GetValue() {
+				continue
+			}
+			outcome, err := ctx.Terminology.Subsumes(codeSystem, ancestor.GetCode().GetValue(), descendant.GetCode().GetValue())
+			if err != nil {
+				return nil, err
+			}
+			switch outcome {
+			case expr.SubsumptionEquivalent, expr.SubsumptionSubsumes:
+				return system.Collection{system.Boolean(true)}, nil
+			case expr.SubsumptionSubsumedBy, expr.SubsumptionNotSubsumed:
+				known = true
+			}
+		}
+	}
+	if !known {
+		return system.Collection{}, nil
+	}
+	return system.Collection{system.Boolean(false)}, nil
+}
+
+// ConformsTo returns true if the single input element conforms to the profile
+// with the canonical URL given by args[0], according to the ProfileValidator
+// of the evaluation context. An element conforms if its validation raises no
+// errors. Returns empty if the profile is unknown.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func ConformsTo(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	if ctx.Validator == nil {
+		return nil, fmt.Errorf("%w: conformsTo() requires a profile validator", ErrNoValidator)
+	}
+	if length := len(input); length == 0 {
+		return system.Collection{}, nil
+	} else if length > 1 {
+		return nil, fmt.Errorf("%w: received %v inputs", expr.ErrNotSingleton, length)
+	}
+	profile, ok, err := stringArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	element, ok := input[0].(fhir.Base)
+	if !ok {
+		return system.Collection{system.Boolean(false)}, nil
+	}
+	outcome, err := ctx.Validator.Validate(element, profile)
+	if err != nil {
+		return nil, err
+	}
+	if outcome == nil {
+		return system.Collection{}, nil
+	}
+	for _, issue := range outcome.GetIssue() {
+		switch issue.GetSeverity().GetValue() {
+		case cpb.IssueSeverityCode_ERROR, cpb.IssueSeverityCode_FATAL:
+			return system.Collection{system.Boolean(false)}, nil
+		}
+	}
+	return system.Collection{system.Boolean(true)}, nil
+}
+
+// primitiveHasNoValue is the URL of the extension that google/fhir uses to
+// mark primitives that have extensions, but no value.
+const primitiveHasNoValue = "https://g.co/fhir/StructureDefinition/primitiveHasNoValue"
+
+// HasValue returns true if the input collection contains a single FHIR
+// primitive which has a value, as opposed to only having extensions. Returns
+// false otherwise.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func HasValue(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if len(input) != 1 {
+		return system.Collection{system.Boolean(false)}, nil
+	}
+	_, ok := primitiveValue(input[0])
+	return system.Collection{system.Boolean(ok)}, nil
+}
+
+// GetValue returns the System value of the FHIR primitive in the input
+// collection, if it contains a single FHIR primitive which has a value.
+// Returns empty otherwise.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func GetValue(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if len(input) != 1 {
+		return system.Collection{}, nil
+	}
+	value, ok := primitiveValue(input[0])
+	if !ok {
+		return system.Collection{}, nil
+	}
+	return system.Collection{value}, nil
+}
+
+// primitiveValue returns the System value of a FHIR primitive, or false if
+// the item isn't a FHIR primitive or has no value. A primitive has no value
+// if it is marked with the primitiveHasNoValue extension, or if its value is
+// the zero value of a field that FHIR doesn't allow to be empty, such as the
+// value of a string, or the precision of a date.
+func primitiveValue(item any) (system.Any, bool) {
+	message, ok := item.(fhir.Base)
+	if !ok {
+		return nil, false
+	}
+	if oneOf := protofields.UnwrapOneofField(message, "choice"); oneOf != nil {
+		message = oneOf
+	}
+	if extendable, ok := message.(fhir.Extendable); ok {
+		for _, ext := range extendable.GetExtension() {
+			if ext.GetUrl().GetValue() == primitiveHasNoValue && ext.GetValue().GetBoolean().GetValue() {
+				return nil, false
+			}
+		}
+	}
+	switch v := message.(type) {
+	case *dtpb.Date:
+		ok = v.GetPrecision() != dtpb.Date_PRECISION_UNSPECIFIED
+	case *dtpb.DateTime:
+		ok = v.GetPrecision() != dtpb.DateTime_PRECISION_UNSPECIFIED
+	case *dtpb.Time:
+		ok = v.GetPrecision() != dtpb.Time_PRECISION_UNSPECIFIED
+	case *dtpb.Instant:
+		ok = v.GetPrecision() != dtpb.Instant_PRECISION_UNSPECIFIED
+	case *dtpb.Base64Binary:
+		ok = len(v.GetValue()) != 0
+	case *dtpb.Xhtml:
+		return system.String(v.GetValue()), v.GetValue() != ""
+	case *dtpb.Quantity:
+		ok = false
+	default:
+		if protofields.IsCodeField(message) {
+			reflect := message.ProtoReflect()
+			field := reflect.Descriptor().Fields().ByName("value")
+			ok = field.Kind() != protoreflect.EnumKind || reflect.Get(field).Enum() != 0
+		} else {
+			ok = system.IsPrimitive(message)
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	value, err := system.From(message)
+	if err != nil {
+		return nil, false
+	}
+	if str, ok := value.(system.String); ok && str == "" {
+		return nil, false
+	}
+	return value, true
+}
+
+// Slice returns the elements of the input collection that are members of the
+// slice named args[1] of the StructureDefinition with the canonical URL given
+// by args[0], according to the StructureDefinitions of the evaluation context.
+// Returns empty if the structure or the slice is unknown.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func Slice(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2", ErrWrongArity, len(args))
+	}
+	if ctx.Structures == nil {
+		return nil, fmt.Errorf("%w: slice() requires structure definitions", ErrNoStructures)
+	}
+	structure, ok, err := stringArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	name, ok, err := stringArg(ctx, input, args[1])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	result := system.Collection{}
+	for _, item := range input {
+		element, ok := item.(fhir.Base)
+		if !ok {
+			continue
+		}
+		inSlice, err := ctx.Structures.InSlice(element, structure, name)
+		if err != nil {
+			return nil, err
+		}
+		if inSlice {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// CheckModifiers returns the input collection if none of its elements has
+// modifier extensions other than those with the URLs given by args[0], which
+// are strings of comma-separated URLs. Raises an error otherwise.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func CheckModifiers(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0 or 1", ErrWrongArity, len(args))
+	}
+	known := map[string]bool{}
+	if len(args) == 1 {
+		modifiers, err := args[0].Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range modifiers {
+			value, err := system.From(item)
+			if err != nil {
+				return nil, err
+			}
+			str, ok := value.(system.String)
+			if !ok {
+				return nil, fmt.Errorf("%w: modifiers must be strings, received %T", ErrInvalidInput, item)
+			}
+			for _, url := range strings.Split(string(str), ",") {
+				known[strings.TrimSpace(url)] = true
+			}
+		}
+	}
+	for _, item := range input {
+		message, ok := item.(fhir.Base)
+		if !ok {
+			continue
+		}
+		reflect := message.ProtoReflect()
+		field := reflect.Descriptor().Fields().ByName("modifier_extension")
+		if field == nil || !field.IsList() {
+			continue
+		}
+		extensions := reflect.Get(field).List()
+		for i := 0; i < extensions.Len(); i++ {
+			ext, ok := extensions.Get(i).Message().Interface().(*dtpb.Extension)
+			if !ok {
+				continue
+			}
+			if url := ext.GetUrl().GetValue(); !known[url] {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownModifier, url)
+			}
+		}
+	}
+	return input, nil
+}
+
+// ElementDefinition returns the definitions of the elements of the input
+// collection, according to the StructureDefinitions of the evaluation context.
+// Elements are located within the %context resources to determine their
+// paths, and are skipped if they can't be located or their path is unknown.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions
+func ElementDefinition(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if ctx.Structures == nil {
+		return nil, fmt.Errorf("%w: elementDefinition() requires structure definitions", ErrNoStructures)
+	}
+	result := system.Collection{}
+	for _, item := range input {
+		element, ok := item.(fhir.Base)
+		if !ok {
+			continue
+		}
+		path, ok := elementPath(ctx, element)
+		if !ok {
+			continue
+		}
+		definition, err := ctx.Structures.ElementDefinition(path)
+		if err != nil {
+			return nil, err
+		}
+		if definition != nil {
+			result = append(result, definition)
+		}
+	}
+	return result, nil
+}
@@ -4,18 +4,27 @@ import (
 	"errors"
 	"testing"
 
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	orgpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/organization_go_proto"
 	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/resolver"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/containedresource"
 	"github.com/verily-src/fhirpath-go/internal/element/extension"
+	"github.com/verily-src/fhirpath-go/internal/element/reference"
 	"github.com/verily-src/fhirpath-go/internal/fhir"
 	"github.com/verily-src/fhirpath-go/internal/fhirtest"
+	"github.com/verily-src/fhirpath-go/internal/resource"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 func TestExtension_ValidInput(t *testing.T) {
@@ -118,3 +127,1102 @@ func TestExtension_InvalidInput_RaisesError(t *testing.T) {
 		})
 	}
 }
+
+func TestResolve_Evaluates(t *testing.T) {
+	patient := &ppb.Patient{Id: fhir.ID("123")}
+	ctx := &expr.Context{Resolver: resolver.Resources(patient)}
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "typed reference",
+			input: system.Collection{fhirtest.NewReferenceTyped(t, resource.Patient, "123")},
+			want:  system.Collection{patient},
+		},
+		{
+			name:  "weak reference",
+			input: system.Collection{reference.Weak(resource.Patient, "Patient/123")},
+			want:  system.Collection{patient},
+		},
+		{
+			name:  "uri and string",
+			input: system.Collection{fhir.URI("Patient/123"), system.String("Patient/123")},
+			want:  system.Collection{patient, patient},
+		},
+		{
+			name:  "unresolved reference is ignored",
+			input: system.Collection{fhirtest.NewReferenceTyped(t, resource.Patient, "456")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "logical reference is ignored",
+			input: system.Collection{reference.Logical(resource.Patient, "system", "value")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "non-reference is ignored",
+			input: system.Collection{system.Integer(1)},
+			want:  system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Resolve(ctx, tc.input)
+			if err != nil {
+				t.Fatalf("Resolve function returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Resolve function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolve_ContainedResources(t *testing.T) {
+	patient := &ppb.Patient{Id: fhir.ID("p1")}
+	contained, err := anypb.New(containedresource.Wrap(patient))
+	if err != nil {
+		t.Fatalf("anypb.New: %v", err)
+	}
+	container := &orgpb.Organization{Id: fhir.ID("org"), Contained: []*anypb.Any{contained}}
+	fragment := &dtpb.Reference{Reference: &dtpb.Reference_Fragment{Fragment: fhir.String("p1")}}
+	testCases := []struct {
+		name      string
+		constants map[string]any
+		input     system.Collection
+		want      system.Collection
+	}{
+		{
+			name:      "fragment reference",
+			constants: map[string]any{"context": system.Collection{container}},
+			input:     system.Collection{fragment},
+			want:      system.Collection{patient},
+		},
+		{
+			name:      "fragment string",
+			constants: map[string]any{"context": system.Collection{container}},
+			input:     system.Collection{system.String("#p1")},
+			want:      system.Collection{patient},
+		},
+		{
+			name:      "containing resource",
+			constants: map[string]any{"context": system.Collection{container}},
+			input:     system.Collection{system.String("#")},
+			want:      system.Collection{container},
+		},
+		{
+			name: "contained in %resource",
+			constants: map[string]any{
+				"context":  system.Collection{&ppb.Patient{}},
+				"resource": container,
+			},
+			input: system.Collection{fragment},
+			want:  system.Collection{patient},
+		},
+		{
+			name:      "unknown fragment",
+			constants: map[string]any{"context": system.Collection{container}},
+			input:     system.Collection{system.String("#p2")},
+			want:      system.Collection{},
+		},
+		{
+			name:      "ambiguous containing resource",
+			constants: map[string]any{"context": system.Collection{container, container}},
+			input:     system.Collection{system.String("#")},
+			want:      system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &expr.Context{ExternalConstants: tc.constants}
+
+			got, err := impl.Resolve(ctx, tc.input)
+
+			if err != nil {
+				t.Fatalf("Resolve function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Resolve function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolve_RaisesError(t *testing.T) {
+	testErr := errors.New("test error")
+	errResolver := resolver.Func(func(string) (fhir.Resource, error) {
+		return nil, testErr
+	})
+	testCases := []struct {
+		name    string
+		ctx     *expr.Context
+		input   system.Collection
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "too many arguments",
+			ctx:     &expr.Context{},
+			input:   system.Collection{system.String("Patient/123")},
+			args:    []expr.Expression{exprtest.Return(system.String(""))},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "resolver errors",
+			ctx:     &expr.Context{Resolver: errResolver},
+			input:   system.Collection{system.String("Patient/123")},
+			wantErr: testErr,
+		},
+		{
+			name: "bad contained resource",
+			ctx: &expr.Context{ExternalConstants: map[string]any{
+				"context": system.Collection{&orgpb.Organization{
+					Contained: []*anypb.Any{{TypeUrl: "type.googleapis.com/unknown"}},
+				}},
+			}},
+			input:   system.Collection{system.String("#p1")},
+			wantErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := impl.Resolve(tc.ctx, tc.input, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("Resolve(%v): got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
+
+// fakeTerminology knows the codes of value sets, keyed by URL. It knows the
+// subsumption hierarchy of the "sys" code system, in which "a" is the parent of
+// "b", and "c" is unrelated to both.
+type fakeTerminology map[string][]string
+
+func (f fakeTerminology) MemberOf(valueSet string, coding *dtpb.Coding) (bool, error) {
+	for _, code := range f[valueSet] {
+		if code == coding.GetCode().GetValue() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f fakeTerminology) Subsumes(system, codeA, codeB string) (expr.SubsumptionOutcome, error) {
+	if system == "error" {
+		return "", errors.New("test error")
+	}
+	known := map[string]bool{"a": true, "b": true, "c": true}
+	switch {
+	case system != "sys" || !known[codeA] || !known[codeB]:
+		return "", nil
+	case codeA == codeB:
+		return expr.SubsumptionEquivalent, nil
+	case codeA == "a" && codeB == "b":
+		return expr.SubsumptionSubsumes, nil
+	case codeA == "b" && codeB == "a":
+		return expr.SubsumptionSubsumedBy, nil
+	default:
+		return expr.SubsumptionNotSubsumed, nil
+	}
+}
+
+func TestMemberOf_Evaluates(t *testing.T) {
+	ctx := &expr.Context{Terminology: fakeTerminology{"vs": {"female", "a"}}}
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "member code",
+			input: system.Collection{fhir.Code("a")},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "member code field",
+			input: system.Collection{&ppb.Patient_GenderCode{Value: cpb.AdministrativeGenderCode_FEMALE}},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "non-member coding",
+			input: system.Collection{fhir.Coding("system", "b")},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "codeable concept with a member coding",
+			input: system.Collection{fhir.CodeableConcept("", fhir.Coding("system", "b"), fhir.Coding("system", "a"))},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "non-code input",
+			input: system.Collection{system.Integer(1)},
+			want:  system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.MemberOf(ctx, tc.input, exprtest.Return(system.String("vs")))
+			if err != nil {
+				t.Fatalf("MemberOf function returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("MemberOf function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMemberOf_RaisesError(t *testing.T) {
+	testErr := errors.New("test error")
+	terminology := fakeTerminology{}
+	testCases := []struct {
+		name    string
+		ctx     *expr.Context
+		input   system.Collection
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "too few arguments",
+			ctx:     &expr.Context{Terminology: terminology},
+			input:   system.Collection{fhir.Code("a")},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "no terminology provider",
+			ctx:     &expr.Context{},
+			input:   system.Collection{fhir.Code("a")},
+			args:    []expr.Expression{exprtest.Return(system.String("vs"))},
+			wantErr: impl.ErrNoTerminology,
+		},
+		{
+			name:    "multiple inputs",
+			ctx:     &expr.Context{Terminology: terminology},
+			input:   system.Collection{fhir.Code("a"), fhir.Code("b")},
+			args:    []expr.Expression{exprtest.Return(system.String("vs"))},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name:    "argument errors",
+			ctx:     &expr.Context{Terminology: terminology},
+			input:   system.Collection{fhir.Code("a")},
+			args:    []expr.Expression{exprtest.Error(testErr)},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := impl.MemberOf(tc.ctx, tc.input, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("MemberOf(%v): got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
+
+func TestSubsumes_Evaluates(t *testing.T) {
+	ctx := &expr.Context{Terminology: fakeTerminology{}}
+	testCases := []struct {
+		name           string
+		input          system.Collection
+		arg            system.Collection
+		wantSubsumes   system.Collection
+		wantSubsumedBy system.Collection
+	}{
+		{
+			name:           "equivalent codings",
+			input:          system.Collection{fhir.Coding("sys", "a")},
+			arg:            system.Collection{fhir.Coding("sys", "a")},
+			wantSubsumes:   system.Collection{system.Boolean(true)},
+			wantSubsumedBy: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:           "ancestor coding",
+			input:          system.Collection{fhir.Coding("sys", "a")},
+			arg:            system.Collection{fhir.Coding("sys", "b")},
+			wantSubsumes:   system.Collection{system.Boolean(true)},
+			wantSubsumedBy: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:           "unrelated codings",
+			input:          system.Collection{fhir.Coding("sys", "a")},
+			arg:            system.Collection{fhir.Coding("sys", "c")},
+			wantSubsumes:   system.Collection{system.Boolean(false)},
+			wantSubsumedBy: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:           "unknown code",
+			input:          system.Collection{fhir.Coding("sys", "a")},
+			arg:            system.Collection{fhir.Coding("sys", "z")},
+			wantSubsumes:   system.Collection{},
+			wantSubsumedBy: system.Collection{},
+		},
+		{
+			name:           "different code systems",
+			input:          system.Collection{fhir.Coding("sys", "a")},
+			arg:            system.Collection{fhir.Coding("other", "b")},
+			wantSubsumes:   system.Collection{},
+			wantSubsumedBy: system.Collection{},
+		},
+		{
+			name:           "codeable concepts with a related pair",
+			input:          system.Collection{fhir.CodeableConcept("", fhir.Coding("sys", "c"), fhir.Coding("sys", "a"))},
+			arg:            system.Collection{fhir.CodeableConcept("", fhir.Coding("other", "x"), fhir.Coding("sys", "b"))},
+			wantSubsumes:   system.Collection{system.Boolean(true)},
+			wantSubsumedBy: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:           "empty input",
+			input:          system.Collection{},
+			arg:            system.Collection{fhir.Coding("sys", "a")},
+			wantSubsumes:   system.Collection{},
+			wantSubsumedBy: system.Collection{},
+		},
+		{
+			name:           "multiple arguments",
+			input:          system.Collection{fhir.Coding("sys", "a")},
+			arg:            system.Collection{fhir.Coding("sys", "a"), fhir.Coding("sys", "b")},
+			wantSubsumes:   system.Collection{},
+			wantSubsumedBy: system.Collection{},
+		},
+		{
+			name:           "non-coding input",
+			input:          system.Collection{system.Integer(1)},
+			arg:            system.Collection{fhir.Coding("sys", "a")},
+			wantSubsumes:   system.Collection{},
+			wantSubsumedBy: system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Subsumes(ctx, tc.input, exprtest.Return(tc.arg...))
+			if err != nil {
+				t.Fatalf("Subsumes function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantSubsumes, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Subsumes function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+
+			got, err = impl.SubsumedBy(ctx, tc.input, exprtest.Return(tc.arg...))
+			if err != nil {
+				t.Fatalf("SubsumedBy function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantSubsumedBy, got, protocmp.Transform()); diff != "" {
+				t.Errorf("SubsumedBy function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubsumes_RaisesError(t *testing.T) {
+	testErr := errors.New("test error")
+	terminology := fakeTerminology{}
+	testCases := []struct {
+		name    string
+		ctx     *expr.Context
+		input   system.Collection
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "too few arguments",
+			ctx:     &expr.Context{Terminology: terminology},
+			input:   system.Collection{fhir.Coding("sys", "a")},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "no terminology provider",
+			ctx:     &expr.Context{},
+			input:   system.Collection{fhir.Coding("sys", "a")},
+			args:    []expr.Expression{exprtest.Return(fhir.Coding("sys", "a"))},
+			wantErr: impl.ErrNoTerminology,
+		},
+		{
+			name:    "argument errors",
+			ctx:     &expr.Context{Terminology: terminology},
+			input:   system.Collection{fhir.Coding("sys", "a")},
+			args:    []expr.Expression{exprtest.Error(testErr)},
+			wantErr: testErr,
+		},
+		{
+			name:    "terminology errors",
+			ctx:     &expr.Context{Terminology: terminology},
+			input:   system.Collection{fhir.Coding("error", "a")},
+			args:    []expr.Expression{exprtest.Return(fhir.Coding("error", "b"))},
+			wantErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := impl.Subsumes(tc.ctx, tc.input, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("Subsumes(%v): got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
+
+// fakeValidator reports a single issue of the given severity for each known
+// profile. Validating against the "error" profile fails.
+type fakeValidator map[string]cpb.IssueSeverityCode_Value
+
+func (f fakeValidator) Validate(element fhir.Base, profile string) (*oopb.OperationOutcome, error) {
+	if profile == "error" {
+		return nil, errors.New("test error")
+	}
+	severity, ok := f[profile]
+	if !ok {
+		return nil, nil
+	}
+	return &oopb.OperationOutcome{
+		Issue: []*oopb.OperationOutcome_Issue{
+			{Severity: &oopb.OperationOutcome_Issue_SeverityCode{Value: severity}},
+		},
+	}, nil
+}
+
+func TestConformsTo_Evaluates(t *testing.T) {
+	ctx := &expr.Context{Validator: fakeValidator{
+		"valid":   cpb.IssueSeverityCode_INFORMATION,
+		"warning": cpb.IssueSeverityCode_WARNING,
+		"invalid": cpb.IssueSeverityCode_ERROR,
+		"fatal":   cpb.IssueSeverityCode_FATAL,
+	}}
+	patient := &ppb.Patient{}
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		profile string
+		want    system.Collection
+	}{
+		{
+			name:    "empty input",
+			input:   system.Collection{},
+			profile: "valid",
+			want:    system.Collection{},
+		},
+		{
+			name:    "conforming element",
+			input:   system.Collection{patient},
+			profile: "valid",
+			want:    system.Collection{system.Boolean(true)},
+		},
+		{
+			name:    "element with warnings",
+			input:   system.Collection{patient},
+			profile: "warning",
+			want:    system.Collection{system.Boolean(true)},
+		},
+		{
+			name:    "element with errors",
+			input:   system.Collection{patient},
+			profile: "invalid",
+			want:    system.Collection{system.Boolean(false)},
+		},
+		{
+			name:    "element with fatal errors",
+			input:   system.Collection{patient},
+			profile: "fatal",
+			want:    system.Collection{system.Boolean(false)},
+		},
+		{
+			name:    "unknown profile",
+			input:   system.Collection{patient},
+			profile: "unknown",
+			want:    system.Collection{},
+		},
+		{
+			name:    "system type input",
+			input:   system.Collection{system.String("a")},
+			profile: "valid",
+			want:    system.Collection{system.Boolean(false)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.ConformsTo(ctx, tc.input, exprtest.Return(system.String(tc.profile)))
+			if err != nil {
+				t.Fatalf("ConformsTo function returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ConformsTo function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConformsTo_RaisesError(t *testing.T) {
+	testErr := errors.New("test error")
+	validator := fakeValidator{}
+	patient := &ppb.Patient{}
+	testCases := []struct {
+		name    string
+		ctx     *expr.Context
+		input   system.Collection
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "too few arguments",
+			ctx:     &expr.Context{Validator: validator},
+			input:   system.Collection{patient},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "no profile validator",
+			ctx:     &expr.Context{},
+			input:   system.Collection{patient},
+			args:    []expr.Expression{exprtest.Return(system.String("valid"))},
+			wantErr: impl.ErrNoValidator,
+		},
+		{
+			name:    "multiple inputs",
+			ctx:     &expr.Context{Validator: validator},
+			input:   system.Collection{patient, patient},
+			args:    []expr.Expression{exprtest.Return(system.String("valid"))},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name:    "argument errors",
+			ctx:     &expr.Context{Validator: validator},
+			input:   system.Collection{patient},
+			args:    []expr.Expression{exprtest.Error(testErr)},
+			wantErr: testErr,
+		},
+		{
+			name:    "validator errors",
+			ctx:     &expr.Context{Validator: validator},
+			input:   system.Collection{patient},
+			args:    []expr.Expression{exprtest.Return(system.String("error"))},
+			wantErr: cmpopts.AnyError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := impl.ConformsTo(tc.ctx, tc.input, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("ConformsTo(%v): got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
+
+func TestHasValue_Evaluates(t *testing.T) {
+	noValue := extension.New("https://g.co/fhir/StructureDefinition/primitiveHasNoValue", fhir.Boolean(true))
+	dataAbsentReason := extension.New("http://hl7.org/fhir/StructureDefinition/data-absent-reason", fhir.Code("unknown"))
+	birthDate := fhir.MustParseDate("2000-01-01")
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "multiple inputs",
+			input: system.Collection{fhir.String("a"), fhir.String("b")},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "string with value",
+			input: system.Collection{fhir.String("a")},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "empty string",
+			input: system.Collection{fhir.String("")},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "date with value",
+			input: system.Collection{birthDate},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "date without precision",
+			input: system.Collection{&dtpb.Date{}},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name: "date with only extensions",
+			input: system.Collection{&dtpb.Date{
+				Precision: dtpb.Date_DAY,
+				Extension: []*dtpb.Extension{noValue, dataAbsentReason},
+			}},
+			want: system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "enum code with value",
+			input: system.Collection{&ppb.Patient_GenderCode{Value: cpb.AdministrativeGenderCode_FEMALE}},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "uninitialized enum code",
+			input: system.Collection{&ppb.Patient_GenderCode{}},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "xhtml",
+			input: system.Collection{&dtpb.Xhtml{Value: "<div>text</div>"}},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "complex type",
+			input: system.Collection{&dtpb.HumanName{Family: fhir.String("Doe")}},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "system type",
+			input: system.Collection{system.String("a")},
+			want:  system.Collection{system.Boolean(false)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.HasValue(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("HasValue function returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("HasValue function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetValue_Evaluates(t *testing.T) {
+	noValue := extension.New("https://g.co/fhir/StructureDefinition/primitiveHasNoValue", fhir.Boolean(true))
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "multiple inputs",
+			input: system.Collection{fhir.String("a"), fhir.String("b")},
+			want:  system.Collection{},
+		},
+		{
+			name:  "string",
+			input: system.Collection{fhir.String("a")},
+			want:  system.Collection{system.String("a")},
+		},
+		{
+			name:  "integer",
+			input: system.Collection{fhir.Integer(5)},
+			want:  system.Collection{system.Integer(5)},
+		},
+		{
+			name:  "date",
+			input: system.Collection{fhir.MustParseDate("2000-01")},
+			want:  system.Collection{system.MustParseDate("2000-01")},
+		},
+		{
+			name:  "date without precision",
+			input: system.Collection{&dtpb.Date{}},
+			want:  system.Collection{},
+		},
+		{
+			name:  "string with only extensions",
+			input: system.Collection{&dtpb.String{Extension: []*dtpb.Extension{noValue}}},
+			want:  system.Collection{},
+		},
+		{
+			name:  "enum code",
+			input: system.Collection{&ppb.Patient_GenderCode{Value: cpb.AdministrativeGenderCode_FEMALE}},
+			want:  system.Collection{system.String("female")},
+		},
+		{
+			name:  "complex type",
+			input: system.Collection{&dtpb.HumanName{Family: fhir.String("Doe")}},
+			want:  system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.GetValue(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("GetValue function returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("GetValue function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrimitiveValueFunctions_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name string
+		fn   func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+	}{
+		{"hasValue", impl.HasValue},
+		{"getValue", impl.GetValue},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.fn(&expr.Context{}, system.Collection{fhir.String("a")}, exprtest.Return(system.String("a")))
+
+			if got, want := err, impl.ErrWrongArity; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("%v: got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
+
+// fakeStructures has the given element definitions, by path. Its slices are
+// named after codes, and contain the codings with that code. All queries fail
+// with err, if set.
+type fakeStructures struct {
+	definitions map[string]*dtpb.ElementDefinition
+	err         error
+}
+
+func (f fakeStructures) ElementDefinition(path string) (*dtpb.ElementDefinition, error) {
+	return f.definitions[path], f.err
+}
+
+func (f fakeStructures) InSlice(element fhir.Base, structure, slice string) (bool, error) {
+	coding, ok := element.(*dtpb.Coding)
+	return structure == "profile" && ok && coding.GetCode().GetValue() == slice, f.err
+}
+
+func TestSlice_Evaluates(t *testing.T) {
+	ctx := &expr.Context{Structures: fakeStructures{}}
+	a, b, otherA := fhir.Coding("system", "a"), fhir.Coding("system", "b"), fhir.Coding("other", "a")
+	testCases := []struct {
+		name      string
+		input     system.Collection
+		structure expr.Expression
+		slice     expr.Expression
+		want      system.Collection
+	}{
+		{
+			name:      "empty input",
+			input:     system.Collection{},
+			structure: exprtest.Return(system.String("profile")),
+			slice:     exprtest.Return(system.String("a")),
+			want:      system.Collection{},
+		},
+		{
+			name:      "members of the slice",
+			input:     system.Collection{a, b, otherA, system.String("a")},
+			structure: exprtest.Return(system.String("profile")),
+			slice:     exprtest.Return(system.String("a")),
+			want:      system.Collection{a, otherA},
+		},
+		{
+			name:      "unknown structure",
+			input:     system.Collection{a, b},
+			structure: exprtest.Return(system.String("unknown")),
+			slice:     exprtest.Return(system.String("a")),
+			want:      system.Collection{},
+		},
+		{
+			name:      "empty slice name",
+			input:     system.Collection{a, b},
+			structure: exprtest.Return(system.String("profile")),
+			slice:     exprtest.Return(),
+			want:      system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Slice(ctx, tc.input, tc.structure, tc.slice)
+			if err != nil {
+				t.Fatalf("Slice function returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Slice function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSlice_RaisesError(t *testing.T) {
+	testErr := errors.New("test error")
+	coding := fhir.Coding("system", "a")
+	profile, slice := exprtest.Return(system.String("profile")), exprtest.Return(system.String("a"))
+	testCases := []struct {
+		name    string
+		ctx     *expr.Context
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "too few arguments",
+			ctx:     &expr.Context{Structures: fakeStructures{}},
+			args:    []expr.Expression{profile},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "no structure definitions",
+			ctx:     &expr.Context{},
+			args:    []expr.Expression{profile, slice},
+			wantErr: impl.ErrNoStructures,
+		},
+		{
+			name:    "argument errors",
+			ctx:     &expr.Context{Structures: fakeStructures{}},
+			args:    []expr.Expression{profile, exprtest.Error(testErr)},
+			wantErr: testErr,
+		},
+		{
+			name:    "structure definitions error",
+			ctx:     &expr.Context{Structures: fakeStructures{err: testErr}},
+			args:    []expr.Expression{profile, slice},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := impl.Slice(tc.ctx, system.Collection{coding}, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("Slice(%v): got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
+
+func TestCheckModifiers_Evaluates(t *testing.T) {
+	modified := &ppb.Patient{
+		ModifierExtension: []*dtpb.Extension{
+			extension.New("http://example.com/a", fhir.Boolean(true)),
+			extension.New("http://example.com/b", fhir.Boolean(true)),
+		},
+	}
+	testCases := []struct {
+		name  string
+		input system.Collection
+		args  []expr.Expression
+	}{
+		{
+			name:  "no modifier extensions",
+			input: system.Collection{&ppb.Patient{}, fhir.String("a"), system.String("b")},
+		},
+		{
+			name:  "known modifier extensions",
+			input: system.Collection{modified},
+			args:  []expr.Expression{exprtest.Return(system.String("http://example.com/a"), fhir.String("http://example.com/b"))},
+		},
+		{
+			name:  "comma-separated modifier extensions",
+			input: system.Collection{modified},
+			args:  []expr.Expression{exprtest.Return(system.String("http://example.com/b, http://example.com/a"))},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.CheckModifiers(&expr.Context{}, tc.input, tc.args...)
+			if err != nil {
+				t.Fatalf("CheckModifiers function returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.input, got, protocmp.Transform()); diff != "" {
+				t.Errorf("CheckModifiers function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckModifiers_RaisesError(t *testing.T) {
+	testErr := errors.New("test error")
+	modified := &ppb.Patient{
+		ModifierExtension: []*dtpb.Extension{extension.New("http://example.com/a", fhir.Boolean(true))},
+	}
+	testCases := []struct {
+		name    string
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "too many arguments",
+			args:    []expr.Expression{exprtest.Return(system.String("a")), exprtest.Return(system.String("b"))},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "no known modifier extensions",
+			wantErr: impl.ErrUnknownModifier,
+		},
+		{
+			name:    "unknown modifier extension",
+			args:    []expr.Expression{exprtest.Return(system.String("http://example.com/b"))},
+			wantErr: impl.ErrUnknownModifier,
+		},
+		{
+			name:    "non-string modifier",
+			args:    []expr.Expression{exprtest.Return(system.Integer(1))},
+			wantErr: impl.ErrInvalidInput,
+		},
+		{
+			name:    "argument errors",
+			args:    []expr.Expression{exprtest.Error(testErr)},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := impl.CheckModifiers(&expr.Context{}, system.Collection{modified}, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("CheckModifiers(%v): got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
+
+func TestElementDefinition_Evaluates(t *testing.T) {
+	definitions := map[string]*dtpb.ElementDefinition{}
+	for _, path := range []string{"Patient", "Patient.name", "Patient.name.given", "Patient.deceasedBoolean", "Patient.managingOrganization", "Organization.name"} {
+		definitions[path] = &dtpb.ElementDefinition{Path: fhir.String(path)}
+	}
+	organization := &orgpb.Organization{Name: fhir.String("Verily")}
+	patient := &ppb.Patient{
+		Name:     []*dtpb.HumanName{{Given: []*dtpb.String{fhir.String("Senpai"), fhir.String("Kang")}}},
+		Gender:   &ppb.Patient_GenderCode{Value: cpb.AdministrativeGenderCode_FEMALE},
+		Deceased: &ppb.Patient_DeceasedX{Choice: &ppb.Patient_DeceasedX_Boolean{Boolean: fhir.Boolean(false)}},
+	}
+	bundle := &bcrpb.Bundle{
+		Entry: []*bcrpb.Bundle_Entry{{Resource: containedresource.Wrap(organization)}},
+	}
+	ctx := &expr.Context{
+		ExternalConstants: map[string]any{"context": system.Collection{patient, bundle}},
+		Structures:        fakeStructures{definitions: definitions},
+	}
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "resource",
+			input: system.Collection{patient},
+			want:  system.Collection{definitions["Patient"]},
+		},
+		{
+			name:  "elements",
+			input: system.Collection{patient.Name[0], patient.Name[0].Given[1]},
+			want:  system.Collection{definitions["Patient.name"], definitions["Patient.name.given"]},
+		},
+		{
+			name:  "choice type element",
+			input: system.Collection{patient.GetDeceased().GetBoolean()},
+			want:  system.Collection{definitions["Patient.deceasedBoolean"]},
+		},
+		{
+			name:  "element of a bundled resource",
+			input: system.Collection{organization.Name},
+			want:  system.Collection{definitions["Organization.name"]},
+		},
+		{
+			name:  "unknown path",
+			input: system.Collection{patient.Gender},
+			want:  system.Collection{},
+		},
+		{
+			name:  "element outside of the context",
+			input: system.Collection{&dtpb.HumanName{}, system.String("a")},
+			want:  system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.ElementDefinition(ctx, tc.input)
+			if err != nil {
+				t.Fatalf("ElementDefinition function returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ElementDefinition function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestElementDefinition_RaisesError(t *testing.T) {
+	testErr := errors.New("test error")
+	patient := &ppb.Patient{}
+	context := map[string]any{"context": system.Collection{patient}}
+	testCases := []struct {
+		name    string
+		ctx     *expr.Context
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "too many arguments",
+			ctx:     &expr.Context{ExternalConstants: context, Structures: fakeStructures{}},
+			args:    []expr.Expression{exprtest.Return(system.String("a"))},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "no structure definitions",
+			ctx:     &expr.Context{ExternalConstants: context},
+			wantErr: impl.ErrNoStructures,
+		},
+		{
+			name:    "structure definitions error",
+			ctx:     &expr.Context{ExternalConstants: context, Structures: fakeStructures{err: testErr}},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := impl.ElementDefinition(tc.ctx, system.Collection{patient}, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("ElementDefinition(%v): got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package impl
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+)
+
+// The methods of the %server constant query the FHIRServer of the evaluation
+// context. Trailing parameters arguments, in URL query form, are accepted for
+// compatibility; only the "profile" parameter of validate() is used. The
+// methods that modify the server, and the operations other than $validate, are
+// not supported.
+//
+// For more details, see https://build.fhir.org/fhirpath.html#serverapi
+
+// ServerRead returns the resource with the given type and id, or empty if
+// there is no such resource.
+func ServerRead(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2", ErrWrongArity, len(args))
+	}
+	if err := requireServer(ctx, "read"); err != nil {
+		return nil, err
+	}
+	resourceType, ok, err := stringArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	id, ok, err := stringArg(ctx, input, args[1])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	res, err := ctx.Server.Read(resourceType, id)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return system.Collection{}, nil
+	}
+	return system.Collection{res}, nil
+}
+
+// ServerSearch returns a searchset Bundle of the resources that match the
+// given search, such as 'Patient?family=Chu'. The doPost argument must be a
+// Boolean, but has no effect, since the search is not sent over HTTP.
+func ServerSearch(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2", ErrWrongArity, len(args))
+	}
+	if err := requireServer(ctx, "search"); err != nil {
+		return nil, err
+	}
+	doPost, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if length := len(doPost); length > 1 {
+		return nil, fmt.Errorf("%w: received %v doPost values", expr.ErrNotSingleton, length)
+	} else if length == 1 {
+		value, err := system.From(doPost[0])
+		if _, ok := value.(system.Boolean); err != nil || !ok {
+			return nil, fmt.Errorf("%w: search() expects a Boolean doPost, got %T", ErrInvalidInput, doPost[0])
+		}
+	}
+	query, ok, err := stringArg(ctx, input, args[1])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	result, err := ctx.Server.Search(query)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return system.Collection{}, nil
+	}
+	return system.Collection{result}, nil
+}
+
+// ServerValidate validates the given resource for the given mode, returning
+// an OperationOutcome. The optional parameters may name a profile to validate
+// against, as in 'profile=http://example.com/StructureDefinition/x'.
+func ServerValidate(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2 or 3", ErrWrongArity, len(args))
+	}
+	if err := requireServer(ctx, "validate"); err != nil {
+		return nil, err
+	}
+	output, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if length := len(output); length > 1 {
+		return nil, fmt.Errorf("%w: received %v resources", expr.ErrNotSingleton, length)
+	} else if length == 0 {
+		return system.Collection{}, nil
+	}
+	res, ok := output[0].(fhir.Resource)
+	if !ok {
+		return nil, fmt.Errorf("%w: validate() expects a resource, got %T", ErrInvalidInput, output[0])
+	}
+	mode, ok, err := stringArg(ctx, input, args[1])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	var profile string
+	if len(args) == 3 {
+		params, _, err := stringArg(ctx, input, args[2])
+		if err != nil {
+			return nil, err
+		}
+		values, err := url.ParseQuery(params)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parsing validate() parameters: %w", ErrInvalidInput, err)
+		}
+		profile = values.Get("profile")
+	}
+	result, err := ctx.Server.Validate(res, mode, profile)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return system.Collection{}, nil
+	}
+	return system.Collection{result}, nil
+}
+
+// ServerUnsupported returns the implementation of a method of the %server
+// constant that a FHIRServer doesn't provide, such as create() or everything().
+// The method raises an ErrUnsupportedMethod error, rather than being unknown to
+// the compiler, so that the failure names the method.
+func ServerUnsupported(method string) func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error) {
+	return func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error) {
+		return nil, fmt.Errorf("%w: %%server.%s() is not supported", ErrUnsupportedMethod, method)
+	}
+}
+
+func requireServer(ctx *expr.Context, method string) error {
+	if ctx.Server == nil {
+		return fmt.Errorf("%w: %%server.%s() requires a FHIR server", ErrNoServer, method)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// fakeServer holds a single Patient with id "123". Searches return an empty
+// Bundle whose id is the query, and validation returns an OperationOutcome
+// whose id is the mode and profile.
+type fakeServer struct{}
+
+var fakePatient = &ppb.Patient{Id: fhir.ID("123")}
+
+func (fakeServer) Read(resourceType, id string) (fhir.Resource, error) {
+	if resourceType == "Patient" && id == "123" {
+		return fakePatient, nil
+	}
+	return nil, nil
+}
+
+func (fakeServer) Search(query string) (*bcrpb.Bundle, error) {
+	if query == "error" {
+		return nil, errors.New("test error")
+	}
+	return &bcrpb.Bundle{Id: fhir.ID(query)}, nil
+}
+
+func (fakeServer) Validate(res fhir.Resource, mode, profile string) (*oopb.OperationOutcome, error) {
+	return &oopb.OperationOutcome{Id: fhir.ID(mode + "|" + profile)}, nil
+}
+
+func TestServer_Evaluates(t *testing.T) {
+	ctx := &expr.Context{Server: fakeServer{}}
+	testCases := []struct {
+		name string
+		fn   func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		args []expr.Expression
+		want system.Collection
+	}{
+		{
+			name: "read existing resource",
+			fn:   impl.ServerRead,
+			args: []expr.Expression{exprtest.Return(system.String("Patient")), exprtest.Return(system.String("123"))},
+			want: system.Collection{fakePatient},
+		},
+		{
+			name: "read missing resource",
+			fn:   impl.ServerRead,
+			args: []expr.Expression{exprtest.Return(system.String("Patient")), exprtest.Return(system.String("456"))},
+			want: system.Collection{},
+		},
+		{
+			name: "read empty id",
+			fn:   impl.ServerRead,
+			args: []expr.Expression{exprtest.Return(system.String("Patient")), exprtest.Return()},
+			want: system.Collection{},
+		},
+		{
+			name: "search",
+			fn:   impl.ServerSearch,
+			args: []expr.Expression{exprtest.Return(system.Boolean(false)), exprtest.Return(system.String("Patient?_id=123"))},
+			want: system.Collection{&bcrpb.Bundle{Id: fhir.ID("Patient?_id=123")}},
+		},
+		{
+			name: "search with a FHIR Boolean doPost",
+			fn:   impl.ServerSearch,
+			args: []expr.Expression{exprtest.Return(fhir.Boolean(true)), exprtest.Return(system.String("Patient?_id=123"))},
+			want: system.Collection{&bcrpb.Bundle{Id: fhir.ID("Patient?_id=123")}},
+		},
+		{
+			name: "validate",
+			fn:   impl.ServerValidate,
+			args: []expr.Expression{exprtest.Return(fakePatient), exprtest.Return(system.String("create"))},
+			want: system.Collection{&oopb.OperationOutcome{Id: fhir.ID("create|")}},
+		},
+		{
+			name: "validate against profile",
+			fn:   impl.ServerValidate,
+			args: []expr.Expression{
+				exprtest.Return(fakePatient),
+				exprtest.Return(system.String("profile")),
+				exprtest.Return(system.String("profile=http://example.com/p")),
+			},
+			want: system.Collection{&oopb.OperationOutcome{Id: fhir.ID("profile|http://example.com/p")}},
+		},
+		{
+			name: "validate empty resource",
+			fn:   impl.ServerValidate,
+			args: []expr.Expression{exprtest.Return(), exprtest.Return(system.String("create"))},
+			want: system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(ctx, system.Collection{}, tc.args...)
+			if err != nil {
+				t.Fatalf("%s returned unexpected error: %v", tc.name, err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("%s returned unexpected diff (-want, +got):\n%s", tc.name, diff)
+			}
+		})
+	}
+}
+
+func TestServer_RaisesError(t *testing.T) {
+	testErr := errors.New("test error")
+	ctx := &expr.Context{Server: fakeServer{}}
+	testCases := []struct {
+		name    string
+		ctx     *expr.Context
+		fn      func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "read with one argument",
+			ctx:     ctx,
+			fn:      impl.ServerRead,
+			args:    []expr.Expression{exprtest.Return(system.String("Patient"))},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "search without a server",
+			ctx:     &expr.Context{},
+			fn:      impl.ServerSearch,
+			args:    []expr.Expression{exprtest.Return(system.Boolean(false)), exprtest.Return(system.String("Patient"))},
+			wantErr: impl.ErrNoServer,
+		},
+		{
+			name:    "search errors",
+			ctx:     ctx,
+			fn:      impl.ServerSearch,
+			args:    []expr.Expression{exprtest.Return(system.Boolean(false)), exprtest.Return(system.String("error"))},
+			wantErr: cmpopts.AnyError,
+		},
+		{
+			name:    "search with a non-Boolean doPost",
+			ctx:     ctx,
+			fn:      impl.ServerSearch,
+			args:    []expr.Expression{exprtest.Return(system.String("true")), exprtest.Return(system.String("Patient"))},
+			wantErr: impl.ErrInvalidInput,
+		},
+		{
+			name:    "search with multiple doPost values",
+			ctx:     ctx,
+			fn:      impl.ServerSearch,
+			args:    []expr.Expression{exprtest.Return(system.Boolean(true), system.Boolean(false)), exprtest.Return(system.String("Patient"))},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name:    "search doPost errors",
+			ctx:     ctx,
+			fn:      impl.ServerSearch,
+			args:    []expr.Expression{exprtest.Error(testErr), exprtest.Return(system.String("Patient"))},
+			wantErr: testErr,
+		},
+		{
+			name:    "validate non-resource",
+			ctx:     ctx,
+			fn:      impl.ServerValidate,
+			args:    []expr.Expression{exprtest.Return(system.String("Patient")), exprtest.Return(system.String("create"))},
+			wantErr: impl.ErrInvalidInput,
+		},
+		{
+			name:    "validate multiple resources",
+			ctx:     ctx,
+			fn:      impl.ServerValidate,
+			args:    []expr.Expression{exprtest.Return(fakePatient, fakePatient), exprtest.Return(system.String("create"))},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name:    "unsupported method",
+			ctx:     ctx,
+			fn:      impl.ServerUnsupported("create"),
+			args:    []expr.Expression{exprtest.Return(fakePatient)},
+			wantErr: impl.ErrUnsupportedMethod,
+		},
+		{
+			name:    "argument errors",
+			ctx:     ctx,
+			fn:      impl.ServerRead,
+			args:    []expr.Expression{exprtest.Error(testErr), exprtest.Return(system.String("123"))},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.fn(tc.ctx, system.Collection{}, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("%s: got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
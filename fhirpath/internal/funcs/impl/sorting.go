@@ -0,0 +1,156 @@
+package impl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+)
+
+// Sort returns the input collection ordered by the key expressions in args,
+// comparing the keys of each item in turn until one of them differs. Without
+// arguments, the items themselves are used as the key. The grammar has no
+// asc/desc keywords, so a key is sorted in descending order by prefixing it
+// with a minus sign, as in sort(-effective).
+//
+// Keys are compared with the Less methods of the System types. If the sort
+// compares two items that can't be ordered relative to each other, because
+// their keys are dates of different precision or quantities of incommensurable
+// units, the result is empty, as it is for the comparison operators. Empty keys are ordered before
+// all other values. The sort is stable.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#sortkeyselector-expression-collection
+func Sort(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) == 0 {
+		args = []expr.Expression{&expr.IdentityExpression{}}
+	}
+	keyExprs := make([]expr.Expression, len(args))
+	descending := make([]bool, len(args))
+	for i, arg := range args {
+		keyExprs[i] = arg
+		if negation, ok := arg.(*expr.NegationExpression); ok {
+			keyExprs[i] = negation.Expr
+			descending[i] = true
+		}
+	}
+
+	keys := make([][]system.Any, len(input))
+	for i, item := range input {
+		keys[i] = make([]system.Any, len(keyExprs))
+		for k, keyExpr := range keyExprs {
+			key, err := sortKey(ctx.WithIndex(i), item, keyExpr)
+			if err != nil {
+				return nil, err
+			}
+			keys[i][k] = key
+		}
+	}
+
+	indices := make([]int, len(input))
+	for i := range indices {
+		indices[i] = i
+	}
+	// sort.SliceStable can't be interrupted, so a pair of items that can't be
+	// ordered, or a failure to compare them, is recorded and checked once the
+	// sort is done.
+	var unordered bool
+	var compareErr error
+	sort.SliceStable(indices, func(i, j int) bool {
+		if unordered || compareErr != nil {
+			return false
+		}
+		a, b := indices[i], indices[j]
+		for k := range keyExprs {
+			cmp, ok, err := compareSortKeys(keys[a][k], keys[b][k])
+			if err != nil {
+				compareErr = err
+				return false
+			}
+			if !ok {
+				unordered = true
+				return false
+			}
+			if descending[k] {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+	if compareErr != nil {
+		return nil, compareErr
+	}
+	if unordered {
+		return system.Collection{}, nil
+	}
+
+	result := make(system.Collection, len(input))
+	for i, index := range indices {
+		result[i] = input[index]
+	}
+	return result, nil
+}
+
+// sortKey evaluates the key expression for a single item. Returns nil if the
+// key is empty.
+func sortKey(ctx *expr.Context, item any, keyExpr expr.Expression) (system.Any, error) {
+	result, err := keyExpr.Evaluate(ctx, system.Collection{item})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	if len(result) > 1 {
+		return nil, fmt.Errorf("%w: sort key contains %v elements", expr.ErrNotSingleton, len(result))
+	}
+	key, err := system.From(result[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", expr.ErrInvalidType, err)
+	}
+	return key, nil
+}
+
+// compareSortKeys returns -1, 0 or 1 depending on whether lhs orders before,
+// the same as, or after rhs. Returns false if the keys can't be ordered, which
+// the comparison operators treat as an empty result, rather than as a failure.
+func compareSortKeys(lhs, rhs system.Any) (int, bool, error) {
+	switch {
+	case lhs == nil && rhs == nil:
+		return 0, true, nil
+	case lhs == nil:
+		return -1, true, nil
+	case rhs == nil:
+		return 1, true, nil
+	}
+	lhs = system.Normalize(lhs, rhs)
+	rhs = system.Normalize(rhs, lhs)
+
+	less, err := lhs.Less(rhs)
+	if err != nil {
+		return unorderedKeys(err)
+	}
+	if less {
+		return -1, true, nil
+	}
+	greater, err := rhs.Less(lhs)
+	if err != nil {
+		return unorderedKeys(err)
+	}
+	if greater {
+		return 1, true, nil
+	}
+	return 0, true, nil
+}
+
+// unorderedKeys returns false for the errors of keys that can't be ordered,
+// and the error otherwise.
+func unorderedKeys(err error) (int, bool, error) {
+	if errors.Is(err, system.ErrMismatchedPrecision) || errors.Is(err, system.ErrMismatchedUnit) {
+		return 0, false, nil
+	}
+	return 0, false, err
+}
@@ -0,0 +1,192 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestSort(t *testing.T) {
+	family := &expr.FieldExpression{FieldName: "family"}
+	given := &expr.FieldExpression{FieldName: "given"}
+	smithJohn := &dtpb.HumanName{Family: fhir.String("Smith"), Given: []*dtpb.String{fhir.String("John")}}
+	smithAnna := &dtpb.HumanName{Family: fhir.String("Smith"), Given: []*dtpb.String{fhir.String("Anna")}}
+	doeJane := &dtpb.HumanName{Family: fhir.String("Doe"), Given: []*dtpb.String{fhir.String("Jane")}}
+	noFamily := &dtpb.HumanName{Given: []*dtpb.String{fhir.String("Cher")}}
+
+	testCases := []struct {
+		name  string
+		input system.Collection
+		args  []expr.Expression
+		want  system.Collection
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "sorts items without a key",
+			input: system.Collection{system.Integer(3), system.Integer(1), system.Integer(2)},
+			want:  system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+		},
+		{
+			name:  "sorts mixed fhir and system types",
+			input: system.Collection{fhir.Integer(3), system.MustParseDecimal("1.5"), system.Integer(2)},
+			want:  system.Collection{system.MustParseDecimal("1.5"), system.Integer(2), fhir.Integer(3)},
+		},
+		{
+			name:  "sorts in descending order with negated key",
+			input: system.Collection{system.String("b"), system.String("c"), system.String("a")},
+			args:  []expr.Expression{&expr.NegationExpression{Expr: &expr.IdentityExpression{}}},
+			want:  system.Collection{system.String("c"), system.String("b"), system.String("a")},
+		},
+		{
+			name:  "sorts by multiple keys",
+			input: system.Collection{smithJohn, doeJane, smithAnna},
+			args:  []expr.Expression{family, given},
+			want:  system.Collection{doeJane, smithAnna, smithJohn},
+		},
+		{
+			name:  "sorts by multiple keys in mixed order",
+			input: system.Collection{smithAnna, doeJane, smithJohn},
+			args:  []expr.Expression{family, &expr.NegationExpression{Expr: given}},
+			want:  system.Collection{doeJane, smithJohn, smithAnna},
+		},
+		{
+			name:  "places empty keys first",
+			input: system.Collection{smithJohn, noFamily, doeJane},
+			args:  []expr.Expression{family},
+			want:  system.Collection{noFamily, doeJane, smithJohn},
+		},
+		{
+			name:  "places empty keys last when descending",
+			input: system.Collection{noFamily, doeJane, smithJohn},
+			args:  []expr.Expression{&expr.NegationExpression{Expr: family}},
+			want:  system.Collection{smithJohn, doeJane, noFamily},
+		},
+		{
+			name:  "keeps input order of equal keys",
+			input: system.Collection{smithJohn, doeJane, smithAnna},
+			args:  []expr.Expression{family},
+			want:  system.Collection{doeJane, smithJohn, smithAnna},
+		},
+		{
+			name: "sorts dates whose precision differs but that are ordered",
+			input: system.Collection{
+				system.MustParseDate("2019-05-01"),
+				system.MustParseDate("2020"),
+				system.MustParseDate("2018-12"),
+			},
+			want: system.Collection{
+				system.MustParseDate("2018-12"),
+				system.MustParseDate("2019-05-01"),
+				system.MustParseDate("2020"),
+			},
+		},
+		{
+			name: "returns empty for dates with mismatched precision",
+			input: system.Collection{
+				system.MustParseDate("2020"),
+				system.MustParseDate("2020-01-05"),
+				system.MustParseDate("2019-12-31"),
+				system.MustParseDate("2020-02"),
+			},
+			want: system.Collection{},
+		},
+		{
+			name: "returns empty for quantities of incommensurable units",
+			input: system.Collection{
+				system.MustParseQuantity("1", "kg"),
+				system.MustParseQuantity("1", "year"),
+			},
+			want: system.Collection{},
+		},
+		{
+			name: "sorts quantities of commensurable units",
+			input: system.Collection{
+				system.MustParseQuantity("1", "kg"),
+				system.MustParseQuantity("20", "g"),
+			},
+			want: system.Collection{
+				system.MustParseQuantity("20", "g"),
+				system.MustParseQuantity("1", "kg"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Sort(&expr.Context{}, tc.input, tc.args...)
+			if err != nil {
+				t.Fatalf("Sort() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Sort() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSort_LargeInput(t *testing.T) {
+	const size = 10000
+	input := make(system.Collection, size)
+	want := make(system.Collection, size)
+	for i := range input {
+		input[i] = system.Integer(size - i)
+		want[i] = system.Integer(i + 1)
+	}
+
+	got, err := impl.Sort(&expr.Context{}, input)
+
+	if err != nil {
+		t.Fatalf("Sort() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Sort() returned unexpected diff (-want, +got)\n%s", diff)
+	}
+}
+
+func TestSort_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input system.Collection
+		args  []expr.Expression
+	}{
+		{
+			name:  "key expression raises error",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Error(errors.New("some error"))},
+		},
+		{
+			name:  "key is not a singleton",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(2))},
+		},
+		{
+			name:  "key is not a system type",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return(&dtpb.HumanName{})},
+		},
+		{
+			name:  "keys can't be compared",
+			input: system.Collection{system.Integer(1), system.String("a")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Sort(&expr.Context{}, tc.input, tc.args...); err == nil {
+				t.Errorf("Sort() didn't return error when expected")
+			}
+		})
+	}
+}
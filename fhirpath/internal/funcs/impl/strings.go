@@ -1,8 +1,13 @@
 package impl
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"regexp"
 	"strings"
 
@@ -10,7 +15,10 @@ import (
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
 )
 
-var ErrInvalidRegex = errors.New("invalid regex")
+var (
+	ErrInvalidRegex    = errors.New("invalid regex")
+	ErrInvalidEncoding = errors.New("invalid encoding")
+)
 
 // StartsWith returns true if the input string starts with the given prefix.
 func StartsWith(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
@@ -469,3 +477,211 @@ func Join(ctx *expr.Context, input system.Collection, args ...expr.Expression) (
 	}
 	return system.Collection{system.String(strings.Join(strs, delimiter))}, nil
 }
+
+// Trim returns the input string with leading and trailing whitespace removed.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#trim--string
+func Trim(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if length := len(args); length != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, length)
+	}
+	fullString, ok, err := stringInput(input)
+	if !ok || err != nil {
+		return system.Collection{}, err
+	}
+	return system.Collection{system.String(strings.TrimSpace(fullString))}, nil
+}
+
+// Split returns a collection of the substrings of the input string that are
+// separated by the given separator.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#splitseparator-string--collection
+func Split(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if length := len(args); length != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	fullString, ok, err := stringInput(input)
+	if !ok || err != nil {
+		return system.Collection{}, err
+	}
+	separator, ok, err := stringArg(ctx, input, args[0])
+	if !ok || err != nil {
+		return system.Collection{}, err
+	}
+	result := system.Collection{}
+	for _, part := range strings.Split(fullString, separator) {
+		result = append(result, system.String(part))
+	}
+	return result, nil
+}
+
+// LastIndexOf returns the 0-based index of the last position in which the
+// substring is found in the input string, or -1 if it is not found. Returns 0
+// if the substring is empty.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#lastindexofsubstring--string--integer
+func LastIndexOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if length := len(args); length != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	fullString, ok, err := stringInput(input)
+	if !ok || err != nil {
+		return system.Collection{}, err
+	}
+	substring, ok, err := stringArg(ctx, input, args[0])
+	if !ok || err != nil {
+		return system.Collection{}, err
+	}
+	if substring == "" {
+		return system.Collection{system.Integer(0)}, nil
+	}
+	return system.Collection{system.Integer(strings.LastIndex(fullString, substring))}, nil
+}
+
+// MatchesFull returns true when the entire input string matches the given
+// regular expression.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#matchesfullregex--string--boolean
+func MatchesFull(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if length := len(args); length != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	fullString, ok, err := stringInput(input)
+	if !ok || err != nil {
+		return system.Collection{}, err
+	}
+	regexString, ok, err := stringArg(ctx, input, args[0])
+	if !ok || err != nil {
+		return system.Collection{}, err
+	}
+	re, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", regexString))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRegex, regexString)
+	}
+	return system.Collection{system.Boolean(re.MatchString(fullString))}, nil
+}
+
+// Encode returns the input string encoded with the given format, which is one
+// of hex, base64 or urlbase64.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#encodeformat--string--string
+func Encode(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return transformString(ctx, input, args, func(format, value string) (string, error) {
+		switch format {
+		case "hex":
+			return hex.EncodeToString([]byte(value)), nil
+		case "base64":
+			return base64.StdEncoding.EncodeToString([]byte(value)), nil
+		case "urlbase64":
+			return base64.URLEncoding.EncodeToString([]byte(value)), nil
+		}
+		return "", fmt.Errorf("%w: unsupported encoding '%s'", ErrInvalidEncoding, format)
+	})
+}
+
+// Decode returns the input string decoded from the given format, which is one
+// of hex, base64 or urlbase64.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#decodeformat--string--string
+func Decode(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return transformString(ctx, input, args, func(format, value string) (string, error) {
+		var decoded []byte
+		var err error
+		switch format {
+		case "hex":
+			decoded, err = hex.DecodeString(value)
+		case "base64":
+			decoded, err = base64.StdEncoding.DecodeString(value)
+		case "urlbase64":
+			decoded, err = base64.URLEncoding.DecodeString(value)
+		default:
+			return "", fmt.Errorf("%w: unsupported encoding '%s'", ErrInvalidEncoding, format)
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+		}
+		return string(decoded), nil
+	})
+}
+
+// Escape returns the input string escaped for the given target, which is
+// either html or json.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#escapetarget--string--string
+func Escape(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return transformString(ctx, input, args, func(target, value string) (string, error) {
+		switch target {
+		case "html":
+			return html.EscapeString(value), nil
+		case "json":
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(value); err != nil {
+				return "", err
+			}
+			// Strip the enclosing quotes and the trailing newline of the encoder.
+			encoded := strings.TrimSuffix(buf.String(), "\n")
+			return encoded[1 : len(encoded)-1], nil
+		}
+		return "", fmt.Errorf("%w: unsupported escape target '%s'", ErrInvalidEncoding, target)
+	})
+}
+
+// Unescape returns the input string unescaped from the given target, which
+// is either html or json.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#unescapetarget--string--string
+func Unescape(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return transformString(ctx, input, args, func(target, value string) (string, error) {
+		switch target {
+		case "html":
+			return html.UnescapeString(value), nil
+		case "json":
+			var unescaped string
+			if err := json.Unmarshal([]byte(`"`+value+`"`), &unescaped); err != nil {
+				return "", fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+			}
+			return unescaped, nil
+		}
+		return "", fmt.Errorf("%w: unsupported escape target '%s'", ErrInvalidEncoding, target)
+	})
+}
+
+// transformString applies the transform to the single input string, using the
+// single string argument as the format.
+func transformString(ctx *expr.Context, input system.Collection, args []expr.Expression, transform func(format, value string) (string, error)) (system.Collection, error) {
+	if length := len(args); length != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, length)
+	}
+	fullString, ok, err := stringInput(input)
+	if !ok || err != nil {
+		return system.Collection{}, err
+	}
+	format, ok, err := stringArg(ctx, input, args[0])
+	if !ok || err != nil {
+		return system.Collection{}, err
+	}
+	result, err := transform(format, fullString)
+	if err != nil {
+		return nil, err
+	}
+	return system.Collection{system.String(result)}, nil
+}
+
+// stringInput returns the single string in the input collection. Returns false
+// if the input is empty.
+func stringInput(input system.Collection) (string, bool, error) {
+	if length := len(input); length > 1 {
+		return "", false, fmt.Errorf("%w: input has length %v, expected 1", ErrWrongArity, length)
+	} else if length == 0 {
+		return "", false, nil
+	}
+	value, err := input.ToString()
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// stringArg evaluates the argument against the input, and returns the single
+// string that it produces. Returns false if the argument evaluates to empty.
+func stringArg(ctx *expr.Context, input system.Collection, arg expr.Expression) (string, bool, error) {
+	output, err := arg.Evaluate(ctx, input)
+	if err != nil {
+		return "", false, err
+	}
+	return stringInput(output)
+}
@@ -1247,3 +1247,344 @@ func TestJoin(t *testing.T) {
 		})
 	}
 }
+
+func TestTrim(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "removes leading and trailing whitespace",
+			input: system.Collection{system.String("  Lee Jieun\t\n")},
+			want:  system.Collection{system.String("Lee Jieun")},
+		},
+		{
+			name:  "trims fhir strings",
+			input: system.Collection{fhir.String(" IU ")},
+			want:  system.Collection{system.String("IU")},
+		},
+		{
+			name:    "errors if input is not a string",
+			input:   system.Collection{system.Integer(516)},
+			wantErr: true,
+		},
+		{
+			name:    "errors if args are given",
+			input:   system.Collection{system.String("IU")},
+			args:    []expr.Expression{&expr.LiteralExpression{Literal: system.String(" ")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Trim(&expr.Context{}, tc.input, tc.args...)
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("Trim got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !tc.wantErr && !cmp.Equal(tc.want, got) {
+				t.Errorf("Trim returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String(",")}},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns empty for empty separator",
+			input: system.Collection{system.String("A,B")},
+			args:  []expr.Expression{&expr.LiteralExpression{}},
+			want:  system.Collection{},
+		},
+		{
+			name:  "splits on separator",
+			input: system.Collection{system.String("A,B,C")},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String(",")}},
+			want:  system.Collection{system.String("A"), system.String("B"), system.String("C")},
+		},
+		{
+			name:  "returns input when separator is not found",
+			input: system.Collection{system.String("ABC")},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String(",")}},
+			want:  system.Collection{system.String("ABC")},
+		},
+		{
+			name:    "errors if input length is more than 1",
+			input:   system.Collection{system.String("A"), system.String("B")},
+			args:    []expr.Expression{&expr.LiteralExpression{Literal: system.String(",")}},
+			wantErr: true,
+		},
+		{
+			name:    "errors if separator is not a string",
+			input:   system.Collection{system.String("A1B")},
+			args:    []expr.Expression{&expr.LiteralExpression{Literal: system.Integer(1)}},
+			wantErr: true,
+		},
+		{
+			name:    "errors if args length is not 1",
+			input:   system.Collection{system.String("A,B")},
+			args:    []expr.Expression{},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Split(&expr.Context{}, tc.input, tc.args...)
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("Split got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !tc.wantErr && !cmp.Equal(tc.want, got) {
+				t.Errorf("Split returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLastIndexOf(t *testing.T) {
+	fullString := system.String("abcdefabc")
+
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String("abc")}},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns last index of substring",
+			input: system.Collection{fullString},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String("abc")}},
+			want:  system.Collection{system.Integer(6)},
+		},
+		{
+			name:  "returns -1 when substring is not found",
+			input: system.Collection{fullString},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String("xyz")}},
+			want:  system.Collection{system.Integer(-1)},
+		},
+		{
+			name:  "returns 0 for empty substring",
+			input: system.Collection{fullString},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String("")}},
+			want:  system.Collection{system.Integer(0)},
+		},
+		{
+			name:    "errors if input is not a string",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{&expr.LiteralExpression{Literal: system.String("1")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.LastIndexOf(&expr.Context{}, tc.input, tc.args...)
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("LastIndexOf got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !tc.wantErr && !cmp.Equal(tc.want, got) {
+				t.Errorf("LastIndexOf returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchesFull(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns empty for empty input",
+			input: system.Collection{},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String("[a-z]+")}},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns true for full match",
+			input: system.Collection{system.String("abc")},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String("[a-z]+")}},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns false for partial match",
+			input: system.Collection{system.String("abc123")},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String("[a-z]+")}},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "anchors alternations",
+			input: system.Collection{system.String("abc123")},
+			args:  []expr.Expression{&expr.LiteralExpression{Literal: system.String("abc|123")}},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:    "errors for invalid regex",
+			input:   system.Collection{system.String("abc")},
+			args:    []expr.Expression{&expr.LiteralExpression{Literal: system.String("[a-z")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.MatchesFull(&expr.Context{}, tc.input, tc.args...)
+
+			if gotErr := err != nil; tc.wantErr != gotErr {
+				t.Fatalf("MatchesFull got unexpected error result: gotErr %v, wantErr %v, err: %v", gotErr, tc.wantErr, err)
+			}
+			if !tc.wantErr && !cmp.Equal(tc.want, got) {
+				t.Errorf("MatchesFull returned unexpected result: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		decoded string
+		format  string
+		encoded string
+	}{
+		{"hex", "test", "hex", "74657374"},
+		{"base64", "subjects?_d", "base64", "c3ViamVjdHM/X2Q="},
+		{"urlbase64", "subjects?_d", "urlbase64", "c3ViamVjdHM_X2Q="},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			format := &expr.LiteralExpression{Literal: system.String(tc.format)}
+
+			encoded, err := impl.Encode(&expr.Context{}, system.Collection{system.String(tc.decoded)}, format)
+			if err != nil {
+				t.Fatalf("Encode returned unexpected error: %v", err)
+			}
+			if want := (system.Collection{system.String(tc.encoded)}); !cmp.Equal(want, encoded) {
+				t.Errorf("Encode returned unexpected result: got %v, want %v", encoded, want)
+			}
+			decoded, err := impl.Decode(&expr.Context{}, system.Collection{system.String(tc.encoded)}, format)
+			if err != nil {
+				t.Fatalf("Decode returned unexpected error: %v", err)
+			}
+			if want := (system.Collection{system.String(tc.decoded)}); !cmp.Equal(want, decoded) {
+				t.Errorf("Decode returned unexpected result: got %v, want %v", decoded, want)
+			}
+		})
+	}
+}
+
+func TestEncodeDecode_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		fn     func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		input  string
+		format string
+	}{
+		{"encode with unsupported format", impl.Encode, "test", "base32"},
+		{"decode with unsupported format", impl.Decode, "test", "base32"},
+		{"decode invalid hex", impl.Decode, "xyz", "hex"},
+		{"decode invalid base64", impl.Decode, "c3ViamVjdHM/X2Q", "base64"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			format := &expr.LiteralExpression{Literal: system.String(tc.format)}
+
+			if _, err := tc.fn(&expr.Context{}, system.Collection{system.String(tc.input)}, format); err == nil {
+				t.Errorf("%s didn't return error when expected", tc.name)
+			}
+		})
+	}
+}
+
+func TestEscapeUnescape(t *testing.T) {
+	testCases := []struct {
+		name      string
+		unescaped string
+		target    string
+		escaped   string
+	}{
+		{"html", `"1 < 5"`, "html", "&#34;1 &lt; 5&#34;"},
+		{"json", `"1 < 5"`, "json", `\"1 < 5\"`},
+		{"json control characters", "a\tb\\c", "json", `a\tb\\c`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := &expr.LiteralExpression{Literal: system.String(tc.target)}
+
+			escaped, err := impl.Escape(&expr.Context{}, system.Collection{system.String(tc.unescaped)}, target)
+			if err != nil {
+				t.Fatalf("Escape returned unexpected error: %v", err)
+			}
+			if want := (system.Collection{system.String(tc.escaped)}); !cmp.Equal(want, escaped) {
+				t.Errorf("Escape returned unexpected result: got %v, want %v", escaped, want)
+			}
+			unescaped, err := impl.Unescape(&expr.Context{}, system.Collection{system.String(tc.escaped)}, target)
+			if err != nil {
+				t.Fatalf("Unescape returned unexpected error: %v", err)
+			}
+			if want := (system.Collection{system.String(tc.unescaped)}); !cmp.Equal(want, unescaped) {
+				t.Errorf("Unescape returned unexpected result: got %v, want %v", unescaped, want)
+			}
+		})
+	}
+}
+
+func TestEscapeUnescape_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		fn     func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		input  string
+		target string
+	}{
+		{"escape with unsupported target", impl.Escape, "test", "xml"},
+		{"unescape with unsupported target", impl.Unescape, "test", "xml"},
+		{"unescape invalid json", impl.Unescape, `\x`, "json"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := &expr.LiteralExpression{Literal: system.String(tc.target)}
+
+			if _, err := tc.fn(&expr.Context{}, system.Collection{system.String(tc.input)}, target); err == nil {
+				t.Errorf("%s didn't return error when expected", tc.name)
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
-	"google.golang.org/protobuf/proto"
 )
 
 // First Returns a collection containing only the first item in the input collection.
@@ -41,6 +40,20 @@ func Tail(ctx *expr.Context, input system.Collection, args ...expr.Expression) (
 	return input[1:], nil
 }
 
+// Single Returns the single item in the input if there is just one item.
+// If the input collection is empty ({ }), the result is empty.
+// If there are multiple items, an error is signaled to the evaluation environment.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#single-collection
+func Single(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if len(input) > 1 {
+		return nil, fmt.Errorf("%w: contains %v elements", expr.ErrNotSingleton, len(input))
+	}
+	return input, nil
+}
+
 // Skip Returns a collection containing all but the first num items in the input collection.
 // Will return an empty collection if there are no items remaining after the indicated number of items have been skipped,
 // or if the input collection is empty.
@@ -122,7 +135,7 @@ func Intersect(ctx *expr.Context, input system.Collection, args ...expr.Expressi
 	var result system.Collection
 	for _, i := range input {
 		for _, c := range argValues {
-			if checkEquality(i, c) {
+			if expr.ItemsEqual(i, c) {
 				v, _ := system.From(c)
 				result = append(result, v)
 			}
@@ -164,6 +177,66 @@ func Exclude(ctx *expr.Context, input system.Collection, args ...expr.Expression
 	return result, nil
 }
 
+// SubsetOf returns true if all items in the input collection are members of the
+// collection passed as the other argument. The argument is evaluated with
+// respect to the root of the expression (%context), not the input collection.
+// If the input collection is empty ({ }), the result is true.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#subsetofother-collection-boolean
+func SubsetOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	other, err := evaluateAgainstRoot(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return system.Collection{system.Boolean(isSubset(input, other))}, nil
+}
+
+// SupersetOf returns true if all items in the collection passed as the other
+// argument are members of the input collection. The argument is evaluated with
+// respect to the root of the expression (%context), not the input collection.
+// If the other collection is empty ({ }), the result is true.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#supersetofother-collection-boolean
+func SupersetOf(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	other, err := evaluateAgainstRoot(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return system.Collection{system.Boolean(isSubset(other, input))}, nil
+}
+
+// evaluateAgainstRoot evaluates the single argument of a set comparison function
+// against the %context root collection.
+func evaluateAgainstRoot(ctx *expr.Context, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	root, err := (&expr.ExternalConstantExpression{Identifier: "context"}).Evaluate(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return args[0].Evaluate(ctx, root)
+}
+
+// isSubset reports whether every item in subset is equal to some item in superset.
+func isSubset(subset, superset system.Collection) bool {
+	for _, item := range subset {
+		if !containsEqual(superset, item) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsEqual reports whether the collection contains an item equal to the
+// given item, using FHIRPath equality semantics.
+func containsEqual(collection system.Collection, item any) bool {
+	for _, other := range collection {
+		if expr.ItemsEqual(item, other) {
+			return true
+		}
+	}
+	return false
+}
+
 // Distinct returns the set of elements that are distinct and unique from the
 // input by applying equality-operation tests.
 //
@@ -213,26 +286,3 @@ func removeDuplicates(collection system.Collection) system.Collection {
 	}
 	return result
 }
-
-func checkEquality(lhs, rhs any) bool {
-	return checkSystemEquality(lhs, rhs) || checkProtoEquality(lhs, rhs)
-}
-
-func checkSystemEquality(lhs, rhs any) bool {
-	l, lerr := system.From(lhs)
-	r, rerr := system.From(rhs)
-	if lerr == nil && rerr == nil {
-		got, ok := system.TryEqual(l, r)
-		return got && ok
-	}
-	return false
-}
-
-func checkProtoEquality(lhs, rhs any) bool {
-	l, lok := lhs.(proto.Message)
-	r, rok := rhs.(proto.Message)
-	if lok && rok {
-		return proto.Equal(l, r)
-	}
-	return false
-}
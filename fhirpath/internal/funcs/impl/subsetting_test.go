@@ -1,6 +1,7 @@
 package impl_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
@@ -136,6 +137,51 @@ func TestTail(t *testing.T) {
 	}
 }
 
+func TestSingle(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns an empty collection if input is empty",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "returns the item of a singleton collection",
+			input: system.Collection{system.String("a")},
+			want:  system.Collection{system.String("a")},
+		},
+		{
+			name:    "errors if input has multiple items",
+			input:   system.Collection{system.String("a"), system.String("b")},
+			wantErr: true,
+		},
+		{
+			name:    "errors if arguments are provided",
+			input:   system.Collection{system.String("a")},
+			args:    []expr.Expression{exprtest.Return(system.Integer(1))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Single(&expr.Context{}, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Single() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Single() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestSkip(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -430,6 +476,150 @@ func TestIntersect(t *testing.T) {
 	}
 }
 
+func TestSubsetOf(t *testing.T) {
+	root := system.Collection{system.String("root")}
+	returnsRoot := &exprtest.MockExpression{
+		Eval: func(_ *expr.Context, input system.Collection) (system.Collection, error) {
+			return input, nil
+		},
+	}
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns true if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1))},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns true if all input items are in other",
+			input: system.Collection{system.Integer(1), fhir.Integer(2)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(2), system.Integer(1), system.Integer(3))},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns false if an input item is not in other",
+			input: system.Collection{system.Integer(1), system.Integer(4)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(2))},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "returns false if other is empty",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "compares complex types",
+			input: system.Collection{fhir.Coding("system", "code")},
+			args:  []expr.Expression{exprtest.Return(fhir.Coding("system", "other"), fhir.Coding("system", "code"))},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "evaluates argument against the root context",
+			input: system.Collection{system.String("root")},
+			args:  []expr.Expression{returnsRoot},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:    "errors if arg is not provided",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+		{
+			name:    "errors if argument raises error",
+			input:   system.Collection{system.Integer(1)},
+			args:    []expr.Expression{exprtest.Error(errors.New("some error"))},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &expr.Context{ExternalConstants: map[string]any{"context": root}}
+			got, err := impl.SubsetOf(ctx, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("SubsetOf() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("SubsetOf() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSupersetOf(t *testing.T) {
+	root := system.Collection{system.String("root")}
+	returnsRoot := &exprtest.MockExpression{
+		Eval: func(_ *expr.Context, input system.Collection) (system.Collection, error) {
+			return input, nil
+		},
+	}
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		want    system.Collection
+		wantErr bool
+	}{
+		{
+			name:  "returns true if other is empty",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return()},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns true if all other items are in input",
+			input: system.Collection{system.Integer(1), system.Integer(2), system.Integer(3)},
+			args:  []expr.Expression{exprtest.Return(fhir.Integer(3), system.Integer(1))},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "returns false if an other item is not in input",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1), system.Integer(2))},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "returns false if input is empty",
+			input: system.Collection{},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1))},
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "evaluates argument against the root context",
+			input: system.Collection{system.String("other"), system.String("root")},
+			args:  []expr.Expression{returnsRoot},
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:    "errors if arg is not provided",
+			input:   system.Collection{system.Integer(1)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &expr.Context{ExternalConstants: map[string]any{"context": root}}
+			got, err := impl.SupersetOf(ctx, tc.input, tc.args...)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("SupersetOf() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("SupersetOf() returned unexpected diff (-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestExclude(t *testing.T) {
 	testCases := []struct {
 		name    string
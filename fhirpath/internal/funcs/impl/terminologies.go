@@ -0,0 +1,216 @@
+package impl
+
+import (
+	"fmt"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+)
+
+// The methods of the %terminologies constant wrap the FHIR terminology
+// operations. Each method accepts an optional trailing params argument, for
+// the additional operation parameters in URL query form; it is accepted for
+// compatibility, but is not used by the TerminologyProvider.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#txapi
+
+// TerminologiesExpand returns the value set with the given URL, or the given
+// ValueSet resource, with an expansion listing its codes.
+func TerminologiesExpand(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1 or 2", ErrWrongArity, len(args))
+	}
+	if err := requireTerminology(ctx, "expand"); err != nil {
+		return nil, err
+	}
+	valueSet, ok, err := canonicalArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	result, err := ctx.Terminology.Expand(valueSet)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return system.Collection{}, nil
+	}
+	return system.Collection{result}, nil
+}
+
+// TerminologiesLookup returns the details of the concept of the given Coding,
+// as a Parameters resource.
+func TerminologiesLookup(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1 or 2", ErrWrongArity, len(args))
+	}
+	if err := requireTerminology(ctx, "lookup"); err != nil {
+		return nil, err
+	}
+	coding, ok, err := codingArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	return parametersResult(ctx.Terminology.Lookup(coding))
+}
+
+// TerminologiesValidateVS validates the given code, Coding or CodeableConcept
+// against the value set with the given URL, returning a Parameters resource.
+func TerminologiesValidateVS(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2 or 3", ErrWrongArity, len(args))
+	}
+	if err := requireTerminology(ctx, "validateVS"); err != nil {
+		return nil, err
+	}
+	valueSet, ok, err := canonicalArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	concept, ok, err := conceptArg(ctx, input, args[1])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	return parametersResult(ctx.Terminology.ValidateVS(valueSet, concept))
+}
+
+// TerminologiesValidateCS validates the given code, Coding or CodeableConcept
+// against the code system with the given URL, returning a Parameters resource.
+func TerminologiesValidateCS(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2 or 3", ErrWrongArity, len(args))
+	}
+	if err := requireTerminology(ctx, "validateCS"); err != nil {
+		return nil, err
+	}
+	codeSystem, ok, err := canonicalArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	concept, ok, err := conceptArg(ctx, input, args[1])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	return parametersResult(ctx.Terminology.ValidateCS(codeSystem, concept))
+}
+
+// TerminologiesSubsumes returns the relationship between two codes or Codings
+// of the code system with the given URL, as a code of the
+// concept-subsumption-outcome value set. Returns empty if the relationship is
+// unknown.
+func TerminologiesSubsumes(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) < 3 || len(args) > 4 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 3 or 4", ErrWrongArity, len(args))
+	}
+	if err := requireTerminology(ctx, "subsumes"); err != nil {
+		return nil, err
+	}
+	codeSystem, ok, err := canonicalArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	codingA, ok, err := codingArg(ctx, input, args[1])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	codingB, ok, err := codingArg(ctx, input, args[2])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	outcome, err := ctx.Terminology.Subsumes(codeSystem, codingA.GetCode().GetValue(), codingB.GetCode().GetValue())
+	if err != nil || outcome == "" {
+		return system.Collection{}, err
+	}
+	return system.Collection{system.String(string(outcome))}, nil
+}
+
+// TerminologiesTranslate translates the given code or Coding using the concept
+// map with the given URL, returning a Parameters resource.
+func TerminologiesTranslate(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 2 or 3", ErrWrongArity, len(args))
+	}
+	if err := requireTerminology(ctx, "translate"); err != nil {
+		return nil, err
+	}
+	conceptMap, ok, err := canonicalArg(ctx, input, args[0])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	coding, ok, err := codingArg(ctx, input, args[1])
+	if err != nil || !ok {
+		return system.Collection{}, err
+	}
+	return parametersResult(ctx.Terminology.Translate(conceptMap, coding))
+}
+
+func requireTerminology(ctx *expr.Context, method string) error {
+	if ctx.Terminology == nil {
+		return fmt.Errorf("%w: %%terminologies.%s() requires a terminology provider", ErrNoTerminology, method)
+	}
+	return nil
+}
+
+// canonicalArg evaluates the argument against the input, and returns the
+// canonical URL that it produces, which is either a string or the url of a
+// resource such as a ValueSet. Returns false if the argument evaluates to empty.
+func canonicalArg(ctx *expr.Context, input system.Collection, arg expr.Expression) (string, bool, error) {
+	output, err := arg.Evaluate(ctx, input)
+	if err != nil {
+		return "", false, err
+	}
+	if len(output) == 1 {
+		if res, ok := output[0].(interface{ GetUrl() *dtpb.Uri }); ok {
+			url := res.GetUrl().GetValue()
+			return url, url != "", nil
+		}
+	}
+	return stringInput(output)
+}
+
+// conceptArg evaluates the argument against the input, and returns the single
+// code, Coding or CodeableConcept that it produces as a CodeableConcept.
+// Returns false if the argument evaluates to empty or to any other type.
+func conceptArg(ctx *expr.Context, input system.Collection, arg expr.Expression) (*dtpb.CodeableConcept, bool, error) {
+	output, err := arg.Evaluate(ctx, input)
+	if err != nil {
+		return nil, false, err
+	}
+	if length := len(output); length > 1 {
+		return nil, false, fmt.Errorf("%w: received %v values", expr.ErrNotSingleton, length)
+	} else if length == 0 {
+		return nil, false, nil
+	}
+	if concept, ok := output[0].(*dtpb.CodeableConcept); ok {
+		return concept, true, nil
+	}
+	codings, ok := codingsOf(output[0])
+	if !ok {
+		return nil, false, nil
+	}
+	return &dtpb.CodeableConcept{Coding: codings}, true, nil
+}
+
+// codingArg evaluates the argument against the input, and returns the single
+// code or Coding that it produces. Returns false if the argument evaluates to
+// empty, to any other type, or to a CodeableConcept without exactly one Coding.
+func codingArg(ctx *expr.Context, input system.Collection, arg expr.Expression) (*dtpb.Coding, bool, error) {
+	concept, ok, err := conceptArg(ctx, input, arg)
+	if err != nil || !ok || len(concept.GetCoding()) != 1 {
+		return nil, false, err
+	}
+	return concept.GetCoding()[0], true, nil
+}
+
+// parametersResult returns the result of a TerminologyProvider operation as a
+// collection, which is empty if the operation has no outcome.
+func parametersResult(result *ppb.Parameters, err error) (system.Collection, error) {
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return system.Collection{}, nil
+	}
+	return system.Collection{result}, nil
+}
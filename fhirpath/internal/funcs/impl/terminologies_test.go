@@ -0,0 +1,260 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	parpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// The operations of fakeTerminology return Parameters with a single "code"
+// parameter, holding the code of the first coding they are given, or nil for
+// the unknown "z" code. Expand lists the codes of the value set.
+
+func (f fakeTerminology) Expand(valueSet string) (*vspb.ValueSet, error) {
+	codes, ok := f[valueSet]
+	if !ok {
+		return nil, errors.New("test error")
+	}
+	expansion := &vspb.ValueSet_Expansion{}
+	for _, code := range codes {
+		expansion.Contains = append(expansion.Contains, &vspb.ValueSet_Expansion_Contains{Code: fhir.Code(code)})
+	}
+	return &vspb.ValueSet{Url: fhir.URI(valueSet), Expansion: expansion}, nil
+}
+
+func (f fakeTerminology) Lookup(coding *dtpb.Coding) (*parpb.Parameters, error) {
+	return fakeParameters(coding.GetCode().GetValue()), nil
+}
+
+func (f fakeTerminology) ValidateVS(valueSet string, concept *dtpb.CodeableConcept) (*parpb.Parameters, error) {
+	return fakeParameters(concept.GetCoding()[0].GetCode().GetValue()), nil
+}
+
+func (f fakeTerminology) ValidateCS(codeSystem string, concept *dtpb.CodeableConcept) (*parpb.Parameters, error) {
+	return fakeParameters(concept.GetCoding()[0].GetCode().GetValue()), nil
+}
+
+func (f fakeTerminology) Translate(conceptMap string, coding *dtpb.Coding) (*parpb.Parameters, error) {
+	return fakeParameters(coding.GetCode().GetValue()), nil
+}
+
+func fakeParameters(code string) *parpb.Parameters {
+	if code == "z" {
+		return nil
+	}
+	return &parpb.Parameters{
+		Parameter: []*parpb.Parameters_Parameter{{
+			Name:  fhir.String("code"),
+			Value: &parpb.Parameters_Parameter_ValueX{Choice: &parpb.Parameters_Parameter_ValueX_Code{Code: fhir.Code(code)}},
+		}},
+	}
+}
+
+func TestTerminologies_Evaluates(t *testing.T) {
+	ctx := &expr.Context{Terminology: fakeTerminology{"vs": {"a", "b"}}}
+	valueSet := &vspb.ValueSet{Url: fhir.URI("vs")}
+	testCases := []struct {
+		name string
+		fn   func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		args []expr.Expression
+		want system.Collection
+	}{
+		{
+			name: "expand by url",
+			fn:   impl.TerminologiesExpand,
+			args: []expr.Expression{exprtest.Return(system.String("vs"))},
+			want: system.Collection{&vspb.ValueSet{
+				Url: fhir.URI("vs"),
+				Expansion: &vspb.ValueSet_Expansion{Contains: []*vspb.ValueSet_Expansion_Contains{
+					{Code: fhir.Code("a")},
+					{Code: fhir.Code("b")},
+				}},
+			}},
+		},
+		{
+			name: "expand by resource, with params",
+			fn:   impl.TerminologiesExpand,
+			args: []expr.Expression{exprtest.Return(valueSet), exprtest.Return(system.String("count=1"))},
+			want: system.Collection{&vspb.ValueSet{
+				Url: fhir.URI("vs"),
+				Expansion: &vspb.ValueSet_Expansion{Contains: []*vspb.ValueSet_Expansion_Contains{
+					{Code: fhir.Code("a")},
+					{Code: fhir.Code("b")},
+				}},
+			}},
+		},
+		{
+			name: "expand empty value set",
+			fn:   impl.TerminologiesExpand,
+			args: []expr.Expression{exprtest.Return()},
+			want: system.Collection{},
+		},
+		{
+			name: "lookup coding",
+			fn:   impl.TerminologiesLookup,
+			args: []expr.Expression{exprtest.Return(fhir.Coding("sys", "a"))},
+			want: system.Collection{fakeParameters("a")},
+		},
+		{
+			name: "lookup unknown coding",
+			fn:   impl.TerminologiesLookup,
+			args: []expr.Expression{exprtest.Return(fhir.Coding("sys", "z"))},
+			want: system.Collection{},
+		},
+		{
+			name: "lookup codeable concept with multiple codings",
+			fn:   impl.TerminologiesLookup,
+			args: []expr.Expression{exprtest.Return(fhir.CodeableConcept("", fhir.Coding("sys", "a"), fhir.Coding("sys", "b")))},
+			want: system.Collection{},
+		},
+		{
+			name: "validateVS code",
+			fn:   impl.TerminologiesValidateVS,
+			args: []expr.Expression{exprtest.Return(system.String("vs")), exprtest.Return(fhir.Code("b"))},
+			want: system.Collection{fakeParameters("b")},
+		},
+		{
+			name: "validateVS empty code",
+			fn:   impl.TerminologiesValidateVS,
+			args: []expr.Expression{exprtest.Return(system.String("vs")), exprtest.Return()},
+			want: system.Collection{},
+		},
+		{
+			name: "validateCS codeable concept",
+			fn:   impl.TerminologiesValidateCS,
+			args: []expr.Expression{exprtest.Return(system.String("sys")), exprtest.Return(fhir.CodeableConcept("", fhir.Coding("sys", "c")))},
+			want: system.Collection{fakeParameters("c")},
+		},
+		{
+			name: "subsumes codings",
+			fn:   impl.TerminologiesSubsumes,
+			args: []expr.Expression{
+				exprtest.Return(system.String("sys")),
+				exprtest.Return(fhir.Coding("sys", "a")),
+				exprtest.Return(fhir.Coding("sys", "b")),
+			},
+			want: system.Collection{system.String("subsumes")},
+		},
+		{
+			name: "subsumes codes",
+			fn:   impl.TerminologiesSubsumes,
+			args: []expr.Expression{
+				exprtest.Return(system.String("sys")),
+				exprtest.Return(system.String("b")),
+				exprtest.Return(system.String("a")),
+			},
+			want: system.Collection{system.String("subsumed-by")},
+		},
+		{
+			name: "subsumes unknown relationship",
+			fn:   impl.TerminologiesSubsumes,
+			args: []expr.Expression{
+				exprtest.Return(system.String("sys")),
+				exprtest.Return(system.String("a")),
+				exprtest.Return(system.String("z")),
+			},
+			want: system.Collection{},
+		},
+		{
+			name: "translate coding",
+			fn:   impl.TerminologiesTranslate,
+			args: []expr.Expression{exprtest.Return(system.String("cm")), exprtest.Return(fhir.Coding("sys", "a"))},
+			want: system.Collection{fakeParameters("a")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(ctx, system.Collection{}, tc.args...)
+			if err != nil {
+				t.Fatalf("%s returned unexpected error: %v", tc.name, err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("%s returned unexpected diff (-want, +got):\n%s", tc.name, diff)
+			}
+		})
+	}
+}
+
+func TestTerminologies_RaisesError(t *testing.T) {
+	testErr := errors.New("test error")
+	ctx := &expr.Context{Terminology: fakeTerminology{}}
+	testCases := []struct {
+		name    string
+		ctx     *expr.Context
+		fn      func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "expand without arguments",
+			ctx:     ctx,
+			fn:      impl.TerminologiesExpand,
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "validateVS with too many arguments",
+			ctx:     ctx,
+			fn:      impl.TerminologiesValidateVS,
+			args:    []expr.Expression{exprtest.Return(), exprtest.Return(), exprtest.Return(), exprtest.Return()},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "lookup without a terminology provider",
+			ctx:     &expr.Context{},
+			fn:      impl.TerminologiesLookup,
+			args:    []expr.Expression{exprtest.Return(fhir.Coding("sys", "a"))},
+			wantErr: impl.ErrNoTerminology,
+		},
+		{
+			name:    "expand of an unknown value set",
+			ctx:     ctx,
+			fn:      impl.TerminologiesExpand,
+			args:    []expr.Expression{exprtest.Return(system.String("unknown"))},
+			wantErr: cmpopts.AnyError,
+		},
+		{
+			name:    "translate with multiple codings",
+			ctx:     ctx,
+			fn:      impl.TerminologiesTranslate,
+			args:    []expr.Expression{exprtest.Return(system.String("cm")), exprtest.Return(fhir.Code("a"), fhir.Code("b"))},
+			wantErr: expr.ErrNotSingleton,
+		},
+		{
+			name:    "subsumes errors",
+			ctx:     ctx,
+			fn:      impl.TerminologiesSubsumes,
+			args:    []expr.Expression{exprtest.Return(system.String("error")), exprtest.Return(fhir.Code("a")), exprtest.Return(fhir.Code("b"))},
+			wantErr: cmpopts.AnyError,
+		},
+		{
+			name:    "argument errors",
+			ctx:     ctx,
+			fn:      impl.TerminologiesValidateCS,
+			args:    []expr.Expression{exprtest.Error(testErr), exprtest.Return(fhir.Code("a"))},
+			wantErr: testErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.fn(tc.ctx, system.Collection{}, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("%s: got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package impl
+
+import (
+	"fmt"
+
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/reflection"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+)
+
+// Type returns the type information of each item of the input collection.
+// System types and FHIR primitives are described by a SimpleTypeInfo, and other
+// FHIR types by a ClassInfo.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#reflection
+func Type(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	result := system.Collection{}
+	for _, item := range input {
+		info, err := reflection.TypeInfoOf(item)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// Is is the function form of the "is" operator. It returns true if the
+// singleton input is of the type given by args[0], or of a subtype of it.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#istype-type-specifier
+func Is(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	typeSpecifier, err := typeSpecifierArg(ctx, input, args...)
+	if err != nil {
+		return nil, err
+	}
+	return (&expr.IsExpression{Expr: &expr.IdentityExpression{}, Type: typeSpecifier}).Evaluate(ctx, input)
+}
+
+// As is the function form of the "as" operator. It returns the singleton input
+// if it is of the type given by args[0], and empty otherwise.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#astype-type-specifier
+func As(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	typeSpecifier, err := typeSpecifierArg(ctx, input, args...)
+	if err != nil {
+		return nil, err
+	}
+	return (&expr.AsExpression{Expr: &expr.IdentityExpression{}, Type: typeSpecifier}).Evaluate(ctx, input)
+}
+
+// typeSpecifierArg evaluates the single argument of a type function, which
+// holds the type specifier that was resolved at compile time.
+func typeSpecifierArg(ctx *expr.Context, input system.Collection, args ...expr.Expression) (reflection.TypeSpecifier, error) {
+	if len(args) != 1 {
+		return reflection.TypeSpecifier{}, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	result, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return reflection.TypeSpecifier{}, err
+	}
+	if len(result) != 1 {
+		return reflection.TypeSpecifier{}, fmt.Errorf("%w: expected a single type specifier, got %v items", ErrInvalidReturnType, len(result))
+	}
+	typeSpecifier, ok := result[0].(reflection.TypeSpecifier)
+	if !ok {
+		return reflection.TypeSpecifier{}, fmt.Errorf("%w: expected a type specifier, got %T", ErrInvalidReturnType, result[0])
+	}
+	return typeSpecifier, nil
+}
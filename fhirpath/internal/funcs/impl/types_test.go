@@ -0,0 +1,195 @@
+package impl_test
+
+import (
+	"errors"
+	"testing"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/reflection"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestType_Evaluates(t *testing.T) {
+	testCases := []struct {
+		name            string
+		inputCollection system.Collection
+		wantCollection  system.Collection
+	}{
+		{
+			name:            "returns empty for empty input",
+			inputCollection: system.Collection{},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "returns simple type info for system type",
+			inputCollection: system.Collection{system.Boolean(true)},
+			wantCollection: system.Collection{
+				reflection.SimpleTypeInfo{Namespace: "System", Name: "Boolean", BaseType: "System.Any"},
+			},
+		},
+		{
+			name:            "returns simple type info for FHIR primitive",
+			inputCollection: system.Collection{fhir.String("a")},
+			wantCollection: system.Collection{
+				reflection.SimpleTypeInfo{Namespace: "FHIR", Name: "string", BaseType: "FHIR.Element"},
+			},
+		},
+		{
+			name:            "returns type info of each item",
+			inputCollection: system.Collection{fhir.String("a"), system.Integer(1)},
+			wantCollection: system.Collection{
+				reflection.SimpleTypeInfo{Namespace: "FHIR", Name: "string", BaseType: "FHIR.Element"},
+				reflection.SimpleTypeInfo{Namespace: "System", Name: "Integer", BaseType: "System.Any"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Type(&expr.Context{}, tc.inputCollection)
+			if err != nil {
+				t.Fatalf("Type function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got); diff != "" {
+				t.Errorf("Type function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestType_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name            string
+		inputCollection system.Collection
+		inputArgs       []expr.Expression
+	}{
+		{
+			name:            "too many arguments",
+			inputCollection: system.Collection{system.String("a")},
+			inputArgs:       []expr.Expression{exprtest.Return(system.String("a"))},
+		},
+		{
+			name:            "input without a type",
+			inputCollection: system.Collection{1},
+		},
+		{
+			name:            "collection with an item without a type",
+			inputCollection: system.Collection{system.String("a"), 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Type(&expr.Context{}, tc.inputCollection, tc.inputArgs...); err == nil {
+				t.Fatalf("evaluating Type function didn't return error when expected")
+			}
+		})
+	}
+}
+
+func TestIsAndAs_Evaluates(t *testing.T) {
+	quantity := reflection.MustCreateTypeSpecifier("FHIR", "Quantity")
+	age := &dtpb.Quantity{Value: fhir.Decimal(42)}
+	observationValue := &opb.Observation_ValueX{
+		Choice: &opb.Observation_ValueX_Quantity{Quantity: age},
+	}
+
+	testCases := []struct {
+		name            string
+		fn              func(*expr.Context, system.Collection, ...expr.Expression) (system.Collection, error)
+		inputCollection system.Collection
+		wantCollection  system.Collection
+	}{
+		{
+			name:            "is returns true for matching type",
+			fn:              impl.Is,
+			inputCollection: system.Collection{observationValue},
+			wantCollection:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:            "is returns false for other type",
+			fn:              impl.Is,
+			inputCollection: system.Collection{fhir.String("a")},
+			wantCollection:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:            "is returns empty for empty input",
+			fn:              impl.Is,
+			inputCollection: system.Collection{},
+			wantCollection:  system.Collection{},
+		},
+		{
+			name:            "as unwraps matching choice type",
+			fn:              impl.As,
+			inputCollection: system.Collection{observationValue},
+			wantCollection:  system.Collection{age},
+		},
+		{
+			name:            "as returns empty for other type",
+			fn:              impl.As,
+			inputCollection: system.Collection{fhir.String("a")},
+			wantCollection:  system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(&expr.Context{}, tc.inputCollection, exprtest.Return(quantity))
+			if err != nil {
+				t.Fatalf("type function returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCollection, got, protocmp.Transform()); diff != "" {
+				t.Errorf("type function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsAndAs_RaisesError(t *testing.T) {
+	quantity := reflection.MustCreateTypeSpecifier("FHIR", "Quantity")
+
+	testCases := []struct {
+		name            string
+		inputCollection system.Collection
+		inputArgs       []expr.Expression
+	}{
+		{
+			name:            "no arguments",
+			inputCollection: system.Collection{system.String("a")},
+			inputArgs:       []expr.Expression{},
+		},
+		{
+			name:            "argument is not a type specifier",
+			inputCollection: system.Collection{system.String("a")},
+			inputArgs:       []expr.Expression{exprtest.Return(system.String("Quantity"))},
+		},
+		{
+			name:            "argument expression raises error",
+			inputCollection: system.Collection{system.String("a")},
+			inputArgs:       []expr.Expression{exprtest.Error(errors.New("some error"))},
+		},
+		{
+			name:            "input is not a singleton",
+			inputCollection: system.Collection{system.String("a"), system.String("b")},
+			inputArgs:       []expr.Expression{exprtest.Return(quantity)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Is(&expr.Context{}, tc.inputCollection, tc.inputArgs...); err == nil {
+				t.Errorf("evaluating Is function didn't return error when expected")
+			}
+			if _, err := impl.As(&expr.Context{}, tc.inputCollection, tc.inputArgs...); err == nil {
+				t.Errorf("evaluating As function didn't return error when expected")
+			}
+		})
+	}
+}
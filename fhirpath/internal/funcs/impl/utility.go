@@ -1,6 +1,9 @@
 package impl
 
 import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
 )
@@ -22,3 +25,225 @@ func Now(ctx *expr.Context, input system.Collection, args ...expr.Expression) (s
 	dateTimeString := ctx.Now.Format("2006-01-02T15:04:05.000Z07:00")
 	return system.Collection{system.MustParseDateTime(dateTimeString)}, nil
 }
+
+// Trace passes the input collection to the trace sink of the context, if it
+// has one, along with the name given by args[0]. If a projection is given by
+// args[1], the result of the projection is traced instead. The input collection
+// is returned unchanged.
+// FHIRPath docs here: https://hl7.org/fhirpath/N1/#tracename-string-projection-expression-collection
+func Trace(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1 or 2", ErrWrongArity, len(args))
+	}
+	nameResult, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	name, err := nameResult.ToString()
+	if err != nil {
+		return nil, err
+	}
+	traced := input
+	if len(args) == 2 {
+		traced, err = args[1].Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if ctx.TraceSink != nil {
+		ctx.TraceSink.Trace(name, traced)
+	}
+	return input, nil
+}
+
+// Default precisions used by lowBoundary() and highBoundary() when no
+// precision argument is given.
+const (
+	defaultDecimalBoundaryPrecision  = 8
+	defaultDateBoundaryPrecision     = 8
+	defaultDateTimeBoundaryPrecision = 17
+	defaultTimeBoundaryPrecision     = 9
+)
+
+// LowBoundary returns the least possible value of the input to the precision
+// given by args[0], given the implicit uncertainty of the input's own
+// precision. Returns empty if the precision is not valid for the input type.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#lowboundaryprecision-integer-decimal--date--datetime--time
+func LowBoundary(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return boundary(ctx, input, false, args...)
+}
+
+// HighBoundary returns the greatest possible value of the input to the
+// precision given by args[0], given the implicit uncertainty of the input's
+// own precision. Returns empty if the precision is not valid for the input
+// type.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#highboundaryprecision-integer-decimal--date--datetime--time
+func HighBoundary(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	return boundary(ctx, input, true, args...)
+}
+
+// boundary implements both LowBoundary and HighBoundary.
+func boundary(ctx *expr.Context, input system.Collection, high bool, args ...expr.Expression) (system.Collection, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0 or 1", ErrWrongArity, len(args))
+	}
+	item, err := singletonSystemValue(input)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return system.Collection{}, nil
+	}
+
+	var precision int
+	switch item.(type) {
+	case system.Decimal, system.Quantity:
+		precision = defaultDecimalBoundaryPrecision
+	case system.Date:
+		precision = defaultDateBoundaryPrecision
+	case system.DateTime:
+		precision = defaultDateTimeBoundaryPrecision
+	case system.Time:
+		precision = defaultTimeBoundaryPrecision
+	default:
+		return nil, fmt.Errorf("%w: boundaries are not defined for %T", ErrInvalidInput, item)
+	}
+	if len(args) == 1 {
+		result, err := args[0].Evaluate(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if result.IsEmpty() {
+			return system.Collection{}, nil
+		}
+		value, err := result.ToInt32()
+		if err != nil {
+			return nil, err
+		}
+		precision = int(value)
+	}
+
+	var value system.Any
+	var ok bool
+	switch v := item.(type) {
+	case system.Decimal:
+		if high {
+			value, ok = v.HighBoundary(precision)
+		} else {
+			value, ok = v.LowBoundary(precision)
+		}
+	case system.Quantity:
+		if high {
+			value, ok = v.HighBoundary(precision)
+		} else {
+			value, ok = v.LowBoundary(precision)
+		}
+	case system.Date:
+		if high {
+			value, ok = v.HighBoundary(precision)
+		} else {
+			value, ok = v.LowBoundary(precision)
+		}
+	case system.DateTime:
+		if high {
+			value, ok = v.HighBoundary(precision)
+		} else {
+			value, ok = v.LowBoundary(precision)
+		}
+	case system.Time:
+		if high {
+			value, ok = v.HighBoundary(precision)
+		} else {
+			value, ok = v.LowBoundary(precision)
+		}
+	}
+	if !ok {
+		return system.Collection{}, nil
+	}
+	return system.Collection{value}, nil
+}
+
+// Precision returns the number of digits of precision of the input. For
+// Decimals this is the number of digits after the decimal point, and for
+// Dates, DateTimes and Times it is the number of digits in the components
+// that are present.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#precision--integer
+func Precision(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	item, err := singletonSystemValue(input)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return system.Collection{}, nil
+	}
+	switch v := item.(type) {
+	case system.Decimal:
+		return system.Collection{system.Integer(v.Precision())}, nil
+	case system.Date:
+		return system.Collection{system.Integer(v.Precision())}, nil
+	case system.DateTime:
+		return system.Collection{system.Integer(v.Precision())}, nil
+	case system.Time:
+		return system.Collection{system.Integer(v.Precision())}, nil
+	}
+	return nil, fmt.Errorf("%w: precision is not defined for %T", ErrInvalidInput, item)
+}
+
+// Comparable returns true if the input quantity has units that are compatible
+// with the quantity given by args[0], such that the two can be compared.
+// FHIRPath docs here: https://build.fhir.org/ig/HL7/FHIRPath/#comparablequantity--boolean
+func Comparable(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 1", ErrWrongArity, len(args))
+	}
+	item, err := singletonSystemValue(input)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return system.Collection{}, nil
+	}
+	result, err := args[0].Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	other, err := singletonSystemValue(result)
+	if err != nil {
+		return nil, err
+	}
+	if other == nil {
+		return system.Collection{}, nil
+	}
+	lhs, ok := item.(system.Quantity)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a Quantity, got %T", ErrInvalidInput, item)
+	}
+	rhs, ok := other.(system.Quantity)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a Quantity argument, got %T", ErrInvalidInput, other)
+	}
+	return system.Collection{system.Boolean(lhs.Comparable(rhs))}, nil
+}
+
+// singletonSystemValue converts the single item of the collection to a System
+// type, with Integers converted to Decimals. Returns nil if the collection is
+// empty.
+func singletonSystemValue(input system.Collection) (system.Any, error) {
+	if input.IsEmpty() {
+		return nil, nil
+	}
+	if len(input) > 1 {
+		return nil, fmt.Errorf("%w: contains %v elements", expr.ErrNotSingleton, len(input))
+	}
+	item, err := system.From(input[0])
+	if err != nil {
+		return nil, err
+	}
+	if i, ok := item.(system.Integer); ok {
+		return system.Decimal(decimal.NewFromInt(int64(i))), nil
+	}
+	return item, nil
+}
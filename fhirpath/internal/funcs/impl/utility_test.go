@@ -1,13 +1,16 @@
 package impl_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
 	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
 )
 
 func TestTimeOfDay(t *testing.T) {
@@ -48,3 +51,404 @@ func TestNow(t *testing.T) {
 		t.Errorf("impl.Now() returned unexpected result: got %v, want %v", got, wantCollection)
 	}
 }
+
+type traceEntry struct {
+	name       string
+	collection system.Collection
+}
+
+type traceSink struct {
+	entries []traceEntry
+}
+
+func (s *traceSink) Trace(name string, collection system.Collection) {
+	s.entries = append(s.entries, traceEntry{name, collection})
+}
+
+func TestTrace(t *testing.T) {
+	input := system.Collection{system.Integer(1), system.Integer(2)}
+	testCases := []struct {
+		name        string
+		args        []expr.Expression
+		wantEntries []traceEntry
+	}{
+		{
+			name:        "traces the input collection",
+			args:        []expr.Expression{exprtest.Return(system.String("numbers"))},
+			wantEntries: []traceEntry{{"numbers", input}},
+		},
+		{
+			name: "traces the projected collection",
+			args: []expr.Expression{
+				exprtest.Return(system.String("projected")),
+				exprtest.Return(system.String("a")),
+			},
+			wantEntries: []traceEntry{{"projected", system.Collection{system.String("a")}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &traceSink{}
+			ctx := &expr.Context{TraceSink: sink}
+
+			got, err := impl.Trace(ctx, input, tc.args...)
+			if err != nil {
+				t.Fatalf("impl.Trace() returned unexpected error: %v", err)
+			}
+			if !cmp.Equal(got, input) {
+				t.Errorf("impl.Trace() returned unexpected result: got %v, want %v", got, input)
+			}
+			if diff := cmp.Diff(tc.wantEntries, sink.entries, cmp.AllowUnexported(traceEntry{})); diff != "" {
+				t.Errorf("impl.Trace() traced unexpected entries (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTrace_NoSink_ReturnsInput(t *testing.T) {
+	input := system.Collection{system.Integer(1)}
+
+	got, err := impl.Trace(&expr.Context{}, input, exprtest.Return(system.String("name")))
+	if err != nil {
+		t.Fatalf("impl.Trace() returned unexpected error: %v", err)
+	}
+	if !cmp.Equal(got, input) {
+		t.Errorf("impl.Trace() returned unexpected result: got %v, want %v", got, input)
+	}
+}
+
+func TestTrace_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []expr.Expression
+	}{
+		{
+			name: "no arguments",
+			args: []expr.Expression{},
+		},
+		{
+			name: "too many arguments",
+			args: []expr.Expression{exprtest.Return(system.String("a")), exprtest.Return(), exprtest.Return()},
+		},
+		{
+			name: "name is not a string",
+			args: []expr.Expression{exprtest.Return(system.Integer(1))},
+		},
+		{
+			name: "name expression raises error",
+			args: []expr.Expression{exprtest.Error(errors.New("some error"))},
+		},
+		{
+			name: "projection raises error",
+			args: []expr.Expression{exprtest.Return(system.String("a")), exprtest.Error(errors.New("some error"))},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Trace(&expr.Context{}, system.Collection{}, tc.args...); err == nil {
+				t.Fatalf("impl.Trace() didn't return error when expected")
+			}
+		})
+	}
+}
+
+func TestBoundaries(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    system.Collection
+		args     []expr.Expression
+		wantLow  system.Collection
+		wantHigh system.Collection
+	}{
+		{
+			name:     "empty input",
+			input:    system.Collection{},
+			wantLow:  system.Collection{},
+			wantHigh: system.Collection{},
+		},
+		{
+			name:     "decimal with default precision",
+			input:    system.Collection{system.MustParseDecimal("1.587")},
+			wantLow:  system.Collection{system.MustParseDecimal("1.58650000")},
+			wantHigh: system.Collection{system.MustParseDecimal("1.58750000")},
+		},
+		{
+			name:     "integer is converted to decimal",
+			input:    system.Collection{system.Integer(1)},
+			args:     []expr.Expression{exprtest.Return(system.Integer(1))},
+			wantLow:  system.Collection{system.MustParseDecimal("0.5")},
+			wantHigh: system.Collection{system.MustParseDecimal("1.5")},
+		},
+		{
+			name:     "partial date with default precision",
+			input:    system.Collection{system.MustParseDate("2020-03")},
+			wantLow:  system.Collection{system.MustParseDate("2020-03-01")},
+			wantHigh: system.Collection{system.MustParseDate("2020-03-31")},
+		},
+		{
+			name:     "date with precision",
+			input:    system.Collection{system.MustParseDate("2020")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(6))},
+			wantLow:  system.Collection{system.MustParseDate("2020-01")},
+			wantHigh: system.Collection{system.MustParseDate("2020-12")},
+		},
+		{
+			name:     "date time with default precision",
+			input:    system.Collection{system.MustParseDateTime("2020-03-01T10")},
+			wantLow:  system.Collection{system.MustParseDateTime("2020-03-01T10:00:00.000")},
+			wantHigh: system.Collection{system.MustParseDateTime("2020-03-01T10:59:59.999")},
+		},
+		{
+			name:     "time with default precision",
+			input:    system.Collection{system.MustParseTime("10:30")},
+			wantLow:  system.Collection{system.MustParseTime("10:30:00.000")},
+			wantHigh: system.Collection{system.MustParseTime("10:30:59.999")},
+		},
+		{
+			name:     "quantity with precision",
+			input:    system.Collection{system.MustParseQuantity("1.5", "mg")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(2))},
+			wantLow:  system.Collection{system.MustParseQuantity("1.45", "mg")},
+			wantHigh: system.Collection{system.MustParseQuantity("1.55", "mg")},
+		},
+		{
+			name:     "FHIR date",
+			input:    system.Collection{fhir.MustParseDate("2020")},
+			wantLow:  system.Collection{system.MustParseDate("2020-01-01")},
+			wantHigh: system.Collection{system.MustParseDate("2020-12-31")},
+		},
+		{
+			name:     "invalid precision",
+			input:    system.Collection{system.MustParseDate("2020")},
+			args:     []expr.Expression{exprtest.Return(system.Integer(17))},
+			wantLow:  system.Collection{},
+			wantHigh: system.Collection{},
+		},
+		{
+			name:     "empty precision",
+			input:    system.Collection{system.MustParseDate("2020")},
+			args:     []expr.Expression{exprtest.Return()},
+			wantLow:  system.Collection{},
+			wantHigh: system.Collection{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotLow, err := impl.LowBoundary(&expr.Context{}, tc.input, tc.args...)
+			if err != nil {
+				t.Fatalf("impl.LowBoundary() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantLow, gotLow); diff != "" {
+				t.Errorf("impl.LowBoundary() returned unexpected diff (-want, +got):\n%s", diff)
+			}
+			gotHigh, err := impl.HighBoundary(&expr.Context{}, tc.input, tc.args...)
+			if err != nil {
+				t.Fatalf("impl.HighBoundary() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantHigh, gotHigh); diff != "" {
+				t.Errorf("impl.HighBoundary() returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBoundaries_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input system.Collection
+		args  []expr.Expression
+	}{
+		{
+			name:  "too many arguments",
+			input: system.Collection{system.MustParseDecimal("1.5")},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1)), exprtest.Return(system.Integer(2))},
+		},
+		{
+			name:  "input is not a singleton",
+			input: system.Collection{system.MustParseDecimal("1.5"), system.MustParseDecimal("2.5")},
+		},
+		{
+			name:  "input has no boundaries",
+			input: system.Collection{system.String("1.5")},
+		},
+		{
+			name:  "precision is not an integer",
+			input: system.Collection{system.MustParseDecimal("1.5")},
+			args:  []expr.Expression{exprtest.Return(system.String("2"))},
+		},
+		{
+			name:  "precision raises error",
+			input: system.Collection{system.MustParseDecimal("1.5")},
+			args:  []expr.Expression{exprtest.Error(errors.New("some error"))},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.LowBoundary(&expr.Context{}, tc.input, tc.args...); err == nil {
+				t.Errorf("impl.LowBoundary() didn't return error when expected")
+			}
+			if _, err := impl.HighBoundary(&expr.Context{}, tc.input, tc.args...); err == nil {
+				t.Errorf("impl.HighBoundary() didn't return error when expected")
+			}
+		})
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "decimal",
+			input: system.Collection{system.MustParseDecimal("1.58700")},
+			want:  system.Collection{system.Integer(5)},
+		},
+		{
+			name:  "date",
+			input: system.Collection{system.MustParseDate("2014")},
+			want:  system.Collection{system.Integer(4)},
+		},
+		{
+			name:  "date time",
+			input: system.Collection{system.MustParseDateTime("2014-01-05T10:30:00.000")},
+			want:  system.Collection{system.Integer(17)},
+		},
+		{
+			name:  "time",
+			input: system.Collection{system.MustParseTime("10:30")},
+			want:  system.Collection{system.Integer(4)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Precision(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("impl.Precision() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("impl.Precision() returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrecision_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input system.Collection
+		args  []expr.Expression
+	}{
+		{
+			name:  "too many arguments",
+			input: system.Collection{system.MustParseDecimal("1.5")},
+			args:  []expr.Expression{exprtest.Return(system.Integer(1))},
+		},
+		{
+			name:  "quantity input",
+			input: system.Collection{system.MustParseQuantity("1.5", "mg")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Precision(&expr.Context{}, tc.input, tc.args...); err == nil {
+				t.Errorf("impl.Precision() didn't return error when expected")
+			}
+		})
+	}
+}
+
+func TestComparable(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input system.Collection
+		arg   expr.Expression
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			arg:   exprtest.Return(system.MustParseQuantity("1", "mg")),
+			want:  system.Collection{},
+		},
+		{
+			name:  "empty argument",
+			input: system.Collection{system.MustParseQuantity("1", "mg")},
+			arg:   exprtest.Return(),
+			want:  system.Collection{},
+		},
+		{
+			name:  "same units",
+			input: system.Collection{system.MustParseQuantity("1", "mg")},
+			arg:   exprtest.Return(system.MustParseQuantity("2", "mg")),
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "different units",
+			input: system.Collection{system.MustParseQuantity("1", "mg")},
+			arg:   exprtest.Return(system.MustParseQuantity("2", "cm")),
+			want:  system.Collection{system.Boolean(false)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.Comparable(&expr.Context{}, tc.input, tc.arg)
+			if err != nil {
+				t.Fatalf("impl.Comparable() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("impl.Comparable() returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestComparable_RaisesError(t *testing.T) {
+	quantity := system.MustParseQuantity("1", "mg")
+	testCases := []struct {
+		name  string
+		input system.Collection
+		args  []expr.Expression
+	}{
+		{
+			name:  "no arguments",
+			input: system.Collection{quantity},
+		},
+		{
+			name:  "input is not a quantity",
+			input: system.Collection{system.Integer(1)},
+			args:  []expr.Expression{exprtest.Return(quantity)},
+		},
+		{
+			name:  "argument is not a quantity",
+			input: system.Collection{quantity},
+			args:  []expr.Expression{exprtest.Return(system.String("1 mg"))},
+		},
+		{
+			name:  "argument raises error",
+			input: system.Collection{quantity},
+			args:  []expr.Expression{exprtest.Error(errors.New("some error"))},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := impl.Comparable(&expr.Context{}, tc.input, tc.args...); err == nil {
+				t.Errorf("impl.Comparable() didn't return error when expected")
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package impl
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+)
+
+// xhtmlNamespace is the namespace of the XHTML of narratives.
+const xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+
+// narrativeAttributes are the attributes allowed on any element of a
+// narrative.
+var narrativeAttributes = []string{"id", "class", "style", "title", "lang", "dir", "accesskey", "tabindex"}
+
+var cellAttributes = []string{"abbr", "axis", "headers", "scope", "rowspan", "colspan", "align", "char", "charoff", "valign"}
+
+// narrativeElements are the elements allowed in a narrative, which are the
+// basic formatting elements of HTML 4.0, along with links and images. Each
+// element is mapped to the attributes that it allows, in addition to the
+// narrativeAttributes.
+var narrativeElements = map[string][]string{
+	"a":          {"href", "name", "rel", "rev", "charset", "hreflang", "type", "shape", "coords"},
+	"abbr":       nil,
+	"acronym":    nil,
+	"address":    nil,
+	"area":       {"alt", "coords", "href", "nohref", "shape"},
+	"b":          nil,
+	"bdo":        nil,
+	"big":        nil,
+	"blockquote": {"cite"},
+	"br":         nil,
+	"caption":    nil,
+	"cite":       nil,
+	"code":       nil,
+	"col":        append([]string{"span", "width"}, cellAttributes...),
+	"colgroup":   append([]string{"span", "width"}, cellAttributes...),
+	"dd":         nil,
+	"del":        {"cite", "datetime"},
+	"dfn":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "longdesc", "height", "width", "usemap", "ismap", "border", "hspace", "vspace", "align"},
+	"ins":        {"cite", "datetime"},
+	"kbd":        nil,
+	"li":         {"value"},
+	"map":        {"name"},
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"q":          {"cite"},
+	"samp":       nil,
+	"small":      nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      {"summary", "width", "border", "frame", "rules", "cellspacing", "cellpadding"},
+	"tbody":      cellAttributes,
+	"td":         cellAttributes,
+	"tfoot":      cellAttributes,
+	"th":         cellAttributes,
+	"thead":      cellAttributes,
+	"tr":         cellAttributes,
+	"tt":         nil,
+	"ul":         nil,
+	"var":        nil,
+}
+
+// HTMLChecks returns true if the single input xhtml element meets the rules
+// of FHIR narratives: it must be a div element of the XHTML namespace, which
+// contains only the allowed subset of elements and attributes, without
+// scripts, and which has some non-whitespace content or an image. Returns empty
+// for any other kind of element.
+//
+// For more details, see https://hl7.org/fhir/R4/fhirpath.html#functions and
+// https://hl7.org/fhir/R4/narrative.html#xhtml
+func HTMLChecks(ctx *expr.Context, input system.Collection, args ...expr.Expression) (system.Collection, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: received %v arguments, expected 0", ErrWrongArity, len(args))
+	}
+	if length := len(input); length == 0 {
+		return system.Collection{}, nil
+	} else if length > 1 {
+		return nil, fmt.Errorf("%w: received %v inputs", expr.ErrNotSingleton, length)
+	}
+	xhtml, ok := input[0].(*dtpb.Xhtml)
+	if !ok {
+		return system.Collection{}, nil
+	}
+	return system.Collection{system.Boolean(checkNarrative(xhtml.GetValue()))}, nil
+}
+
+// checkNarrative reports whether the XHTML meets the rules of narratives.
+func checkNarrative(xhtml string) bool {
+	decoder := xml.NewDecoder(strings.NewReader(xhtml))
+	depth, roots := 0, 0
+	hasContent := false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return false
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+				if token.Name.Local != "div" {
+					return false
+				}
+			}
+			if !checkNarrativeElement(token) {
+				return false
+			}
+			if token.Name.Local == "img" {
+				hasContent = true
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if strings.TrimSpace(string(token)) == "" {
+				continue
+			}
+			if depth == 0 {
+				return false
+			}
+			hasContent = true
+		case xml.ProcInst, xml.Directive:
+			return false
+		}
+	}
+	return roots == 1 && hasContent
+}
+
+func checkNarrativeElement(element xml.StartElement) bool {
+	if element.Name.Space != xhtmlNamespace {
+		return false
+	}
+	allowed, ok := narrativeElements[element.Name.Local]
+	if !ok {
+		return false
+	}
+	for _, attr := range element.Attr {
+		switch {
+		case attr.Name.Space == "xmlns", attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			continue
+		case attr.Name.Space == "xml" || attr.Name.Space == "http://www.w3.org/XML/1998/namespace":
+			if attr.Name.Local != "lang" {
+				return false
+			}
+			continue
+		case attr.Name.Space != "":
+			return false
+		}
+		name := attr.Name.Local
+		if !includes(narrativeAttributes, name) && !includes(allowed, name) {
+			return false
+		}
+		if (name == "href" || name == "src") && strings.HasPrefix(strings.ToLower(strings.TrimSpace(attr.Value)), "javascript:") {
+			return false
+		}
+	}
+	return true
+}
+
+func includes(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package impl_test
+
+import (
+	"testing"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr/exprtest"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestHTMLChecks_Evaluates(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input system.Collection
+		want  system.Collection
+	}{
+		{
+			name:  "empty input",
+			input: system.Collection{},
+			want:  system.Collection{},
+		},
+		{
+			name:  "non-xhtml input",
+			input: system.Collection{fhir.String(`<div xmlns="http://www.w3.org/1999/xhtml">text</div>`)},
+			want:  system.Collection{},
+		},
+		{
+			name:  "simple narrative",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml">text</div>`),
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name: "formatted narrative",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml" xml:lang="en">
+				<h1 class="title">Patient</h1>
+				<table border="1"><tr><th scope="col">Name</th></tr><tr><td colspan="2">Doe</td></tr></table>
+				<p>See <a href="http://example.com">the record</a>.</p>
+			</div>`),
+			want: system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "narrative with only an image",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml"><img src="#image" alt=""/></div>`),
+			want:  system.Collection{system.Boolean(true)},
+		},
+		{
+			name:  "narrative with only whitespace",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml"> <p> </p> </div>`),
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "malformed xml",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml"><p>text</div>`),
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "root is not a div",
+			input: xhtml(`<p xmlns="http://www.w3.org/1999/xhtml">text</p>`),
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "multiple roots",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml">a</div><div xmlns="http://www.w3.org/1999/xhtml">b</div>`),
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "missing namespace",
+			input: xhtml(`<div>text</div>`),
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "script element",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml">text<script>alert(1)</script></div>`),
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "event handler attribute",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml"><p onclick="alert(1)">text</p></div>`),
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "javascript link",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml"><a href=" JavaScript:alert(1)">text</a></div>`),
+			want:  system.Collection{system.Boolean(false)},
+		},
+		{
+			name:  "attribute of another element",
+			input: xhtml(`<div xmlns="http://www.w3.org/1999/xhtml"><p href="http://example.com">text</p></div>`),
+			want:  system.Collection{system.Boolean(false)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := impl.HTMLChecks(&expr.Context{}, tc.input)
+			if err != nil {
+				t.Fatalf("HTMLChecks function returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("HTMLChecks function returned unexpected diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHTMLChecks_RaisesError(t *testing.T) {
+	div := &dtpb.Xhtml{Value: `<div xmlns="http://www.w3.org/1999/xhtml">text</div>`}
+	testCases := []struct {
+		name    string
+		input   system.Collection
+		args    []expr.Expression
+		wantErr error
+	}{
+		{
+			name:    "too many arguments",
+			input:   system.Collection{div},
+			args:    []expr.Expression{exprtest.Return(system.Boolean(true))},
+			wantErr: impl.ErrWrongArity,
+		},
+		{
+			name:    "multiple inputs",
+			input:   system.Collection{div, div},
+			wantErr: expr.ErrNotSingleton,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := impl.HTMLChecks(&expr.Context{}, tc.input, tc.args...)
+
+			if got, want := err, tc.wantErr; !cmp.Equal(got, want, cmpopts.EquateErrors()) {
+				t.Fatalf("HTMLChecks(%v): got err %v, want err %v", tc.name, got, want)
+			}
+		})
+	}
+}
+
+func xhtml(value string) system.Collection {
+	return system.Collection{&dtpb.Xhtml{Value: value}}
+}
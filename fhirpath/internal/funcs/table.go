@@ -1,6 +1,10 @@
 package funcs
 
-import "github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+import (
+	"math"
+
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs/impl"
+)
 
 // BaseTable holds the default mapping of all
 // FHIRPath functions. Unimplemented functions return an
@@ -20,12 +24,6 @@ var baseTable = FunctionTable{
 		1,
 		false,
 	},
-	"extension": Function{
-		impl.Extension,
-		1,
-		1,
-		false,
-	},
 	"all": Function{
 		impl.All,
 		1,
@@ -56,8 +54,18 @@ var baseTable = FunctionTable{
 		0,
 		false,
 	},
-	"subsetOf":   notImplemented,
-	"supersetOf": notImplemented,
+	"subsetOf": Function{
+		impl.SubsetOf,
+		1,
+		1,
+		false,
+	},
+	"supersetOf": Function{
+		impl.SupersetOf,
+		1,
+		1,
+		false,
+	},
 	"count": Function{
 		impl.Count,
 		0,
@@ -88,9 +96,42 @@ var baseTable = FunctionTable{
 		1,
 		false,
 	},
-	"repeat": notImplemented,
-	"ofType": notImplemented,
-	"single": notImplemented,
+	"repeat": Function{
+		impl.Repeat,
+		1,
+		1,
+		false,
+	},
+	"ofType": Function{
+		impl.OfType,
+		1,
+		1,
+		true,
+	},
+	"is": Function{
+		impl.Is,
+		1,
+		1,
+		true,
+	},
+	"as": Function{
+		impl.As,
+		1,
+		1,
+		true,
+	},
+	"type": Function{
+		impl.Type,
+		0,
+		0,
+		false,
+	},
+	"single": Function{
+		impl.Single,
+		0,
+		0,
+		false,
+	},
 	"first": Function{
 		impl.First,
 		0,
@@ -133,8 +174,18 @@ var baseTable = FunctionTable{
 		1,
 		false,
 	},
-	"union":   notImplemented,
-	"combine": notImplemented,
+	"union": Function{
+		impl.Union,
+		1,
+		1,
+		false,
+	},
+	"combine": Function{
+		impl.Combine,
+		1,
+		1,
+		false,
+	},
 	"iif": Function{
 		impl.Iif,
 		2,
@@ -297,6 +348,12 @@ var baseTable = FunctionTable{
 		2,
 		false,
 	},
+	"join": Function{
+		impl.Join,
+		0,
+		1,
+		false,
+	},
 	"length": Function{
 		impl.Length,
 		0,
@@ -381,7 +438,18 @@ var baseTable = FunctionTable{
 		0,
 		false,
 	},
-	"trace": notImplemented,
+	"trace": Function{
+		impl.Trace,
+		1,
+		2,
+		false,
+	},
+	"aggregate": Function{
+		impl.Aggregate,
+		1,
+		2,
+		false,
+	},
 	"now": Function{
 		impl.Now,
 		0,
@@ -408,26 +476,459 @@ var baseTable = FunctionTable{
 	},
 }
 
-// ExperimentalTable holds the mapping of all
-// experimental FHIRPath functions. These functions
-// are not a part of the N1 normative spec.
+// fhirTable holds the FHIR-specific functions that the
+// R4 specification adds to FHIRPath. These are enabled
+// by default, along with the base table.
+// See https://hl7.org/fhir/R4/fhirpath.html#functions
+var fhirTable = FunctionTable{
+	"extension": Function{
+		impl.Extension,
+		1,
+		1,
+		false,
+	},
+	"resolve": Function{
+		impl.Resolve,
+		0,
+		0,
+		false,
+	},
+	"conformsTo": Function{
+		impl.ConformsTo,
+		1,
+		1,
+		false,
+	},
+	"hasValue": Function{
+		impl.HasValue,
+		0,
+		0,
+		false,
+	},
+	"getValue": Function{
+		impl.GetValue,
+		0,
+		0,
+		false,
+	},
+	"htmlChecks": Function{
+		impl.HTMLChecks,
+		0,
+		0,
+		false,
+	},
+	"slice": Function{
+		impl.Slice,
+		2,
+		2,
+		false,
+	},
+	"checkModifiers": Function{
+		impl.CheckModifiers,
+		0,
+		1,
+		false,
+	},
+	"elementDefinition": Function{
+		impl.ElementDefinition,
+		0,
+		0,
+		false,
+	},
+	"memberOf": Function{
+		impl.MemberOf,
+		1,
+		1,
+		false,
+	},
+	"subsumes": Function{
+		impl.Subsumes,
+		1,
+		1,
+		false,
+	},
+	"subsumedBy": Function{
+		impl.SubsumedBy,
+		1,
+		1,
+		false,
+	},
+}
+
+// experimentalVariableTable holds the defineVariable
+// function introduced after the N1 normative spec. The
+// parser builds defineVariable itself, since the variable
+// is in scope for the rest of the invocation chain, so
+// its entry only enables the function.
+// See https://build.fhir.org/ig/HL7/FHIRPath/#definevariablename-string--expr-expression
+var experimentalVariableTable = FunctionTable{
+	"defineVariable": Function{
+		unimplemented,
+		1,
+		2,
+		false,
+	},
+}
+
+// experimentalSortTable holds the sort function
+// introduced after the N1 normative spec.
+// See https://build.fhir.org/ig/HL7/FHIRPath/#sortkeyselector-expression-collection
+var experimentalSortTable = FunctionTable{
+	"sort": Function{
+		impl.Sort,
+		0,
+		math.MaxInt,
+		false,
+	},
+}
+
+// experimentalAggregateTable holds the aggregate math
+// functions introduced after the N1 normative spec.
+// See https://build.fhir.org/ig/HL7/FHIRPath/#aggregates
+var experimentalAggregateTable = FunctionTable{
+	"sum": Function{
+		impl.Sum,
+		0,
+		0,
+		false,
+	},
+	"min": Function{
+		impl.Min,
+		0,
+		0,
+		false,
+	},
+	"max": Function{
+		impl.Max,
+		0,
+		0,
+		false,
+	},
+	"avg": Function{
+		impl.Avg,
+		0,
+		0,
+		false,
+	},
+}
+
+// experimentalPrecisionTable holds the precision and
+// boundary functions introduced after the N1 normative
+// spec.
+// See https://build.fhir.org/ig/HL7/FHIRPath/#utility-functions
+var experimentalPrecisionTable = FunctionTable{
+	"lowBoundary": Function{
+		impl.LowBoundary,
+		0,
+		1,
+		false,
+	},
+	"highBoundary": Function{
+		impl.HighBoundary,
+		0,
+		1,
+		false,
+	},
+	"precision": Function{
+		impl.Precision,
+		0,
+		0,
+		false,
+	},
+	"comparable": Function{
+		impl.Comparable,
+		1,
+		1,
+		false,
+	},
+}
+
+// experimentalDateTimeTable holds the date and time
+// component and arithmetic functions introduced after
+// the N1 normative spec.
+// See https://build.fhir.org/ig/HL7/FHIRPath/#datetime-functions
+var experimentalDateTimeTable = FunctionTable{
+	"yearOf": Function{
+		impl.YearOf,
+		0,
+		0,
+		false,
+	},
+	"monthOf": Function{
+		impl.MonthOf,
+		0,
+		0,
+		false,
+	},
+	"dayOf": Function{
+		impl.DayOf,
+		0,
+		0,
+		false,
+	},
+	"hourOf": Function{
+		impl.HourOf,
+		0,
+		0,
+		false,
+	},
+	"minuteOf": Function{
+		impl.MinuteOf,
+		0,
+		0,
+		false,
+	},
+	"secondOf": Function{
+		impl.SecondOf,
+		0,
+		0,
+		false,
+	},
+	"millisecondOf": Function{
+		impl.MillisecondOf,
+		0,
+		0,
+		false,
+	},
+	"timezoneOffsetOf": Function{
+		impl.TimezoneOffsetOf,
+		0,
+		0,
+		false,
+	},
+	"dateOf": Function{
+		impl.DateOf,
+		0,
+		0,
+		false,
+	},
+	"timeOf": Function{
+		impl.TimeOf,
+		0,
+		0,
+		false,
+	},
+	"duration": Function{
+		impl.Duration,
+		2,
+		2,
+		false,
+	},
+	"difference": Function{
+		impl.Difference,
+		2,
+		2,
+		false,
+	},
+}
+
+// experimentalStringTable holds the string functions
+// introduced after the N1 normative spec.
+// See https://build.fhir.org/ig/HL7/FHIRPath/#string-manipulation
+var experimentalStringTable = FunctionTable{
+	"trim": Function{
+		impl.Trim,
+		0,
+		0,
+		false,
+	},
+	"split": Function{
+		impl.Split,
+		1,
+		1,
+		false,
+	},
+	"lastIndexOf": Function{
+		impl.LastIndexOf,
+		1,
+		1,
+		false,
+	},
+	"matchesFull": Function{
+		impl.MatchesFull,
+		1,
+		1,
+		false,
+	},
+	"encode": Function{
+		impl.Encode,
+		1,
+		1,
+		false,
+	},
+	"decode": Function{
+		impl.Decode,
+		1,
+		1,
+		false,
+	},
+	"escape": Function{
+		impl.Escape,
+		1,
+		1,
+		false,
+	},
+	"unescape": Function{
+		impl.Unescape,
+		1,
+		1,
+		false,
+	},
+}
+
+// experimentalTables holds the groups of experimental
+// FHIRPath functions. These functions are not a part
+// of the N1 normative spec.
 // See https://build.fhir.org/ig/HL7/FHIRPath/
-var experimentalTable = FunctionTable{
-	"join": Function{
-		impl.Join,
+var experimentalTables = []FunctionTable{
+	experimentalStringTable,
+	experimentalVariableTable,
+	experimentalSortTable,
+	experimentalAggregateTable,
+	experimentalPrecisionTable,
+	experimentalDateTimeTable,
+}
+
+// terminologiesTable holds the methods of the %terminologies
+// constant, which wrap the FHIR terminology operations.
+// See https://hl7.org/fhir/R4/fhirpath.html#txapi
+var terminologiesTable = FunctionTable{
+	"expand": Function{
+		impl.TerminologiesExpand,
+		1,
+		2,
+		false,
+	},
+	"lookup": Function{
+		impl.TerminologiesLookup,
+		1,
+		2,
+		false,
+	},
+	"validateVS": Function{
+		impl.TerminologiesValidateVS,
+		2,
+		3,
+		false,
+	},
+	"validateCS": Function{
+		impl.TerminologiesValidateCS,
+		2,
+		3,
+		false,
+	},
+	"subsumes": Function{
+		impl.TerminologiesSubsumes,
+		3,
+		4,
+		false,
+	},
+	"translate": Function{
+		impl.TerminologiesTranslate,
+		2,
+		3,
+		false,
+	},
+}
+
+// serverTable holds the methods of the %server constant,
+// which query a FHIR server. The methods that a FHIRServer
+// doesn't provide raise an unsupported method error.
+// See https://build.fhir.org/fhirpath.html#serverapi
+var serverTable = FunctionTable{
+	"read": Function{
+		impl.ServerRead,
+		2,
+		2,
+		false,
+	},
+	"search": Function{
+		impl.ServerSearch,
+		2,
+		2,
+		false,
+	},
+	"validate": Function{
+		impl.ServerValidate,
+		2,
+		3,
+		false,
+	},
+	"create": Function{
+		impl.ServerUnsupported("create"),
+		1,
+		1,
+		false,
+	},
+	"update": Function{
+		impl.ServerUnsupported("update"),
+		1,
+		1,
+		false,
+	},
+	"delete": Function{
+		impl.ServerUnsupported("delete"),
+		1,
+		1,
+		false,
+	},
+	"patch": Function{
+		impl.ServerUnsupported("patch"),
+		1,
+		1,
+		false,
+	},
+	"capabilities": Function{
+		impl.ServerUnsupported("capabilities"),
 		0,
 		1,
 		false,
 	},
+	"transform": Function{
+		impl.ServerUnsupported("transform"),
+		2,
+		2,
+		false,
+	},
+	"everything": Function{
+		impl.ServerUnsupported("everything"),
+		2,
+		3,
+		false,
+	},
+	"apply": Function{
+		impl.ServerUnsupported("apply"),
+		2,
+		3,
+		false,
+	},
+}
+
+// methodTables maps the names of the external constants
+// that provide methods, such as %terminologies, to the
+// methods that they provide.
+var methodTables = map[string]FunctionTable{
+	"terminologies": terminologiesTable,
+	"server":        serverTable,
+}
+
+// Methods returns the table of methods provided by the
+// named external constant, if it provides any.
+func Methods(constant string) (FunctionTable, bool) {
+	table, ok := methodTables[constant]
+	return table, ok
 }
 
 // Clone returns a deep copy of the base
-// function table.
+// function table, including the FHIR-specific
+// functions.
 func Clone() FunctionTable {
 	// TODO(PHP-6173): Optimize
 	table := make(FunctionTable)
-	for k, v := range baseTable {
-		table[k] = v
+	for _, t := range []FunctionTable{baseTable, fhirTable} {
+		for k, v := range t {
+			table[k] = v
+		}
 	}
 	return table
 }
@@ -436,11 +937,13 @@ func Clone() FunctionTable {
 // to the given function table and returns it.
 // If a function already exists in the table, it is not overridden.
 func AddExperimentalFuncs(table FunctionTable) FunctionTable {
-	for k, v := range experimentalTable {
-		if _, exists := table[k]; exists {
-			continue
+	for _, experimental := range experimentalTables {
+		for k, v := range experimental {
+			if _, exists := table[k]; exists {
+				continue
+			}
+			table[k] = v
 		}
-		table[k] = v
 	}
 	return table
 }
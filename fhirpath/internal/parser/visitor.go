@@ -21,14 +21,34 @@ var (
 	errTooManyQualifiers  = errors.New("too many type qualifiers")
 	errVisitingChildren   = errors.New("error while visiting child expressions")
 	errUnresolvedFunction = errors.New("function identifier can't be resolved")
+	errVariableDefined    = errors.New("variable already defined")
+	errVariableUndefined  = errors.New("variable used before it is defined")
+	errInvalidVariable    = errors.New("variable name must be a string literal")
+	errInvalidType        = errors.New("argument is not a type specifier")
 )
 
+// defineVariable is the name of the function that binds expression-scoped
+// variables. It is handled by the visitor rather than the function table,
+// since the variables it defines are visible to the rest of the invocation chain.
+// It is only available if the function table enables it.
+const defineVariable = "defineVariable"
+
+// reservedVariables are the environment variables that are always defined, and
+// so can't be redefined by an expression.
+var reservedVariables = []string{"context", "ucum", "resource", "rootResource"}
+
 type FHIRPathVisitor struct {
 	*grammar.BasefhirpathVisitor
 	visitedRoot bool
 	Functions   funcs.FunctionTable
 	Transform   VisitorTransform
 	Permissive  bool
+
+	// variables are the names bound by defineVariable() that are in scope for
+	// the expression being visited, and declared are the names bound anywhere in
+	// the expression. Both maps are replaced rather than modified in place.
+	variables map[string]bool
+	declared  map[string]bool
 }
 
 type VisitResult struct {
@@ -48,6 +68,8 @@ func (v *FHIRPathVisitor) clone() *FHIRPathVisitor {
 		Transform:   v.Transform,
 		Permissive:  v.Permissive,
 		visitedRoot: false,
+		variables:   v.variables,
+		declared:    v.declared,
 	}
 }
 
@@ -59,13 +81,51 @@ func (v *FHIRPathVisitor) transformedVisitResult(resultExpr expr.Expression) *Vi
 }
 
 func (v *FHIRPathVisitor) Visit(tree antlr.ParseTree) interface{} {
+	// Variables only stay in scope along an invocation chain, mirroring how
+	// expression sequences pass them along during evaluation.
+	if !isInvocationChain(tree) || !isInvocationChain(tree.GetParent()) {
+		variables := v.variables
+		defer func() { v.variables = variables }()
+	}
 	return tree.Accept(v)
 }
 
+// isInvocationChain returns true if the node is part of a chain of invocations,
+// which is evaluated as an expression sequence.
+func isInvocationChain(tree antlr.Tree) bool {
+	switch tree.(type) {
+	case *grammar.InvocationExpressionContext, *grammar.TermExpressionContext,
+		*grammar.InvocationTermContext, *grammar.ParenthesizedTermContext,
+		*grammar.FunctionInvocationContext, *grammar.FunctionContext:
+		return true
+	}
+	return false
+}
+
 func (v *FHIRPathVisitor) VisitProg(ctx *grammar.ProgContext) interface{} {
+	v.declared = declaredVariables(ctx)
 	return v.Visit(ctx.Expression()).(*VisitResult)
 }
 
+// declaredVariables collects the names of all variables defined in the parse
+// tree, to be able to detect variables that are used before their definition.
+func declaredVariables(tree antlr.Tree) map[string]bool {
+	declared := map[string]bool{}
+	if fn, ok := tree.(*grammar.FunctionContext); ok && fn.Identifier().GetText() == defineVariable {
+		if params := fn.ParamList(); params != nil && len(params.AllExpression()) > 0 {
+			if name, err := system.ParseString(params.Expression(0).GetText()); err == nil {
+				declared[string(name)] = true
+			}
+		}
+	}
+	for _, child := range tree.GetChildren() {
+		for name := range declaredVariables(child) {
+			declared[name] = true
+		}
+	}
+	return declared
+}
+
 // VisitIndexerExpression visits both the left side expression and right side expression, and
 // constructs an index expression. If the right side expression does not evaluate to an Integer,
 // returns an error
@@ -157,7 +217,17 @@ func (v *FHIRPathVisitor) VisitMultiplicativeExpression(ctx *grammar.Multiplicat
 }
 
 func (v *FHIRPathVisitor) VisitUnionExpression(ctx *grammar.UnionExpressionContext) interface{} {
-	return &VisitResult{nil, errNotSupported}
+	leftResult := v.Visit(ctx.Expression(0)).(*VisitResult)
+	if leftResult.Error != nil {
+		return &VisitResult{nil, leftResult.Error}
+	}
+	rightResult := v.clone().Visit(ctx.Expression(1)).(*VisitResult)
+	if rightResult.Error != nil {
+		return &VisitResult{nil, rightResult.Error}
+	}
+
+	expression := &expr.UnionExpression{Left: leftResult.Result, Right: rightResult.Result}
+	return v.transformedVisitResult(expression)
 }
 
 func (v *FHIRPathVisitor) VisitOrExpression(ctx *grammar.OrExpressionContext) interface{} {
@@ -191,7 +261,19 @@ func (v *FHIRPathVisitor) VisitAndExpression(ctx *grammar.AndExpressionContext)
 }
 
 func (v *FHIRPathVisitor) VisitMembershipExpression(ctx *grammar.MembershipExpressionContext) interface{} {
-	return &VisitResult{nil, errNotSupported}
+	leftResult := v.Visit(ctx.Expression(0)).(*VisitResult)
+	if leftResult.Error != nil {
+		return &VisitResult{nil, leftResult.Error}
+	}
+	rightResult := v.clone().Visit(ctx.Expression(1)).(*VisitResult)
+	if rightResult.Error != nil {
+		return &VisitResult{nil, rightResult.Error}
+	}
+
+	operator := expr.Operator(ctx.GetChild(1).(antlr.TerminalNode).GetText())
+
+	expression := &expr.MembershipExpression{Left: leftResult.Result, Right: rightResult.Result, Op: operator}
+	return v.transformedVisitResult(expression)
 }
 
 func (v *FHIRPathVisitor) VisitInequalityExpression(ctx *grammar.InequalityExpressionContext) interface{} {
@@ -211,7 +293,15 @@ func (v *FHIRPathVisitor) VisitInequalityExpression(ctx *grammar.InequalityExpre
 }
 
 // VisitInvocationExpression visits both sides, and constructs an expression sequence.
+// Invoking a method of an external constant, such as %terminologies.expand(), instead
+// constructs a single function expression, which is evaluated against the current focus.
 func (v *FHIRPathVisitor) VisitInvocationExpression(ctx *grammar.InvocationExpressionContext) interface{} {
+	if methods, ok := v.methodsOf(ctx.Expression()); ok {
+		if invocation, ok := ctx.Invocation().(*grammar.FunctionInvocationContext); ok {
+			return v.visitMethod(methods, invocation.Function())
+		}
+	}
+
 	// Visit left side with new visitor, raising error if necessary
 	leftResult := v.Visit(ctx.Expression()).(*VisitResult)
 	if leftResult.Error != nil {
@@ -250,9 +340,9 @@ func (v *FHIRPathVisitor) VisitEqualityExpression(ctx *grammar.EqualityExpressio
 	case expr.NotEquals:
 		expression = &expr.EqualityExpression{Left: leftResult.Result, Right: rightResult.Result, Not: true}
 	case expr.Equivalence:
-		// TODO (PHP-5889): Implement equivalence expressions
+		expression = &expr.EquivalenceExpression{Left: leftResult.Result, Right: rightResult.Result}
 	case expr.Inequivalence:
-		// TODO (PHP-5889): Implement non-equivalence expressions
+		expression = &expr.EquivalenceExpression{Left: leftResult.Result, Right: rightResult.Result, Not: true}
 	}
 	return v.transformedVisitResult(expression)
 }
@@ -304,8 +394,13 @@ func (v *FHIRPathVisitor) VisitLiteralTerm(ctx *grammar.LiteralTermContext) inte
 }
 
 func (v *FHIRPathVisitor) VisitExternalConstantTerm(ctx *grammar.ExternalConstantTermContext) interface{} {
-	ident := ctx.ExternalConstant().GetText()
-	ident = strings.TrimPrefix(ident, "%")
+	ident, err := unquoteIdentifier(strings.TrimPrefix(ctx.ExternalConstant().GetText(), "%"))
+	if err != nil {
+		return &VisitResult{nil, err}
+	}
+	if v.declared[ident] && !v.variables[ident] {
+		return &VisitResult{nil, fmt.Errorf("%w: %s", errVariableUndefined, ident)}
+	}
 	return v.transformedVisitResult(&expr.ExternalConstantExpression{Identifier: ident})
 }
 
@@ -420,7 +515,10 @@ func (v *FHIRPathVisitor) VisitExternalConstant(ctx *grammar.ExternalConstantCon
 // VisitMemberInvocation checks to see if the identifier corresponds to a resource type and is the
 // root of the expression. If so, it will return a TypeExpression. Otherwise, it returns a FieldExpression.
 func (v *FHIRPathVisitor) VisitMemberInvocation(ctx *grammar.MemberInvocationContext) interface{} {
-	identifier := ctx.GetText()
+	identifier, err := unquoteIdentifier(ctx.GetText())
+	if err != nil {
+		return &VisitResult{nil, err}
+	}
 	var expression expr.Expression
 
 	if resource.IsType(identifier) && !v.visitedRoot {
@@ -433,6 +531,24 @@ func (v *FHIRPathVisitor) VisitMemberInvocation(ctx *grammar.MemberInvocationCon
 	return v.transformedVisitResult(expression)
 }
 
+// unquoteIdentifier returns the name of an identifier. Delimited identifiers,
+// such as `div`, may be used for names that are otherwise keywords, and
+// external constants may also be named by strings, such as %'us-zip'. Both
+// have their delimiters removed and their escape sequences replaced.
+func unquoteIdentifier(identifier string) (string, error) {
+	if len(identifier) < 2 {
+		return identifier, nil
+	}
+	inner := identifier[1 : len(identifier)-1]
+	for _, delimiter := range []byte{'`', '\''} {
+		if identifier[0] == delimiter && identifier[len(identifier)-1] == delimiter {
+			name, err := system.ParseString("'" + inner + "'")
+			return string(name), err
+		}
+	}
+	return identifier, nil
+}
+
 func (v *FHIRPathVisitor) VisitFunctionInvocation(ctx *grammar.FunctionInvocationContext) interface{} {
 	return v.Visit(ctx.Function())
 }
@@ -442,11 +558,11 @@ func (v *FHIRPathVisitor) VisitThisInvocation(ctx *grammar.ThisInvocationContext
 }
 
 func (v *FHIRPathVisitor) VisitIndexInvocation(ctx *grammar.IndexInvocationContext) interface{} {
-	return &VisitResult{nil, errNotSupported}
+	return &VisitResult{&expr.IndexVariableExpression{}, nil}
 }
 
 func (v *FHIRPathVisitor) VisitTotalInvocation(ctx *grammar.TotalInvocationContext) interface{} {
-	return &VisitResult{nil, errNotSupported}
+	return &VisitResult{&expr.TotalVariableExpression{}, nil}
 }
 
 func (v *FHIRPathVisitor) VisitFunction(ctx *grammar.FunctionContext) interface{} {
@@ -455,10 +571,51 @@ func (v *FHIRPathVisitor) VisitFunction(ctx *grammar.FunctionContext) interface{
 	if !ok {
 		return &VisitResult{nil, fmt.Errorf("%w: %s", errUnresolvedFunction, ident)}
 	}
+	if ident == defineVariable {
+		return v.visitDefineVariable(ctx.ParamList())
+	}
 
+	if fn.IsTypeFunction {
+		return v.visitTypeFunction(fn, ctx.ParamList())
+	}
+	return v.visitFunctionCall(fn, ctx.ParamList())
+}
+
+// methodsOf returns the methods provided by the expression, if it is an
+// external constant that provides methods and isn't shadowed by a variable.
+func (v *FHIRPathVisitor) methodsOf(ctx grammar.IExpressionContext) (funcs.FunctionTable, bool) {
+	term, ok := ctx.(*grammar.TermExpressionContext)
+	if !ok {
+		return nil, false
+	}
+	constant, ok := term.Term().(*grammar.ExternalConstantTermContext)
+	if !ok {
+		return nil, false
+	}
+	ident := strings.TrimPrefix(constant.ExternalConstant().GetText(), "%")
+	if v.declared[ident] {
+		return nil, false
+	}
+	return funcs.Methods(ident)
+}
+
+// visitMethod builds the function expression for a method of an external
+// constant, resolved from the given methods rather than the function table.
+func (v *FHIRPathVisitor) visitMethod(methods funcs.FunctionTable, ctx grammar.IFunctionContext) *VisitResult {
+	ident := ctx.Identifier().GetText()
+	fn, ok := methods[ident]
+	if !ok {
+		return &VisitResult{nil, fmt.Errorf("%w: %s", errUnresolvedFunction, ident)}
+	}
+	return v.visitFunctionCall(fn, ctx.ParamList())
+}
+
+// visitFunctionCall builds a function expression whose arguments are the
+// expressions of the parameter list.
+func (v *FHIRPathVisitor) visitFunctionCall(fn funcs.Function, params grammar.IParamListContext) *VisitResult {
 	results := []*VisitResult{}
-	if args := ctx.ParamList(); args != nil {
-		results = v.Visit(args).([]*VisitResult)
+	if params != nil {
+		results = v.Visit(params).([]*VisitResult)
 	}
 
 	errs := slices.Map(results, func(r *VisitResult) error { return r.Error })
@@ -473,6 +630,112 @@ func (v *FHIRPathVisitor) VisitFunction(ctx *grammar.FunctionContext) interface{
 	return v.transformedVisitResult(&expr.FunctionExpression{Fn: fn.Func, Args: expressions})
 }
 
+// visitTypeFunction builds a function expression whose arguments are type
+// specifiers rather than expressions, such as the argument of ofType(). The
+// type is resolved at compile time so that unknown types are rejected early.
+func (v *FHIRPathVisitor) visitTypeFunction(fn funcs.Function, params grammar.IParamListContext) *VisitResult {
+	args := []grammar.IExpressionContext{}
+	if params != nil {
+		args = params.AllExpression()
+	}
+	if len(args) < fn.MinArity || len(args) > fn.MaxArity {
+		return &VisitResult{nil, fmt.Errorf("%w: input arity outside of function arity bounds", impl.ErrWrongArity)}
+	}
+	expressions := []expr.Expression{}
+	for _, arg := range args {
+		identifiers, err := typeIdentifiers(arg)
+		if err != nil {
+			return &VisitResult{nil, fmt.Errorf("%w: %w", errVisitingChildren, err)}
+		}
+		specifier := newTypeResult(identifiers)
+		if specifier.err != nil {
+			return &VisitResult{nil, fmt.Errorf("%w: %w", errVisitingChildren, specifier.err)}
+		}
+		expressions = append(expressions, &expr.TypeSpecifierExpression{Type: specifier.result})
+	}
+	return v.transformedVisitResult(&expr.FunctionExpression{Fn: fn.Func, Args: expressions})
+}
+
+// typeIdentifiers returns the unquoted identifiers of a type specifier. Those
+// of the is and as operators are qualified identifiers, but the grammar parses
+// the arguments of type functions as expressions, so that a qualified type such
+// as FHIR.Patient is an invocation of the member Patient on the term FHIR.
+func typeIdentifiers(tree antlr.Tree) ([]string, error) {
+	switch ctx := tree.(type) {
+	case *grammar.TermExpressionContext:
+		return typeIdentifiers(ctx.Term())
+	case *grammar.InvocationTermContext:
+		return typeIdentifiers(ctx.Invocation())
+	case *grammar.InvocationExpressionContext:
+		qualifiers, err := typeIdentifiers(ctx.Expression())
+		if err != nil {
+			return nil, err
+		}
+		identifiers, err := typeIdentifiers(ctx.Invocation())
+		if err != nil {
+			return nil, err
+		}
+		return append(qualifiers, identifiers...), nil
+	case *grammar.MemberInvocationContext:
+		identifier, err := unquoteIdentifier(ctx.Identifier().GetText())
+		if err != nil {
+			return nil, err
+		}
+		return []string{identifier}, nil
+	case *grammar.QualifiedIdentifierContext:
+		var identifiers []string
+		for _, identifier := range ctx.AllIdentifier() {
+			name, err := unquoteIdentifier(identifier.GetText())
+			if err != nil {
+				return nil, err
+			}
+			identifiers = append(identifiers, name)
+		}
+		return identifiers, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errInvalidType, tree.(antlr.ParseTree).GetText())
+	}
+}
+
+// visitDefineVariable builds the expression for defineVariable(name [, expr]),
+// and brings the variable into scope for the rest of the invocation chain.
+func (v *FHIRPathVisitor) visitDefineVariable(params grammar.IParamListContext) *VisitResult {
+	results := []*VisitResult{}
+	if params != nil {
+		results = v.Visit(params).([]*VisitResult)
+	}
+	if len(results) < 1 || len(results) > 2 {
+		return &VisitResult{nil, fmt.Errorf("%w: input arity outside of function arity bounds", impl.ErrWrongArity)}
+	}
+	errs := slices.Map(results, func(r *VisitResult) error { return r.Error })
+	if err := errors.Join(errs...); err != nil {
+		return &VisitResult{nil, fmt.Errorf("%w: %w", errVisitingChildren, err)}
+	}
+
+	literal, ok := results[0].Result.(*expr.LiteralExpression)
+	if !ok {
+		return &VisitResult{nil, errInvalidVariable}
+	}
+	name, ok := literal.Literal.(system.String)
+	if !ok {
+		return &VisitResult{nil, errInvalidVariable}
+	}
+	if v.variables[string(name)] || slices.Includes(reservedVariables, string(name)) {
+		return &VisitResult{nil, fmt.Errorf("%w: %s", errVariableDefined, name)}
+	}
+
+	expression := &expr.DefineVariableExpression{Name: string(name)}
+	if len(results) == 2 {
+		expression.Value = results[1].Result
+	}
+	variables := map[string]bool{string(name): true}
+	for k := range v.variables {
+		variables[k] = true
+	}
+	v.variables = variables
+	return v.transformedVisitResult(expression)
+}
+
 func (v *FHIRPathVisitor) VisitParamList(ctx *grammar.ParamListContext) interface{} {
 	return slices.Map(ctx.AllExpression(), func(e grammar.IExpressionContext) *VisitResult { return v.Visit(e).(*VisitResult) })
 }
@@ -494,7 +757,16 @@ func (v *FHIRPathVisitor) VisitPluralDateTimePrecision(ctx *grammar.PluralDateTi
 }
 
 func (v *FHIRPathVisitor) VisitTypeSpecifier(ctx *grammar.TypeSpecifierContext) interface{} {
-	identifiers := v.Visit(ctx.QualifiedIdentifier()).([]string)
+	identifiers, err := typeIdentifiers(ctx.QualifiedIdentifier())
+	if err != nil {
+		return &typeResult{err: err}
+	}
+	return newTypeResult(identifiers)
+}
+
+// newTypeResult resolves a type specifier from its (optionally namespaced)
+// identifiers.
+func newTypeResult(identifiers []string) *typeResult {
 	if len(identifiers) == 1 {
 		specifier, err := reflection.NewTypeSpecifier(identifiers[0])
 		return &typeResult{specifier, err}
@@ -506,10 +778,6 @@ func (v *FHIRPathVisitor) VisitTypeSpecifier(ctx *grammar.TypeSpecifierContext)
 	return &typeResult{err: fmt.Errorf("%w: %s", errTooManyQualifiers, strings.Join(identifiers, ","))}
 }
 
-func (v *FHIRPathVisitor) VisitQualifiedIdentifier(ctx *grammar.QualifiedIdentifierContext) interface{} {
-	return slices.Map(ctx.AllIdentifier(), func(i grammar.IIdentifierContext) string { return i.GetText() })
-}
-
 func (v *FHIRPathVisitor) VisitIdentifier(ctx *grammar.IdentifierContext) interface{} {
 	return &VisitResult{nil, errNotSupported}
 }
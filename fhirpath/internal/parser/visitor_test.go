@@ -0,0 +1,165 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/compile"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/expr"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/funcs"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/parser"
+)
+
+func visit(t *testing.T, path string) *parser.VisitResult {
+	t.Helper()
+	tree, err := compile.Tree(path)
+	if err != nil {
+		t.Fatalf("compile.Tree(%s) returned unexpected error: %v", path, err)
+	}
+	visitor := &parser.FHIRPathVisitor{Functions: funcs.Clone()}
+	return visitor.Visit(tree).(*parser.VisitResult)
+}
+
+func TestVisitMemberInvocation_DelimitedIdentifier(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+		want expr.Expression
+	}{
+		{
+			name: "field",
+			path: "`given`",
+			want: &expr.FieldExpression{FieldName: "given"},
+		},
+		{
+			name: "keyword as field",
+			path: "`div`",
+			want: &expr.FieldExpression{FieldName: "div"},
+		},
+		{
+			name: "resource type",
+			path: "`Patient`.`name`",
+			want: &expr.ExpressionSequence{
+				Expressions: []expr.Expression{
+					&expr.TypeExpression{Type: "Patient"},
+					&expr.FieldExpression{FieldName: "name"},
+				},
+			},
+		},
+		{
+			name: "escaped delimiter",
+			path: "`a\\`b`",
+			want: &expr.FieldExpression{FieldName: "a`b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := visit(t, tc.path)
+
+			if got.Error != nil {
+				t.Fatalf("Visit(%s) returned unexpected error: %v", tc.path, got.Error)
+			}
+			if diff := cmp.Diff(tc.want, got.Result); diff != "" {
+				t.Errorf("Visit(%s) returned unexpected diff (-want, +got):\n%s", tc.path, diff)
+			}
+		})
+	}
+}
+
+func TestVisitExternalConstantTerm_QuotedIdentifier(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"identifier", "%resource", "resource"},
+		{"delimited identifier", "%`us-zip`", "us-zip"},
+		{"string", "%'us-zip'", "us-zip"},
+		{"string with escape sequence", "%'vs-\\'zip\\''", "vs-'zip'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := visit(t, tc.path)
+
+			if got.Error != nil {
+				t.Fatalf("Visit(%s) returned unexpected error: %v", tc.path, got.Error)
+			}
+			want := &expr.ExternalConstantExpression{Identifier: tc.want}
+			if diff := cmp.Diff(want, got.Result); diff != "" {
+				t.Errorf("Visit(%s) returned unexpected diff (-want, +got):\n%s", tc.path, diff)
+			}
+		})
+	}
+}
+
+func TestVisitTypeFunction_DelimitedIdentifier(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"type", "ofType(Patient)", "FHIR.Patient"},
+		{"qualified type", "ofType(FHIR.Patient)", "FHIR.Patient"},
+		{"delimited type", "ofType(`Patient`)", "FHIR.Patient"},
+		{"delimited qualified type", "ofType(`System`.`Integer`)", "System.Integer"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := visit(t, tc.path)
+
+			if got.Error != nil {
+				t.Fatalf("Visit(%s) returned unexpected error: %v", tc.path, got.Error)
+			}
+			fn, ok := got.Result.(*expr.FunctionExpression)
+			if !ok || len(fn.Args) != 1 {
+				t.Fatalf("Visit(%s) returned %#v, want a function with one argument", tc.path, got.Result)
+			}
+			specifier, ok := fn.Args[0].(*expr.TypeSpecifierExpression)
+			if !ok {
+				t.Fatalf("Visit(%s) returned argument %#v, want a type specifier", tc.path, fn.Args[0])
+			}
+			if specifier.Type.String() != tc.want {
+				t.Errorf("Visit(%s) returned type %v, want %v", tc.path, specifier.Type, tc.want)
+			}
+		})
+	}
+}
+
+func TestVisitTypeExpression_DelimitedIdentifier(t *testing.T) {
+	path := "1 is `System`.`Integer`"
+
+	got := visit(t, path)
+
+	if got.Error != nil {
+		t.Fatalf("Visit(%s) returned unexpected error: %v", path, got.Error)
+	}
+	is, ok := got.Result.(*expr.IsExpression)
+	if !ok {
+		t.Fatalf("Visit(%s) returned %#v, want an is expression", path, got.Result)
+	}
+	if got, want := is.Type.String(), "System.Integer"; got != want {
+		t.Errorf("Visit(%s) returned type %v, want %v", path, got, want)
+	}
+}
+
+func TestVisitTypeFunction_RaisesError(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+	}{
+		{"delimited identifier containing a dot", "ofType(`FHIR.Patient`)"},
+		{"literal argument", "ofType(1)"},
+		{"too many qualifiers", "ofType(FHIR.Patient.name)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := visit(t, tc.path); got.Error == nil {
+				t.Errorf("Visit(%s) didn't return error when expected", tc.path)
+			}
+		})
+	}
+}
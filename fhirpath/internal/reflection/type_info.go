@@ -0,0 +1,192 @@
+package reflection
+
+import (
+	"fmt"
+
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/protofields"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// TypeInfo is implemented by the values of the FHIRPath reflection model, which
+// are returned by the type() function. The properties of these values can be
+// navigated like the fields of any other FHIRPath value.
+type TypeInfo interface {
+	// Field returns the value of the named property, and whether or not the
+	// property exists.
+	Field(name string) (system.Collection, bool)
+}
+
+// SimpleTypeInfo describes primitive types, such as System.Integer or
+// FHIR.string.
+type SimpleTypeInfo struct {
+	Namespace string
+	Name      string
+	BaseType  string
+}
+
+// Field returns the value of the named SimpleTypeInfo property.
+func (i SimpleTypeInfo) Field(name string) (system.Collection, bool) {
+	switch name {
+	case "namespace":
+		return system.Collection{system.String(i.Namespace)}, true
+	case "name":
+		return system.Collection{system.String(i.Name)}, true
+	case "baseType":
+		return system.Collection{system.String(i.BaseType)}, true
+	}
+	return nil, false
+}
+
+// ClassInfo describes FHIR complex types and resources, along with the elements
+// that they contain.
+type ClassInfo struct {
+	Namespace string
+	Name      string
+	BaseType  string
+	Element   []ClassInfoElement
+}
+
+// Field returns the value of the named ClassInfo property.
+func (i ClassInfo) Field(name string) (system.Collection, bool) {
+	switch name {
+	case "namespace":
+		return system.Collection{system.String(i.Namespace)}, true
+	case "name":
+		return system.Collection{system.String(i.Name)}, true
+	case "baseType":
+		return system.Collection{system.String(i.BaseType)}, true
+	case "element":
+		result := system.Collection{}
+		for _, element := range i.Element {
+			result = append(result, element)
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// ClassInfoElement describes a single element of a ClassInfo.
+type ClassInfoElement struct {
+	Name       string
+	Type       string
+	IsOneBased bool
+}
+
+// Field returns the value of the named ClassInfoElement property.
+func (e ClassInfoElement) Field(name string) (system.Collection, bool) {
+	switch name {
+	case "name":
+		return system.Collection{system.String(e.Name)}, true
+	case "type":
+		return system.Collection{system.String(e.Type)}, true
+	case "isOneBased":
+		return system.Collection{system.Boolean(e.IsOneBased)}, true
+	}
+	return nil, false
+}
+
+// ListTypeInfo describes a collection of values, all of which are of the
+// element type.
+type ListTypeInfo struct {
+	ElementType string
+}
+
+// Field returns the value of the named ListTypeInfo property.
+func (i ListTypeInfo) Field(name string) (system.Collection, bool) {
+	if name == "elementType" {
+		return system.Collection{system.String(i.ElementType)}, true
+	}
+	return nil, false
+}
+
+var (
+	_ TypeInfo = SimpleTypeInfo{}
+	_ TypeInfo = ClassInfo{}
+	_ TypeInfo = ClassInfoElement{}
+	_ TypeInfo = ListTypeInfo{}
+)
+
+// TypeInfoOf returns the reflection model of the input, which must be a
+// supported FHIRPath type. System types and FHIR primitives are described by a
+// SimpleTypeInfo, and other FHIR types by a ClassInfo.
+func TypeInfoOf(input any) (TypeInfo, error) {
+	ts, err := TypeOf(input)
+	if err != nil {
+		return nil, err
+	}
+	if ts.namespace == System || isPrimitive(ts.typeName) {
+		return SimpleTypeInfo{
+			Namespace: ts.namespace,
+			Name:      ts.typeName,
+			BaseType:  ts.baseType().String(),
+		}, nil
+	}
+	message := input.(fhir.Base)
+	if oneOf := protofields.UnwrapOneofField(message, "choice"); oneOf != nil {
+		message = oneOf
+	}
+	return ClassInfo{
+		Namespace: ts.namespace,
+		Name:      ts.typeName,
+		BaseType:  ts.baseType().String(),
+		Element:   classElements(message.ProtoReflect().Descriptor()),
+	}, nil
+}
+
+// String returns the qualified name of the type, such as "FHIR.Patient".
+func (ts TypeSpecifier) String() string {
+	return fmt.Sprintf("%s.%s", ts.namespace, ts.typeName)
+}
+
+// baseType returns the type that the receiver derives from. Every root type
+// derives from System.Any.
+func (ts TypeSpecifier) baseType() TypeSpecifier {
+	if parent := ts.parent(); parent != ts {
+		return parent
+	}
+	return TypeSpecifier{System, "Any"}
+}
+
+// classElements describes the fields of a FHIR complex type or resource. Fields
+// that are members of a oneof, such as the typed IDs of a Reference, are
+// implementation details of the protos and are omitted.
+func classElements(descriptor protoreflect.MessageDescriptor) []ClassInfoElement {
+	var elements []ClassInfoElement
+	fields := descriptor.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.ContainingOneof() != nil || field.Kind() != protoreflect.MessageKind {
+			continue
+		}
+		typeName := elementTypeName(field)
+		if field.IsList() {
+			typeName = fmt.Sprintf("List<%s>", typeName)
+		}
+		elements = append(elements, ClassInfoElement{
+			Name: field.JSONName(),
+			Type: typeName,
+		})
+	}
+	return elements
+}
+
+func elementTypeName(field protoreflect.FieldDescriptor) string {
+	message := field.Message()
+	switch message.FullName() {
+	case (&bcrpb.ContainedResource{}).ProtoReflect().Descriptor().FullName(),
+		(&anypb.Any{}).ProtoReflect().Descriptor().FullName():
+		return TypeSpecifier{FHIR, "Resource"}.String()
+	}
+	if message.Oneofs().ByName("choice") != nil {
+		return TypeSpecifier{FHIR, "Element"}.String()
+	}
+	if protofields.IsCodeField(dynamicpb.NewMessage(message)) {
+		return TypeSpecifier{FHIR, "code"}.String()
+	}
+	return TypeSpecifier{FHIR, primitiveToLowercase(string(message.Name()))}.String()
+}
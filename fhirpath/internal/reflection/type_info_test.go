@@ -0,0 +1,167 @@
+package reflection_test
+
+import (
+	"testing"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/reflection"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+)
+
+func TestTypeInfoOf_SimpleType(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input any
+		want  reflection.TypeInfo
+	}{
+		{
+			name:  "system type",
+			input: system.Integer(1),
+			want:  reflection.SimpleTypeInfo{Namespace: "System", Name: "Integer", BaseType: "System.Any"},
+		},
+		{
+			name:  "FHIR primitive",
+			input: fhir.Boolean(true),
+			want:  reflection.SimpleTypeInfo{Namespace: "FHIR", Name: "boolean", BaseType: "FHIR.Element"},
+		},
+		{
+			name:  "FHIR primitive subtype",
+			input: fhir.Markdown("# title"),
+			want:  reflection.SimpleTypeInfo{Namespace: "FHIR", Name: "markdown", BaseType: "FHIR.string"},
+		},
+		{
+			name:  "FHIR code",
+			input: &ppb.Patient_GenderCode{},
+			want:  reflection.SimpleTypeInfo{Namespace: "FHIR", Name: "code", BaseType: "FHIR.string"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := reflection.TypeInfoOf(tc.input)
+			if err != nil {
+				t.Fatalf("TypeInfoOf(%v) returned unexpected error: %v", tc.input, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("TypeInfoOf(%v) returned unexpected diff (-want, +got):\n%s", tc.input, diff)
+			}
+		})
+	}
+}
+
+func TestTypeInfoOf_ClassInfo(t *testing.T) {
+	got, err := reflection.TypeInfoOf(&dtpb.Period{})
+	if err != nil {
+		t.Fatalf("TypeInfoOf(Period) returned unexpected error: %v", err)
+	}
+	want := reflection.ClassInfo{
+		Namespace: "FHIR",
+		Name:      "Period",
+		BaseType:  "FHIR.Element",
+		Element: []reflection.ClassInfoElement{
+			{Name: "id", Type: "FHIR.string"},
+			{Name: "extension", Type: "List<FHIR.Extension>"},
+			{Name: "start", Type: "FHIR.dateTime"},
+			{Name: "end", Type: "FHIR.dateTime"},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TypeInfoOf(Period) returned unexpected diff (-want, +got):\n%s", diff)
+	}
+}
+
+func TestTypeInfoOf_Resource(t *testing.T) {
+	got, err := reflection.TypeInfoOf(&ppb.Patient{})
+	if err != nil {
+		t.Fatalf("TypeInfoOf(Patient) returned unexpected error: %v", err)
+	}
+	info, ok := got.(reflection.ClassInfo)
+	if !ok {
+		t.Fatalf("TypeInfoOf(Patient) returned %T, want ClassInfo", got)
+	}
+	if got, want := info.BaseType, "FHIR.DomainResource"; got != want {
+		t.Errorf("TypeInfoOf(Patient) base type: got %v, want %v", got, want)
+	}
+	wantElements := map[string]string{
+		"contained": "List<FHIR.Resource>",
+		"name":      "List<FHIR.HumanName>",
+		"gender":    "FHIR.code",
+		"deceased":  "FHIR.Element",
+	}
+	for _, element := range info.Element {
+		if want, ok := wantElements[element.Name]; ok && element.Type != want {
+			t.Errorf("TypeInfoOf(Patient) element %v: got type %v, want %v", element.Name, element.Type, want)
+		}
+		delete(wantElements, element.Name)
+	}
+	if len(wantElements) != 0 {
+		t.Errorf("TypeInfoOf(Patient) is missing elements %v", wantElements)
+	}
+}
+
+func TestTypeInfoOf_InvalidInput_RaisesError(t *testing.T) {
+	if _, err := reflection.TypeInfoOf(1); err == nil {
+		t.Errorf("TypeInfoOf(1) didn't return error when expected")
+	}
+}
+
+func TestTypeInfo_Field(t *testing.T) {
+	element := reflection.ClassInfoElement{Name: "start", Type: "FHIR.dateTime"}
+	testCases := []struct {
+		name   string
+		info   reflection.TypeInfo
+		field  string
+		want   system.Collection
+		wantOK bool
+	}{
+		{
+			name:   "simple type name",
+			info:   reflection.SimpleTypeInfo{Namespace: "System", Name: "String", BaseType: "System.Any"},
+			field:  "name",
+			want:   system.Collection{system.String("String")},
+			wantOK: true,
+		},
+		{
+			name:   "class info elements",
+			info:   reflection.ClassInfo{Namespace: "FHIR", Name: "Period", Element: []reflection.ClassInfoElement{element}},
+			field:  "element",
+			want:   system.Collection{element},
+			wantOK: true,
+		},
+		{
+			name:   "class info element one-based flag",
+			info:   element,
+			field:  "isOneBased",
+			want:   system.Collection{system.Boolean(false)},
+			wantOK: true,
+		},
+		{
+			name:   "list element type",
+			info:   reflection.ListTypeInfo{ElementType: "FHIR.HumanName"},
+			field:  "elementType",
+			want:   system.Collection{system.String("FHIR.HumanName")},
+			wantOK: true,
+		},
+		{
+			name:  "unknown property",
+			info:  reflection.ListTypeInfo{ElementType: "FHIR.HumanName"},
+			field: "name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := tc.info.Field(tc.field)
+			if ok != tc.wantOK {
+				t.Fatalf("Field(%v) returned ok = %v, want %v", tc.field, ok, tc.wantOK)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Field(%v) returned unexpected diff (-want, +got):\n%s", tc.field, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package profile
+
+import (
+	"strings"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// ElementDefinition returns the definition of the element at the given path,
+// such as "Observation.valueQuantity.unit", from the base StructureDefinitions
+// of the resources and data types along the path, which are those with a
+// "http://hl7.org/fhir/StructureDefinition/" URL. Choice type elements may be
+// named after their type. A nil definition is returned if the path is unknown.
+func (r *Registry) ElementDefinition(path string) (*dtpb.ElementDefinition, error) {
+	segments := strings.Split(path, ".")
+	return r.elementDefinition(segments[0], segments[1:]), nil
+}
+
+// elementDefinition returns the definition of the element at the path formed
+// by the segments, relative to the base definition of the given type.
+func (r *Registry) elementDefinition(typeName string, segments []string) *dtpb.ElementDefinition {
+	s, ok := r.structures[fhirTypePrefix+typeName]
+	if !ok {
+		return nil
+	}
+	ed := s.byPath[typeName]
+	chosen := ""
+	for i, segment := range segments {
+		parent := ed.GetPath().GetValue()
+		if ref := ed.GetContentReference().GetValue(); strings.Contains(ref, "#") {
+			parent = ref[strings.Index(ref, "#")+1:]
+		}
+		child, code := s.child(parent, segment)
+		if child == nil {
+			// The children of elements of complex types are defined by the
+			// base definition of their type.
+			if chosen == "" && len(ed.GetType()) == 1 {
+				chosen = strings.TrimPrefix(ed.GetType()[0].GetCode().GetValue(), fhirTypePrefix)
+			}
+			// Unless a segment was consumed, the type defines no such child.
+			if chosen == "" || i == 0 {
+				return nil
+			}
+			return r.elementDefinition(chosen, segments[i:])
+		}
+		ed, chosen = child, code
+	}
+	return ed
+}
+
+// child returns the definition of the named child of the element at the given
+// path, along with the chosen type if the child is a choice type element
+// named after its type, such as "valueQuantity".
+func (s *structure) child(path, name string) (*dtpb.ElementDefinition, string) {
+	if ed, ok := s.byPath[path+"."+name]; ok {
+		return ed, ""
+	}
+	for i := 1; i < len(name); i++ {
+		ed, ok := s.byPath[path+"."+name[:i]+"[x]"]
+		if !ok {
+			continue
+		}
+		for _, ref := range ed.GetType() {
+			code := ref.GetCode().GetValue()
+			if code != "" && strings.ToUpper(code[:1])+code[1:] == name[i:] {
+				return ed, code
+			}
+		}
+	}
+	return nil, ""
+}
@@ -0,0 +1,81 @@
+package profile_test
+
+import (
+	"testing"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/profile"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+)
+
+func withContentReference(ed *dtpb.ElementDefinition, ref string) *dtpb.ElementDefinition {
+	ed.ContentReference = fhir.URI(ref)
+	return ed
+}
+
+func TestRegistry_ElementDefinition(t *testing.T) {
+	registry, err := profile.NewRegistry(
+		snapshotOf("http://hl7.org/fhir/StructureDefinition/Observation", "Observation",
+			elementDefinition("Observation", 0, "*"),
+			withTypes(elementDefinition("Observation.code", 1, "1"), "CodeableConcept"),
+			withTypes(elementDefinition("Observation.value[x]", 0, "1"), "Quantity", "string"),
+			withTypes(elementDefinition("Observation.referenceRange", 0, "*"), "BackboneElement"),
+			withTypes(elementDefinition("Observation.referenceRange.low", 0, "1"), "Quantity"),
+			withTypes(elementDefinition("Observation.component", 0, "*"), "BackboneElement"),
+			withTypes(elementDefinition("Observation.component.code", 1, "1"), "CodeableConcept"),
+			withContentReference(elementDefinition("Observation.component.referenceRange", 0, "*"), "#Observation.referenceRange"),
+		),
+		snapshotOf("http://hl7.org/fhir/StructureDefinition/CodeableConcept", "CodeableConcept",
+			elementDefinition("CodeableConcept", 0, "*"),
+			withTypes(elementDefinition("CodeableConcept.text", 0, "1"), "string"),
+		),
+		snapshotOf("http://hl7.org/fhir/StructureDefinition/Quantity", "Quantity",
+			elementDefinition("Quantity", 0, "*"),
+			withTypes(elementDefinition("Quantity.value", 0, "1"), "decimal"),
+			withTypes(elementDefinition("Quantity.unit", 0, "1"), "string"),
+		),
+		snapshotOf("http://hl7.org/fhir/StructureDefinition/Extension", "Extension",
+			elementDefinition("Extension", 0, "*"),
+			withTypes(elementDefinition("Extension.extension", 0, "*"), "Extension"),
+			withTypes(elementDefinition("Extension.url", 1, "1"), "uri"),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry: unexpected err: %v", err)
+	}
+	testCases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"resource", "Observation", "Observation"},
+		{"element", "Observation.code", "Observation.code"},
+		{"element of a data type", "Observation.code.text", "CodeableConcept.text"},
+		{"choice type element", "Observation.valueQuantity", "Observation.value[x]"},
+		{"choice type element of a primitive type", "Observation.valueString", "Observation.value[x]"},
+		{"element of a choice type", "Observation.valueQuantity.unit", "Quantity.unit"},
+		{"backbone element", "Observation.component.code", "Observation.component.code"},
+		{"content reference", "Observation.component.referenceRange.low", "Observation.referenceRange.low"},
+		{"data type of a content reference", "Observation.component.referenceRange.low.value", "Quantity.value"},
+		{"element of the same type", "Extension.extension.url", "Extension.url"},
+		{"element of nested elements of the same type", "Extension.extension.extension.url", "Extension.url"},
+		{"unknown element of the same type", "Extension.extension.unknown", ""},
+		{"unknown choice type", "Observation.valueBoolean", ""},
+		{"unknown element", "Observation.unknown", ""},
+		{"unknown element of a data type", "Observation.code.unknown", ""},
+		{"unknown resource", "Patient.name", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := registry.ElementDefinition(tc.path)
+			if err != nil {
+				t.Fatalf("ElementDefinition(%v): unexpected err: %v", tc.path, err)
+			}
+
+			if got := got.GetPath().GetValue(); got != tc.want {
+				t.Errorf("ElementDefinition(%v): got path %q, want %q", tc.path, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package profile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/reflection"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/protofields"
+	"google.golang.org/protobuf/proto"
+)
+
+// elementVariable is the environment variable that holds the validated
+// element, from which the instances of element definitions are selected.
+const elementVariable = "element"
+
+const (
+	fhirTypePrefix     = "http://hl7.org/fhir/StructureDefinition/"
+	systemTypePrefix   = "http://hl7.org/fhirpath/System."
+	unboundedMaxValues = -1
+)
+
+// element is an element definition, along with the FHIRPath expressions that
+// select the instances that it constrains.
+type element struct {
+	definition *dtpb.ElementDefinition
+	path       string
+
+	// instances selects the instances of the element, and counts selects the
+	// number of instances within each instance of the parent element. counts is
+	// nil for the root element.
+	instances *fhirpath.Expression
+	counts    *fhirpath.Expression
+	err       error
+
+	max         int
+	types       []reflection.TypeSpecifier
+	constraints []*constraint
+}
+
+// constraint is a FHIRPath constraint of an element, along with the FHIRPath
+// expression that selects the instances that don't satisfy it.
+type constraint struct {
+	definition *dtpb.ElementDefinition_Constraint
+	failures   *fhirpath.Expression
+	err        error
+}
+
+func newElement(ed *dtpb.ElementDefinition) *element {
+	e := &element{
+		definition: ed,
+		path:       ed.GetPath().GetValue(),
+		max:        unboundedMaxValues,
+		types:      typesOf(ed),
+	}
+	segments := strings.Split(e.path, ".")
+	selection := "%" + elementVariable
+	parent := ""
+	for _, segment := range segments[1:] {
+		parent = selection
+		selection = fmt.Sprintf("%s.`%s`", selection, strings.TrimSuffix(segment, "[x]"))
+	}
+	if e.instances, e.err = fhirpath.Compile(selection); e.err != nil {
+		return e
+	}
+	if parent != "" {
+		name := strings.TrimSuffix(segments[len(segments)-1], "[x]")
+		e.counts, e.err = fhirpath.Compile(fmt.Sprintf("%s.select(`%s`.count())", parent, name))
+	}
+	if max, err := strconv.Atoi(ed.GetMax().GetValue()); err == nil {
+		e.max = max
+	}
+	for _, c := range ed.GetConstraint() {
+		if c.GetExpression().GetValue() == "" {
+			continue
+		}
+		failures, err := fhirpath.Compile(fmt.Sprintf("%s.where((%s).not())", selection, c.GetExpression().GetValue()))
+		e.constraints = append(e.constraints, &constraint{definition: c, failures: failures, err: err})
+	}
+	return e
+}
+
+// typesOf returns the types allowed by the element definition, or nil if any
+// type is allowed or if a type isn't supported, so that types aren't checked.
+func typesOf(ed *dtpb.ElementDefinition) []reflection.TypeSpecifier {
+	var types []reflection.TypeSpecifier
+	for _, ref := range ed.GetType() {
+		code := strings.TrimPrefix(ref.GetCode().GetValue(), fhirTypePrefix)
+		switch {
+		case strings.HasPrefix(code, systemTypePrefix), code == "Element", code == "BackboneElement":
+			return nil
+		}
+		ts, err := reflection.NewTypeSpecifier(code)
+		if err != nil {
+			return nil
+		}
+		types = append(types, ts)
+	}
+	return types
+}
+
+// validation holds the state of the validation of a single element.
+type validation struct {
+	element fhir.Base
+	options []fhirpath.EvaluateOption
+	issues  []*oopb.OperationOutcome_Issue
+}
+
+func newValidation(element fhir.Base) *validation {
+	options := []fhirpath.EvaluateOption{evalopts.EnvVariable(elementVariable, element)}
+	if res, ok := element.(fhir.Resource); ok {
+		options = append(options,
+			evalopts.EnvVariable("resource", res),
+			evalopts.EnvVariable("rootResource", res),
+		)
+	}
+	return &validation{element: element, options: options}
+}
+
+func (v *validation) evaluate(expression *fhirpath.Expression) (system.Collection, error) {
+	return expression.Evaluate(nil, v.options...)
+}
+
+func (v *validation) report(severity cpb.IssueSeverityCode_Value, code cpb.IssueTypeCode_Value, path, diagnostics string) {
+	issue := &oopb.OperationOutcome_Issue{
+		Severity:    &oopb.OperationOutcome_Issue_SeverityCode{Value: severity},
+		Code:        &oopb.OperationOutcome_Issue_CodeType{Value: code},
+		Diagnostics: fhir.String(diagnostics),
+	}
+	if path != "" {
+		issue.Expression = []*dtpb.String{fhir.String(path)}
+	}
+	v.issues = append(v.issues, issue)
+}
+
+// validate validates the instances of the element, reporting an issue for
+// each failed check.
+func (e *element) validate(v *validation) {
+	if e.err != nil {
+		v.report(cpb.IssueSeverityCode_WARNING, cpb.IssueTypeCode_NOT_SUPPORTED, e.path,
+			fmt.Sprintf("element %s is not supported: %v", e.path, e.err))
+		return
+	}
+	instances, err := v.evaluate(e.instances)
+	if err != nil {
+		v.report(cpb.IssueSeverityCode_WARNING, cpb.IssueTypeCode_NOT_SUPPORTED, e.path,
+			fmt.Sprintf("element %s is not supported: %v", e.path, err))
+		return
+	}
+	if err := e.validateCardinality(v); err != nil {
+		v.report(cpb.IssueSeverityCode_WARNING, cpb.IssueTypeCode_NOT_SUPPORTED, e.path,
+			fmt.Sprintf("element %s is not supported: %v", e.path, err))
+		return
+	}
+	for _, instance := range instances {
+		e.validateType(v, instance)
+		e.validateValue(v, instance)
+	}
+	for _, c := range e.constraints {
+		c.validate(v, e.path)
+	}
+}
+
+func (e *element) validateCardinality(v *validation) error {
+	if e.counts == nil {
+		return nil
+	}
+	counts, err := v.evaluate(e.counts)
+	if err != nil {
+		return err
+	}
+	min := int(e.definition.GetMin().GetValue())
+	for _, item := range counts {
+		count, ok := item.(system.Integer)
+		if !ok {
+			continue
+		}
+		if int(count) < min {
+			v.report(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_REQUIRED, e.path,
+				fmt.Sprintf("%s: minimum required = %d, but only found %d", e.path, min, count))
+		}
+		if e.max != unboundedMaxValues && int(count) > e.max {
+			v.report(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_STRUCTURE, e.path,
+				fmt.Sprintf("%s: maximum allowed = %d, but found %d", e.path, e.max, count))
+		}
+	}
+	return nil
+}
+
+func (e *element) validateType(v *validation, instance any) {
+	if len(e.types) == 0 {
+		return
+	}
+	got, err := reflection.TypeOf(instance)
+	if err == nil && isAny(got, e.types) {
+		return
+	}
+	v.report(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_STRUCTURE, e.path,
+		fmt.Sprintf("%s: type %v is not one of the allowed types", e.path, got))
+}
+
+func (e *element) validateValue(v *validation, instance any) {
+	message, _ := instance.(proto.Message)
+	if fixed := e.definition.GetFixed(); fixed != nil {
+		if want := protofields.UnwrapOneofField(fixed, "choice"); want != nil && (message == nil || !matches(message, want, true)) {
+			v.report(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_VALUE, e.path,
+				fmt.Sprintf("%s: value does not equal the fixed value", e.path))
+		}
+	}
+	if pattern := e.definition.GetPattern(); pattern != nil {
+		if want := protofields.UnwrapOneofField(pattern, "choice"); want != nil && (message == nil || !matches(message, want, false)) {
+			v.report(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_VALUE, e.path,
+				fmt.Sprintf("%s: value does not match the pattern", e.path))
+		}
+	}
+}
+
+// validate evaluates the constraint, reporting an issue with the severity of
+// the constraint if any instance doesn't satisfy it. Constraints that can't be
+// evaluated are reported as warnings.
+func (c *constraint) validate(v *validation, path string) {
+	key := c.definition.GetKey().GetValue()
+	err := c.err
+	var failures system.Collection
+	if err == nil {
+		failures, err = v.evaluate(c.failures)
+	}
+	if err != nil {
+		v.report(cpb.IssueSeverityCode_WARNING, cpb.IssueTypeCode_NOT_SUPPORTED, path,
+			fmt.Sprintf("constraint %s could not be evaluated: %v", key, err))
+		return
+	}
+	if len(failures) == 0 {
+		return
+	}
+	severity := cpb.IssueSeverityCode_ERROR
+	if c.definition.GetSeverity().GetValue() == cpb.ConstraintSeverityCode_WARNING {
+		severity = cpb.IssueSeverityCode_WARNING
+	}
+	v.report(severity, cpb.IssueTypeCode_INVARIANT, path,
+		fmt.Sprintf("constraint %s failed: %s", key, c.definition.GetHuman().GetValue()))
+}
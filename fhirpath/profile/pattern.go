@@ -0,0 +1,79 @@
+package profile
+
+import (
+	"github.com/verily-src/fhirpath-go/internal/protofields"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// matches reports whether the value matches the pattern, which is the case if
+// every field populated in the pattern matches the same field of the value.
+// Each element of a repeated field of the pattern must match some element of
+// the value.
+//
+// If exact, as for fixed values, the value must also have no other fields
+// populated, and repeated fields must match element by element.
+func matches(value, pattern proto.Message, exact bool) bool {
+	if oneOf := protofields.UnwrapOneofField(value, "choice"); oneOf != nil {
+		value = oneOf
+	}
+	if oneOf := protofields.UnwrapOneofField(pattern, "choice"); oneOf != nil {
+		pattern = oneOf
+	}
+	got, want := value.ProtoReflect(), pattern.ProtoReflect()
+	if got.Descriptor().FullName() != want.Descriptor().FullName() {
+		// Codes are represented by a distinct message for each value set, so
+		// a code of a fixed value may be of a different message than the
+		// instance.
+		gotCode, ok := protofields.StringValueFromCodeField(value)
+		wantCode, wantOK := protofields.StringValueFromCodeField(pattern)
+		return ok && wantOK && gotCode == wantCode
+	}
+	result := true
+	want.Range(func(fd protoreflect.FieldDescriptor, wantValue protoreflect.Value) bool {
+		result = got.Has(fd) && matchesField(fd, got.Get(fd), wantValue, exact)
+		return result
+	})
+	if result && exact {
+		got.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			result = want.Has(fd)
+			return result
+		})
+	}
+	return result
+}
+
+func matchesField(fd protoreflect.FieldDescriptor, value, pattern protoreflect.Value, exact bool) bool {
+	if !fd.IsList() {
+		return matchesValue(fd, value, pattern, exact)
+	}
+	values, patterns := value.List(), pattern.List()
+	if exact {
+		if values.Len() != patterns.Len() {
+			return false
+		}
+		for i := 0; i < patterns.Len(); i++ {
+			if !matchesValue(fd, values.Get(i), patterns.Get(i), exact) {
+				return false
+			}
+		}
+		return true
+	}
+	for i := 0; i < patterns.Len(); i++ {
+		found := false
+		for j := 0; j < values.Len() && !found; j++ {
+			found = matchesValue(fd, values.Get(j), patterns.Get(i), exact)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesValue(fd protoreflect.FieldDescriptor, value, pattern protoreflect.Value, exact bool) bool {
+	if fd.Message() != nil {
+		return matches(value.Message().Interface(), pattern.Message().Interface(), exact)
+	}
+	return value.Equal(pattern)
+}
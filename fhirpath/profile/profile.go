@@ -0,0 +1,194 @@
+/*
+Package profile provides a local registry of StructureDefinition resources,
+which validates resources and elements against the profiles that they define.
+The Registry is an evalopts.ProfileValidator, which answers the conformsTo()
+function, and evalopts.StructureDefinitions, which answers the slice() and
+elementDefinition() functions. It may also be used directly:
+
+	registry, err := profile.NewRegistry(vitalSigns)
+	if err != nil {
+		return err
+	}
+	outcome, err := registry.Validate(observation, "http://hl7.org/fhir/StructureDefinition/vitalsigns")
+*/
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/reflection"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+)
+
+var (
+	ErrInvalidDefinition  = errors.New("invalid structure definition")
+	ErrUnsupportedSlicing = errors.New("unsupported slicing")
+	ErrAmbiguousSlice     = errors.New("ambiguous slice name")
+)
+
+// Registry is a ProfileValidator over in-memory StructureDefinition resources,
+// which are looked up by their canonical URL, with or without a "|version"
+// suffix.
+//
+// Elements are validated against the snapshot of a StructureDefinition, or
+// against its differential if it has no snapshot, in which case the base
+// definition is also validated if it is in the registry. The validation checks
+// the cardinality, types, fixed and pattern values, and FHIRPath constraints of
+// each element. Slices and the profiles of element types are not validated.
+//
+// The Registry also identifies the members of slices by the discriminators of
+// their slicing, and looks up the definitions of elements in the base
+// definitions of resources and data types, if these are in the registry.
+type Registry struct {
+	structures map[string]*structure
+}
+
+var (
+	_ evalopts.ProfileValidator     = (*Registry)(nil)
+	_ evalopts.StructureDefinitions = (*Registry)(nil)
+)
+
+// structure is a StructureDefinition, along with the compiled checks of the
+// elements that it is validated against.
+type structure struct {
+	definition *sdpb.StructureDefinition
+
+	// differential is true if the elements are those of the differential, in
+	// which case the base definition must also be validated.
+	differential bool
+	elements     []*element
+
+	// byPath indexes the element definitions that aren't slices by path, and
+	// byID indexes all element definitions by id. slices holds the slices of
+	// the definition by id, such as "Observation.component:systolic".
+	byPath map[string]*dtpb.ElementDefinition
+	byID   map[string]*dtpb.ElementDefinition
+	slices map[string]*slice
+}
+
+// NewRegistry returns a Registry of the given StructureDefinitions.
+// Definitions without a URL or type result in an ErrInvalidDefinition error.
+func NewRegistry(definitions ...*sdpb.StructureDefinition) (*Registry, error) {
+	r := &Registry{structures: map[string]*structure{}}
+	for _, definition := range definitions {
+		url := definition.GetUrl().GetValue()
+		if url == "" || definition.GetType().GetValue() == "" {
+			return nil, fmt.Errorf("%w: missing url or type of %s", ErrInvalidDefinition, definition.GetId().GetValue())
+		}
+		s := newStructure(definition)
+		r.structures[url] = s
+		if version := definition.GetVersion().GetValue(); version != "" {
+			r.structures[url+"|"+version] = s
+		}
+	}
+	return r, nil
+}
+
+func newStructure(definition *sdpb.StructureDefinition) *structure {
+	s := &structure{
+		definition: definition,
+		byPath:     map[string]*dtpb.ElementDefinition{},
+		byID:       map[string]*dtpb.ElementDefinition{},
+		slices:     map[string]*slice{},
+	}
+	definitions := definition.GetSnapshot().GetElement()
+	if len(definitions) == 0 {
+		definitions = definition.GetDifferential().GetElement()
+		s.differential = true
+	}
+	var slices []*dtpb.ElementDefinition
+	for _, ed := range definitions {
+		s.byID[elementID(ed)] = ed
+		if ed.GetSliceName().GetValue() != "" {
+			slices = append(slices, ed)
+		}
+		if isSlice(ed) {
+			continue
+		}
+		s.byPath[ed.GetPath().GetValue()] = ed
+		s.elements = append(s.elements, newElement(ed))
+	}
+	for _, ed := range slices {
+		s.slices[elementID(ed)] = s.newSlice(ed)
+	}
+	return s
+}
+
+// Validate validates the element, which is either a resource or a data type,
+// against the StructureDefinition with the given canonical URL. The issues
+// found are returned as an OperationOutcome, which has a single informational
+// issue if there are none. A nil outcome is returned if the profile is
+// unknown.
+func (r *Registry) Validate(element fhir.Base, profile string) (*oopb.OperationOutcome, error) {
+	s, ok := r.structures[profile]
+	if !ok {
+		return nil, nil
+	}
+	v := newValidation(element)
+	if r.validateType(v, s) {
+		r.validate(v, s, map[*structure]bool{})
+	}
+	if len(v.issues) == 0 {
+		v.report(cpb.IssueSeverityCode_INFORMATION, cpb.IssueTypeCode_INFORMATIONAL, "", "validation successful")
+	}
+	return &oopb.OperationOutcome{Issue: v.issues}, nil
+}
+
+// validateType reports whether the element is of the type constrained by the
+// structure, reporting an issue if it isn't.
+func (r *Registry) validateType(v *validation, s *structure) bool {
+	name := s.definition.GetType().GetValue()
+	want, err := reflection.NewTypeSpecifier(name)
+	if err != nil {
+		v.report(cpb.IssueSeverityCode_WARNING, cpb.IssueTypeCode_NOT_SUPPORTED, "",
+			fmt.Sprintf("profile constrains unsupported type %s", name))
+		return false
+	}
+	got, err := reflection.TypeOf(v.element)
+	if err != nil || !got.Is(want) {
+		v.report(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_STRUCTURE, "",
+			fmt.Sprintf("element of type %v does not match profile type %s", got, name))
+		return false
+	}
+	return true
+}
+
+func (r *Registry) validate(v *validation, s *structure, seen map[*structure]bool) {
+	if seen[s] {
+		return
+	}
+	seen[s] = true
+	if s.differential {
+		if base, ok := r.structures[s.definition.GetBaseDefinition().GetValue()]; ok {
+			r.validate(v, base, seen)
+		}
+	}
+	for _, e := range s.elements {
+		e.validate(v)
+	}
+}
+
+// elementID returns the id of the element definition, which defaults to its
+// path, along with its slice name if it has one.
+func elementID(ed *dtpb.ElementDefinition) string {
+	if id := ed.GetId().GetValue(); id != "" {
+		return id
+	}
+	if name := ed.GetSliceName().GetValue(); name != "" {
+		return ed.GetPath().GetValue() + ":" + name
+	}
+	return ed.GetPath().GetValue()
+}
+
+// isSlice reports whether the element definition is a slice, or an element
+// within one, which is identified by a slice name in its id.
+func isSlice(ed *dtpb.ElementDefinition) bool {
+	return ed.GetSliceName().GetValue() != "" || strings.Contains(ed.GetId().GetValue(), ":")
+}
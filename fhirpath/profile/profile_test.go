@@ -0,0 +1,340 @@
+package profile_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/profile"
+	"github.com/verily-src/fhirpath-go/internal/element/reference"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/resource"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	observationBase = "http://example.com/StructureDefinition/observation-base"
+	vitalSigns      = "http://example.com/StructureDefinition/vital-signs"
+	simpleQuantity  = "http://example.com/StructureDefinition/simple-quantity"
+	snapshot        = "http://example.com/StructureDefinition/snapshot"
+	loinc           = "http://loinc.org"
+	categories      = "http://terminology.hl7.org/CodeSystem/observation-category"
+)
+
+func elementDefinition(path string, min uint32, max string) *dtpb.ElementDefinition {
+	return &dtpb.ElementDefinition{
+		Id:   fhir.String(path),
+		Path: fhir.String(path),
+		Min:  fhir.UnsignedInt(min),
+		Max:  fhir.String(max),
+	}
+}
+
+func withTypes(ed *dtpb.ElementDefinition, codes ...string) *dtpb.ElementDefinition {
+	for _, code := range codes {
+		ed.Type = append(ed.Type, &dtpb.ElementDefinition_TypeRef{Code: fhir.URI(code)})
+	}
+	return ed
+}
+
+func withConstraint(ed *dtpb.ElementDefinition, key string, severity cpb.ConstraintSeverityCode_Value, expression string) *dtpb.ElementDefinition {
+	ed.Constraint = append(ed.Constraint, &dtpb.ElementDefinition_Constraint{
+		Key:        fhir.ID(key),
+		Severity:   &dtpb.ElementDefinition_Constraint_SeverityCode{Value: severity},
+		Human:      fhir.String(key + " must hold"),
+		Expression: fhir.String(expression),
+	})
+	return ed
+}
+
+func differential(url, resourceType, base string, elements ...*dtpb.ElementDefinition) *sdpb.StructureDefinition {
+	return &sdpb.StructureDefinition{
+		Url:            fhir.URI(url),
+		Version:        fhir.String("1.0.0"),
+		Type:           fhir.URI(resourceType),
+		BaseDefinition: &dtpb.Canonical{Value: base},
+		Differential:   &sdpb.StructureDefinition_Differential{Element: elements},
+	}
+}
+
+var (
+	category = fhir.CodeableConcept("", fhir.Coding(categories, "vital-signs"))
+
+	statusFixed = func() *dtpb.ElementDefinition {
+		ed := elementDefinition("Observation.status", 1, "1")
+		ed.Fixed = &dtpb.ElementDefinition_FixedX{
+			Choice: &dtpb.ElementDefinition_FixedX_Code{Code: fhir.Code("final")},
+		}
+		return ed
+	}()
+
+	categoryPattern = func() *dtpb.ElementDefinition {
+		ed := elementDefinition("Observation.category", 1, "*")
+		ed.Pattern = &dtpb.ElementDefinition_PatternX{
+			Choice: &dtpb.ElementDefinition_PatternX_CodeableConcept{CodeableConcept: category},
+		}
+		return ed
+	}()
+
+	categorySlice = func() *dtpb.ElementDefinition {
+		ed := elementDefinition("Observation.category", 1, "1")
+		ed.Id = fhir.String("Observation.category:VSCat")
+		ed.SliceName = fhir.String("VSCat")
+		return ed
+	}()
+
+	quantitySystem = func() *dtpb.ElementDefinition {
+		ed := elementDefinition("Quantity.system", 1, "1")
+		ed.Fixed = &dtpb.ElementDefinition_FixedX{
+			Choice: &dtpb.ElementDefinition_FixedX_Uri{Uri: fhir.URI("http://unitsofmeasure.org")},
+		}
+		return ed
+	}()
+
+	definitions = []*sdpb.StructureDefinition{
+		differential(observationBase, "Observation", "http://hl7.org/fhir/StructureDefinition/Observation",
+			withConstraint(elementDefinition("Observation", 0, "*"), "obs-1", cpb.ConstraintSeverityCode_ERROR,
+				"value.exists() or dataAbsentReason.exists() or component.exists()"),
+			elementDefinition("Observation.code", 1, "1"),
+		),
+		differential(vitalSigns, "Observation", observationBase,
+			statusFixed,
+			categoryPattern,
+			categorySlice,
+			withTypes(elementDefinition("Observation.subject", 1, "1"), "Reference"),
+			withTypes(elementDefinition("Observation.value[x]", 0, "1"), "Quantity", "CodeableConcept"),
+			elementDefinition("Observation.issued", 0, "0"),
+			withConstraint(elementDefinition("Observation.component", 0, "*"), "vs-3", cpb.ConstraintSeverityCode_WARNING,
+				"value.exists() or dataAbsentReason.exists()"),
+			elementDefinition("Observation.component.code", 1, "1"),
+		),
+		differential(simpleQuantity, "Quantity", "http://hl7.org/fhir/StructureDefinition/Quantity",
+			elementDefinition("Quantity.value", 1, "1"),
+			quantitySystem,
+			elementDefinition("Quantity.comparator", 0, "0"),
+		),
+		{
+			Url:  fhir.URI(snapshot),
+			Type: fhir.URI("Observation"),
+			Snapshot: &sdpb.StructureDefinition_Snapshot{
+				Element: []*dtpb.ElementDefinition{
+					elementDefinition("Observation", 0, "*"),
+					elementDefinition("Observation.valueQuantity", 1, "1"),
+					withConstraint(elementDefinition("Observation.code", 1, "1"), "unknown-1", cpb.ConstraintSeverityCode_ERROR,
+						"notAFunction()"),
+				},
+			},
+			Differential: &sdpb.StructureDefinition_Differential{
+				Element: []*dtpb.ElementDefinition{
+					elementDefinition("Observation.id", 1, "1"),
+				},
+			},
+		},
+	}
+
+	heartRate = &opb.Observation{
+		Status:   &opb.Observation_StatusCode{Value: cpb.ObservationStatusCode_FINAL},
+		Category: []*dtpb.CodeableConcept{fhir.CodeableConcept("Vital signs", fhir.Coding(categories, "vital-signs"))},
+		Code:     fhir.CodeableConcept("Heart rate", fhir.Coding(loinc, "8867-4")),
+		Subject:  reference.Weak(resource.Patient, "Patient/123"),
+		Value: &opb.Observation_ValueX{
+			Choice: &opb.Observation_ValueX_Quantity{Quantity: beatsPerMinute},
+		},
+	}
+	beatsPerMinute = &dtpb.Quantity{
+		Value:  fhir.Decimal(72),
+		System: fhir.URI("http://unitsofmeasure.org"),
+		Code:   fhir.Code("/min"),
+	}
+)
+
+func newRegistry(t *testing.T) *profile.Registry {
+	t.Helper()
+	registry, err := profile.NewRegistry(definitions...)
+	if err != nil {
+		t.Fatalf("NewRegistry: unexpected err: %v", err)
+	}
+	return registry
+}
+
+// issues summarizes the issues of an outcome as "severity code expression".
+func issues(outcome *oopb.OperationOutcome) []string {
+	var result []string
+	for _, issue := range outcome.GetIssue() {
+		severity := issue.GetSeverity().GetValue().String()
+		code := issue.GetCode().GetValue().String()
+		summary := strings.ToLower(strings.ReplaceAll(fmt.Sprintf("%s %s", severity, code), "_", "-"))
+		for _, expression := range issue.GetExpression() {
+			summary += " " + expression.GetValue()
+		}
+		result = append(result, summary)
+	}
+	return result
+}
+
+func TestNewRegistry_InvalidDefinition_ReturnsError(t *testing.T) {
+	_, err := profile.NewRegistry(&sdpb.StructureDefinition{Type: fhir.URI("Observation")})
+
+	if !errors.Is(err, profile.ErrInvalidDefinition) {
+		t.Errorf("NewRegistry: got err %v, want %v", err, profile.ErrInvalidDefinition)
+	}
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	registry := newRegistry(t)
+	testCases := []struct {
+		name    string
+		element fhir.Base
+		profile string
+		want    []string
+	}{
+		{
+			name:    "conforming resource",
+			element: heartRate,
+			profile: vitalSigns,
+			want:    []string{"information informational"},
+		},
+		{
+			name:    "versioned profile",
+			element: heartRate,
+			profile: vitalSigns + "|1.0.0",
+			want:    []string{"information informational"},
+		},
+		{
+			name: "missing required elements, including those of the base profile",
+			element: &opb.Observation{
+				Status: &opb.Observation_StatusCode{Value: cpb.ObservationStatusCode_FINAL},
+			},
+			profile: vitalSigns,
+			want: []string{
+				"error invariant Observation",
+				"error required Observation.code",
+				"error required Observation.category",
+				"error required Observation.subject",
+			},
+		},
+		{
+			name: "too many and prohibited elements",
+			element: with(heartRate, func(o *opb.Observation) {
+				o.Code = nil
+				o.Issued = &dtpb.Instant{ValueUs: 1}
+			}),
+			profile: vitalSigns,
+			want: []string{
+				"error required Observation.code",
+				"error structure Observation.issued",
+			},
+		},
+		{
+			name: "value other than the fixed value",
+			element: with(heartRate, func(o *opb.Observation) {
+				o.Status = &opb.Observation_StatusCode{Value: cpb.ObservationStatusCode_AMENDED}
+			}),
+			profile: vitalSigns,
+			want:    []string{"error value Observation.status"},
+		},
+		{
+			name: "value not matching the pattern",
+			element: with(heartRate, func(o *opb.Observation) {
+				o.Category = []*dtpb.CodeableConcept{fhir.CodeableConcept("", fhir.Coding(categories, "laboratory"))}
+			}),
+			profile: vitalSigns,
+			want:    []string{"error value Observation.category"},
+		},
+		{
+			name: "value of a type that isn't allowed",
+			element: with(heartRate, func(o *opb.Observation) {
+				o.Value = &opb.Observation_ValueX{Choice: &opb.Observation_ValueX_StringValue{StringValue: fhir.String("72")}}
+			}),
+			profile: vitalSigns,
+			want:    []string{"error structure Observation.value[x]"},
+		},
+		{
+			name: "nested elements and warning constraints",
+			element: with(heartRate, func(o *opb.Observation) {
+				o.Component = []*opb.Observation_Component{
+					{Code: fhir.CodeableConcept("", fhir.Coding(loinc, "8480-6"))},
+					{},
+				}
+			}),
+			profile: vitalSigns,
+			want: []string{
+				"warning invariant Observation.component",
+				"error required Observation.component.code",
+			},
+		},
+		{
+			name:    "conforming data type",
+			element: beatsPerMinute,
+			profile: simpleQuantity,
+			want:    []string{"information informational"},
+		},
+		{
+			name: "non-conforming data type",
+			element: &dtpb.Quantity{
+				Comparator: &dtpb.Quantity_ComparatorCode{Value: cpb.QuantityComparatorCode_LESS_THAN},
+				System:     fhir.URI("http://example.com/units"),
+			},
+			profile: simpleQuantity,
+			want: []string{
+				"error required Quantity.value",
+				"error value Quantity.system",
+				"error structure Quantity.comparator",
+			},
+		},
+		{
+			name:    "element of another type",
+			element: &ppb.Patient{},
+			profile: vitalSigns,
+			want:    []string{"error structure"},
+		},
+		{
+			name:    "snapshot with unsupported elements and constraints",
+			element: heartRate,
+			profile: snapshot,
+			want: []string{
+				"warning not-supported Observation.valueQuantity",
+				"warning not-supported Observation.code",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := registry.Validate(tc.element, tc.profile)
+			if err != nil {
+				t.Fatalf("Validate: unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, issues(got)); diff != "" {
+				t.Errorf("Validate(%v) returned unexpected diff (-want, +got):\n%s", tc.profile, diff)
+			}
+		})
+	}
+}
+
+func TestRegistry_Validate_UnknownProfile_ReturnsNil(t *testing.T) {
+	registry := newRegistry(t)
+
+	got, err := registry.Validate(heartRate, "http://example.com/StructureDefinition/unknown")
+	if err != nil {
+		t.Fatalf("Validate: unexpected err: %v", err)
+	}
+
+	if got != nil {
+		t.Errorf("Validate: got %v, want nil", got)
+	}
+}
+
+func with(observation *opb.Observation, update func(*opb.Observation)) *opb.Observation {
+	clone := proto.Clone(observation).(*opb.Observation)
+	update(clone)
+	return clone
+}
@@ -0,0 +1,269 @@
+package profile
+
+import (
+	"fmt"
+	"strings"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath"
+	"github.com/verily-src/fhirpath-go/fhirpath/internal/reflection"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/protofields"
+	"google.golang.org/protobuf/proto"
+)
+
+// slice is a named slice of an element, along with the discriminators of its
+// slicing, which identify the members of the slice.
+type slice struct {
+	definition     *dtpb.ElementDefinition
+	id             string
+	discriminators []*discriminator
+	err            error
+}
+
+// discriminator is a discriminator of a slicing, along with the FHIRPath
+// expression that selects the discriminating values of an instance, and what
+// the values of the members of a slice must be.
+type discriminator struct {
+	kind    cpb.DiscriminatorTypeCode_Value
+	path    string
+	values  *fhirpath.Expression
+	element *dtpb.ElementDefinition
+	err     error
+
+	// want is the fixed or pattern value of value and pattern discriminators,
+	// which must be matched exactly if fixed.
+	want  proto.Message
+	exact bool
+
+	types    []reflection.TypeSpecifier
+	profiles []string
+}
+
+// newSlice returns the slice of the element definition, which is discriminated
+// by the slicing of the element that it slices. The slicing must be defined in
+// the same StructureDefinition, as it is in snapshots.
+func (s *structure) newSlice(ed *dtpb.ElementDefinition) *slice {
+	sl := &slice{definition: ed, id: elementID(ed)}
+	sliced := s.byID[strings.TrimSuffix(sl.id, ":"+ed.GetSliceName().GetValue())]
+	discriminators := sliced.GetSlicing().GetDiscriminator()
+	if len(discriminators) == 0 {
+		sl.err = fmt.Errorf("%w: slice %s has no discriminators", ErrUnsupportedSlicing, sl.id)
+		return sl
+	}
+	for _, d := range discriminators {
+		sl.discriminators = append(sl.discriminators, s.newDiscriminator(sl, d))
+	}
+	return sl
+}
+
+func (s *structure) newDiscriminator(sl *slice, d *dtpb.ElementDefinition_Slicing_Discriminator) *discriminator {
+	dc := &discriminator{kind: d.GetType().GetValue(), path: d.GetPath().GetValue()}
+	selection := "%" + elementVariable
+	if dc.path != "$this" {
+		selection += "." + dc.path
+	}
+	if dc.values, dc.err = fhirpath.Compile(selection); dc.err != nil {
+		return dc
+	}
+	dc.element = s.sliceElement(sl, dc.path)
+	switch dc.kind {
+	case cpb.DiscriminatorTypeCode_VALUE, cpb.DiscriminatorTypeCode_PATTERN:
+		if fixed := protofields.UnwrapOneofField(dc.element.GetFixed(), "choice"); fixed != nil {
+			dc.want, dc.exact = fixed, true
+		} else if pattern := protofields.UnwrapOneofField(dc.element.GetPattern(), "choice"); pattern != nil {
+			dc.want = pattern
+		} else if profiles := profilesOf(sl.definition, false); dc.path == "url" && len(profiles) == 1 {
+			// Slices of extensions are usually identified by the profile of
+			// the extension, rather than by a fixed url.
+			dc.want, dc.exact = &dtpb.Uri{Value: profiles[0]}, true
+		} else {
+			dc.err = fmt.Errorf("%w: slice %s has no fixed or pattern value at %s", ErrUnsupportedSlicing, sl.id, dc.path)
+		}
+	case cpb.DiscriminatorTypeCode_TYPE:
+		if dc.types = typesOf(dc.element); len(dc.types) == 0 {
+			dc.err = fmt.Errorf("%w: slice %s has no supported types at %s", ErrUnsupportedSlicing, sl.id, dc.path)
+		}
+	case cpb.DiscriminatorTypeCode_PROFILE:
+		if dc.profiles = profilesOf(dc.element, strings.HasSuffix(dc.path, "resolve()")); len(dc.profiles) == 0 {
+			dc.err = fmt.Errorf("%w: slice %s has no profiles at %s", ErrUnsupportedSlicing, sl.id, dc.path)
+		}
+	case cpb.DiscriminatorTypeCode_EXISTS:
+		if dc.element == nil {
+			dc.err = fmt.Errorf("%w: slice %s has no element at %s", ErrUnsupportedSlicing, sl.id, dc.path)
+		}
+	default:
+		dc.err = fmt.Errorf("%w: discriminator type %v", ErrUnsupportedSlicing, dc.kind)
+	}
+	return dc
+}
+
+// sliceElement returns the definition of the element at the discriminator
+// path within the slice, or nil if there is none. Calls to resolve() and
+// ofType() in the path are skipped, since they don't select child elements.
+func (s *structure) sliceElement(sl *slice, path string) *dtpb.ElementDefinition {
+	id := sl.id
+	for _, segment := range strings.Split(path, ".") {
+		switch {
+		case segment == "$this", segment == "resolve()", strings.HasPrefix(segment, "ofType("):
+			continue
+		}
+		if _, ok := s.byID[id+"."+segment]; ok {
+			id += "." + segment
+		} else if _, ok := s.byID[id+"."+segment+"[x]"]; ok {
+			id += "." + segment + "[x]"
+		} else {
+			return nil
+		}
+	}
+	return s.byID[id]
+}
+
+// profilesOf returns the profiles of the types of the element definition, or
+// the target profiles of its references if targets is true.
+func profilesOf(ed *dtpb.ElementDefinition, targets bool) []string {
+	var profiles []string
+	for _, ref := range ed.GetType() {
+		canonicals := ref.GetProfile()
+		if targets {
+			canonicals = ref.GetTargetProfile()
+		}
+		for _, canonical := range canonicals {
+			profiles = append(profiles, canonical.GetValue())
+		}
+	}
+	return profiles
+}
+
+// InSlice reports whether the element is a member of the named slice of the
+// StructureDefinition with the given canonical URL, which is the case if it
+// matches every discriminator of the slicing. The value, pattern, type,
+// profile and exists discriminators are supported, which results in an
+// ErrUnsupportedSlicing error otherwise. False is returned if the profile or
+// the slice is unknown.
+//
+// The slice is named by its slice name, such as "systolic", or by its element
+// id, such as "Observation.component:systolic". Slice names that are shared by
+// several slicings of the profile result in an ErrAmbiguousSlice error.
+func (r *Registry) InSlice(element fhir.Base, structure, name string) (bool, error) {
+	s, ok := r.structures[structure]
+	if !ok {
+		return false, nil
+	}
+	sl, err := s.slice(name)
+	if sl == nil || err != nil {
+		return false, err
+	}
+	if sl.err != nil {
+		return false, sl.err
+	}
+	v := newValidation(element)
+	for _, dc := range sl.discriminators {
+		if ok, err := r.discriminates(v, dc); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// slice returns the slice with the given element id or slice name, or nil if
+// there is none.
+func (s *structure) slice(name string) (*slice, error) {
+	if sl, ok := s.slices[name]; ok {
+		return sl, nil
+	}
+	var found *slice
+	for _, sl := range s.slices {
+		if sl.definition.GetSliceName().GetValue() != name {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%w: %s names slices of several elements, which must be named by id", ErrAmbiguousSlice, name)
+		}
+		found = sl
+	}
+	return found, nil
+}
+
+// discriminates reports whether the discriminating values of the validated
+// element are those of the members of the slice.
+func (r *Registry) discriminates(v *validation, dc *discriminator) (bool, error) {
+	if dc.err != nil {
+		return false, dc.err
+	}
+	values, err := v.evaluate(dc.values)
+	if err != nil {
+		return false, err
+	}
+	switch dc.kind {
+	case cpb.DiscriminatorTypeCode_VALUE, cpb.DiscriminatorTypeCode_PATTERN:
+		for _, value := range values {
+			if message, ok := value.(proto.Message); ok && matches(message, dc.want, dc.exact) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case cpb.DiscriminatorTypeCode_TYPE:
+		for _, value := range values {
+			got, err := reflection.TypeOf(value)
+			if err != nil || !isAny(got, dc.types) {
+				return false, nil
+			}
+		}
+		return len(values) > 0, nil
+	case cpb.DiscriminatorTypeCode_PROFILE:
+		for _, value := range values {
+			conforms, err := r.conformsToAny(value, dc.profiles)
+			if err != nil || !conforms {
+				return false, err
+			}
+		}
+		return len(values) > 0, nil
+	default:
+		if dc.element.GetMax().GetValue() == "0" {
+			return len(values) == 0, nil
+		}
+		return len(values) > 0 || dc.element.GetMin().GetValue() == 0, nil
+	}
+}
+
+// conformsToAny reports whether the value conforms to any of the profiles,
+// which is the case if its validation raises no errors.
+func (r *Registry) conformsToAny(value any, profiles []string) (bool, error) {
+	element, ok := value.(fhir.Base)
+	if !ok {
+		return false, nil
+	}
+	for _, profile := range profiles {
+		outcome, err := r.Validate(element, profile)
+		if err != nil {
+			return false, err
+		}
+		if outcome != nil && !hasErrors(outcome.GetIssue()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasErrors reports whether any of the issues is an error.
+func hasErrors(issues []*oopb.OperationOutcome_Issue) bool {
+	for _, issue := range issues {
+		switch issue.GetSeverity().GetValue() {
+		case cpb.IssueSeverityCode_ERROR, cpb.IssueSeverityCode_FATAL:
+			return true
+		}
+	}
+	return false
+}
+
+func isAny(got reflection.TypeSpecifier, types []reflection.TypeSpecifier) bool {
+	for _, want := range types {
+		if got.Is(want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package profile_test
+
+import (
+	"errors"
+	"testing"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/profile"
+	"github.com/verily-src/fhirpath-go/internal/element/extension"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+)
+
+const (
+	bloodPressure  = "http://example.com/StructureDefinition/blood-pressure"
+	device         = "http://example.com/StructureDefinition/device"
+	modifierDevice = "http://example.com/StructureDefinition/modifier-device"
+	textCategory   = "http://example.com/StructureDefinition/text-category"
+)
+
+func snapshotOf(url, typeName string, elements ...*dtpb.ElementDefinition) *sdpb.StructureDefinition {
+	return &sdpb.StructureDefinition{
+		Url:      fhir.URI(url),
+		Type:     fhir.URI(typeName),
+		Snapshot: &sdpb.StructureDefinition_Snapshot{Element: elements},
+	}
+}
+
+func slicing(ed *dtpb.ElementDefinition, kind cpb.DiscriminatorTypeCode_Value, path string) *dtpb.ElementDefinition {
+	ed.Slicing = &dtpb.ElementDefinition_Slicing{
+		Discriminator: []*dtpb.ElementDefinition_Slicing_Discriminator{
+			{
+				Type: &dtpb.ElementDefinition_Slicing_Discriminator_TypeCode{Value: kind},
+				Path: fhir.String(path),
+			},
+		},
+	}
+	return ed
+}
+
+func sliceOf(path, name string, min uint32, max string) *dtpb.ElementDefinition {
+	ed := elementDefinition(path, min, max)
+	ed.Id = fhir.String(path + ":" + name)
+	ed.SliceName = fhir.String(name)
+	return ed
+}
+
+func within(id string, ed *dtpb.ElementDefinition) *dtpb.ElementDefinition {
+	ed.Id = fhir.String(id)
+	return ed
+}
+
+func withPattern(ed *dtpb.ElementDefinition, pattern *dtpb.CodeableConcept) *dtpb.ElementDefinition {
+	ed.Pattern = &dtpb.ElementDefinition_PatternX{
+		Choice: &dtpb.ElementDefinition_PatternX_CodeableConcept{CodeableConcept: pattern},
+	}
+	return ed
+}
+
+func withProfile(ed *dtpb.ElementDefinition, code, profile string) *dtpb.ElementDefinition {
+	ed.Type = append(ed.Type, &dtpb.ElementDefinition_TypeRef{
+		Code:    fhir.URI(code),
+		Profile: []*dtpb.Canonical{{Value: profile}},
+	})
+	return ed
+}
+
+func newSliceRegistry(t *testing.T) *profile.Registry {
+	t.Helper()
+	registry, err := profile.NewRegistry(
+		snapshotOf(bloodPressure, "Observation",
+			elementDefinition("Observation", 0, "*"),
+			slicing(elementDefinition("Observation.extension", 0, "*"), cpb.DiscriminatorTypeCode_VALUE, "url"),
+			withProfile(sliceOf("Observation.extension", "device", 0, "1"), "Extension", device),
+			slicing(elementDefinition("Observation.modifierExtension", 0, "*"), cpb.DiscriminatorTypeCode_VALUE, "url"),
+			withProfile(sliceOf("Observation.modifierExtension", "device", 0, "1"), "Extension", modifierDevice),
+			slicing(elementDefinition("Observation.identifier", 0, "*"), cpb.DiscriminatorTypeCode_VALUE, "system"),
+			sliceOf("Observation.identifier", "unfixed", 0, "1"),
+			within("Observation.identifier:unfixed.system", elementDefinition("Observation.identifier.system", 1, "1")),
+			sliceOf("Observation.performer", "undiscriminated", 0, "1"),
+			slicing(elementDefinition("Observation.category", 1, "*"), cpb.DiscriminatorTypeCode_PROFILE, "$this"),
+			withProfile(sliceOf("Observation.category", "text", 0, "1"), "CodeableConcept", textCategory),
+			slicing(withTypes(elementDefinition("Observation.value[x]", 0, "1"), "Quantity", "string"), cpb.DiscriminatorTypeCode_TYPE, "$this"),
+			withTypes(sliceOf("Observation.value[x]", "valueQuantity", 0, "1"), "Quantity"),
+			slicing(elementDefinition("Observation.note", 0, "*"), cpb.DiscriminatorTypeCode_EXISTS, "author"),
+			sliceOf("Observation.note", "authored", 0, "*"),
+			within("Observation.note:authored.author[x]", elementDefinition("Observation.note.author[x]", 1, "1")),
+			sliceOf("Observation.note", "anonymous", 0, "*"),
+			within("Observation.note:anonymous.author[x]", elementDefinition("Observation.note.author[x]", 0, "0")),
+			slicing(elementDefinition("Observation.component", 2, "*"), cpb.DiscriminatorTypeCode_PATTERN, "code"),
+			sliceOf("Observation.component", "systolic", 1, "1"),
+			within("Observation.component:systolic.code", withPattern(elementDefinition("Observation.component.code", 1, "1"),
+				fhir.CodeableConcept("", fhir.Coding(loinc, "8480-6")))),
+			sliceOf("Observation.component", "diastolic", 1, "1"),
+			within("Observation.component:diastolic.code", withPattern(elementDefinition("Observation.component.code", 1, "1"),
+				fhir.CodeableConcept("", fhir.Coding(loinc, "8462-4")))),
+		),
+		differential(textCategory, "CodeableConcept", "http://hl7.org/fhir/StructureDefinition/CodeableConcept",
+			elementDefinition("CodeableConcept.text", 1, "1"),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewRegistry: unexpected err: %v", err)
+	}
+	return registry
+}
+
+func TestRegistry_InSlice(t *testing.T) {
+	registry := newSliceRegistry(t)
+	systolic := &opb.Observation_Component{Code: fhir.CodeableConcept("Systolic", fhir.Coding(loinc, "8480-6"))}
+	diastolic := &opb.Observation_Component{Code: fhir.CodeableConcept("", fhir.Coding(loinc, "8462-4"))}
+	authored := &dtpb.Annotation{
+		Author: &dtpb.Annotation_AuthorX{Choice: &dtpb.Annotation_AuthorX_StringValue{StringValue: fhir.String("Dr. Chu")}},
+		Text:   &dtpb.Markdown{Value: "Measured at rest"},
+	}
+	anonymous := &dtpb.Annotation{Text: &dtpb.Markdown{Value: "Measured at rest"}}
+	testCases := []struct {
+		name      string
+		element   fhir.Base
+		structure string
+		slice     string
+		want      bool
+	}{
+		{
+			name:      "pattern discriminator matches",
+			element:   systolic,
+			structure: bloodPressure,
+			slice:     "systolic",
+			want:      true,
+		},
+		{
+			name:      "pattern discriminator doesn't match",
+			element:   diastolic,
+			structure: bloodPressure,
+			slice:     "systolic",
+			want:      false,
+		},
+		{
+			name:      "slice named by id",
+			element:   systolic,
+			structure: bloodPressure,
+			slice:     "Observation.component:systolic",
+			want:      true,
+		},
+		{
+			name:      "value discriminator matches the profile of an extension",
+			element:   extension.New(device, fhir.String("cuff")),
+			structure: bloodPressure,
+			slice:     "Observation.extension:device",
+			want:      true,
+		},
+		{
+			name:      "value discriminator doesn't match",
+			element:   extension.New("http://example.com/StructureDefinition/other", fhir.String("cuff")),
+			structure: bloodPressure,
+			slice:     "Observation.extension:device",
+			want:      false,
+		},
+		{
+			name:      "slice of another slicing with the same name",
+			element:   extension.New(device, fhir.String("cuff")),
+			structure: bloodPressure,
+			slice:     "Observation.modifierExtension:device",
+			want:      false,
+		},
+		{
+			name:      "type discriminator matches",
+			element:   beatsPerMinute,
+			structure: bloodPressure,
+			slice:     "valueQuantity",
+			want:      true,
+		},
+		{
+			name:      "type discriminator doesn't match",
+			element:   fhir.String("high"),
+			structure: bloodPressure,
+			slice:     "valueQuantity",
+			want:      false,
+		},
+		{
+			name:      "profile discriminator matches",
+			element:   fhir.CodeableConcept("Vital signs", fhir.Coding(categories, "vital-signs")),
+			structure: bloodPressure,
+			slice:     "text",
+			want:      true,
+		},
+		{
+			name:      "profile discriminator doesn't match",
+			element:   category,
+			structure: bloodPressure,
+			slice:     "text",
+			want:      false,
+		},
+		{
+			name:      "exists discriminator requires the element",
+			element:   authored,
+			structure: bloodPressure,
+			slice:     "authored",
+			want:      true,
+		},
+		{
+			name:      "exists discriminator requires a missing element",
+			element:   anonymous,
+			structure: bloodPressure,
+			slice:     "authored",
+			want:      false,
+		},
+		{
+			name:      "exists discriminator forbids the element",
+			element:   authored,
+			structure: bloodPressure,
+			slice:     "anonymous",
+			want:      false,
+		},
+		{
+			name:      "exists discriminator forbids a missing element",
+			element:   anonymous,
+			structure: bloodPressure,
+			slice:     "anonymous",
+			want:      true,
+		},
+		{
+			name:      "unknown slice",
+			element:   systolic,
+			structure: bloodPressure,
+			slice:     "unknown",
+			want:      false,
+		},
+		{
+			name:      "unknown profile",
+			element:   systolic,
+			structure: "http://example.com/StructureDefinition/unknown",
+			slice:     "systolic",
+			want:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := registry.InSlice(tc.element, tc.structure, tc.slice)
+			if err != nil {
+				t.Fatalf("InSlice(%v): unexpected err: %v", tc.name, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("InSlice(%v): got %v, want %v", tc.name, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_InSlice_UnsupportedSlicing_ReturnsError(t *testing.T) {
+	registry := newSliceRegistry(t)
+	testCases := []struct {
+		name  string
+		slice string
+	}{
+		{"slicing without discriminators", "undiscriminated"},
+		{"value discriminator without a value", "unfixed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := registry.InSlice(&dtpb.Identifier{System: fhir.URI("urn:ietf:rfc:3986")}, bloodPressure, tc.slice)
+
+			if !errors.Is(err, profile.ErrUnsupportedSlicing) {
+				t.Errorf("InSlice(%v): got err %v, want %v", tc.name, err, profile.ErrUnsupportedSlicing)
+			}
+		})
+	}
+}
+
+func TestRegistry_InSlice_AmbiguousSliceName_ReturnsError(t *testing.T) {
+	registry := newSliceRegistry(t)
+
+	_, err := registry.InSlice(extension.New(device, fhir.String("cuff")), bloodPressure, "device")
+
+	if !errors.Is(err, profile.ErrAmbiguousSlice) {
+		t.Errorf("InSlice(device): got err %v, want %v", err, profile.ErrAmbiguousSlice)
+	}
+}
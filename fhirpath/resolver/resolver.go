@@ -0,0 +1,117 @@
+/*
+Package resolver provides implementations of evalopts.Resolver, which locate
+the resources referred to by the FHIRPath resolve() function.
+
+Resolvers are supplied to an evaluation with the evalopts.Resolve option, and
+may be combined with Chain:
+
+	expr.Evaluate(input, evalopts.Resolve(resolver.Chain(
+		resolver.Bundle(bundle),
+		resolver.Resources(patient, practitioner),
+	)))
+*/
+package resolver
+
+import (
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
+	"github.com/verily-src/fhirpath-go/internal/containedresource"
+	"github.com/verily-src/fhirpath-go/internal/element/reference"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/resource"
+)
+
+// Func is an adapter that allows an ordinary function to be used as a
+// Resolver.
+type Func func(url string) (fhir.Resource, error)
+
+// Resolve calls f(url).
+func (f Func) Resolve(url string) (fhir.Resource, error) {
+	return f(url)
+}
+
+// Chain returns a Resolver that tries each of the given resolvers in turn,
+// returning the first resource that is found.
+func Chain(resolvers ...evalopts.Resolver) evalopts.Resolver {
+	return Func(func(url string) (fhir.Resource, error) {
+		for _, r := range resolvers {
+			res, err := r.Resolve(url)
+			if err != nil || res != nil {
+				return res, err
+			}
+		}
+		return nil, nil
+	})
+}
+
+// Bundle returns a Resolver for the resources in the entries of the given
+// Bundle. URLs are matched against the fullUrl of each entry, or against the
+// relative URL ("Type/id" or "Type/id/_history/version") of the resource.
+func Bundle(bundle *bcrpb.Bundle) evalopts.Resolver {
+	fullURLs := map[string]fhir.Resource{}
+	var resources []fhir.Resource
+	for _, entry := range bundle.GetEntry() {
+		res := containedresource.Unwrap(entry.GetResource())
+		if res == nil {
+			continue
+		}
+		if fullURL := entry.GetFullUrl().GetValue(); fullURL != "" {
+			fullURLs[fullURL] = res
+		}
+		resources = append(resources, res)
+	}
+	byIdentity := Resources(resources...)
+	return Func(func(url string) (fhir.Resource, error) {
+		if res, ok := fullURLs[url]; ok {
+			return res, nil
+		}
+		return byIdentity.Resolve(url)
+	})
+}
+
+// Resources returns a Resolver for the given resources, which are indexed by
+// their resource.Identity. Relative and absolute REST URLs are resolved by
+// the identity they contain; unversioned URLs resolve to any version of the
+// resource. Canonical resources are also resolved by their canonical URL,
+// with or without a "|version" suffix.
+func Resources(resources ...fhir.Resource) evalopts.Resolver {
+	identities := map[resource.Identity]fhir.Resource{}
+	canonicals := map[string]fhir.Resource{}
+	for _, res := range resources {
+		if identity, ok := resource.IdentityOf(res); ok {
+			identities[*identity] = res
+			identities[*identity.Unversioned()] = res
+		}
+		if cr, ok := res.(fhir.CanonicalResource); ok {
+			if url := cr.GetUrl().GetValue(); url != "" {
+				canonicals[url] = res
+				if version := cr.GetVersion().GetValue(); version != "" {
+					canonicals[url+"|"+version] = res
+				}
+			}
+		}
+	}
+	return Func(func(url string) (fhir.Resource, error) {
+		if res, ok := canonicals[url]; ok {
+			return res, nil
+		}
+		lit, ok := literalInfo(url)
+		if !ok {
+			return nil, nil
+		}
+		if identity, ok := lit.Identity(); ok {
+			return identities[*identity], nil
+		}
+		return nil, nil
+	})
+}
+
+// literalInfo parses the given URL as a literal reference, reporting false if
+// it is not one. Such URLs can never be resolved, so they are not an error.
+func literalInfo(url string) (*reference.LiteralInfo, bool) {
+	if url == "" {
+		return nil, false
+	}
+	lit, err := reference.LiteralInfoFromURI(url)
+	return lit, err == nil
+}
@@ -0,0 +1,118 @@
+package resolver_test
+
+import (
+	"errors"
+	"testing"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
+	"github.com/verily-src/fhirpath-go/fhirpath/resolver"
+	"github.com/verily-src/fhirpath-go/internal/bundle"
+	"github.com/verily-src/fhirpath-go/internal/containedresource"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+var (
+	patient = &ppb.Patient{
+		Id:   fhir.ID("123"),
+		Meta: &dtpb.Meta{VersionId: fhir.ID("2")},
+	}
+	valueSet = &vspb.ValueSet{
+		Id:      fhir.ID("vs"),
+		Url:     fhir.URI("http://example.com/ValueSet/vs"),
+		Version: fhir.String("1.0"),
+	}
+)
+
+func TestResources_Resolves(t *testing.T) {
+	r := resolver.Resources(patient, valueSet)
+	testCases := []struct {
+		name string
+		url  string
+		want fhir.Resource
+	}{
+		{"relative URL", "Patient/123", patient},
+		{"versioned relative URL", "Patient/123/_history/2", patient},
+		{"absolute URL", "http://example.com/fhir/Patient/123", patient},
+		{"canonical URL", "http://example.com/ValueSet/vs", valueSet},
+		{"versioned canonical URL", "http://example.com/ValueSet/vs|1.0", valueSet},
+		{"unknown id", "Patient/456", nil},
+		{"unknown version", "Patient/123/_history/1", nil},
+		{"unknown canonical version", "http://example.com/ValueSet/vs|2.0", nil},
+		{"fragment", "#123", nil},
+		{"not a URL", "Patient", nil},
+		{"empty", "", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testResolve(t, r, tc.url, tc.want)
+		})
+	}
+}
+
+func TestBundle_Resolves(t *testing.T) {
+	other := &ppb.Patient{Id: fhir.ID("456")}
+	b := bundle.NewCollection(bundle.WithEntries(
+		&bcrpb.Bundle_Entry{
+			FullUrl:  fhir.URI("urn:uuid:1234"),
+			Resource: containedresource.Wrap(patient),
+		},
+		bundle.NewCollectionEntry(other),
+	))
+	r := resolver.Bundle(b)
+	testCases := []struct {
+		name string
+		url  string
+		want fhir.Resource
+	}{
+		{"full URL", "urn:uuid:1234", patient},
+		{"relative URL", "Patient/123", patient},
+		{"relative URL without full URL", "Patient/456", other},
+		{"unknown full URL", "urn:uuid:5678", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testResolve(t, r, tc.url, tc.want)
+		})
+	}
+}
+
+func TestChain_ReturnsFirstResolved(t *testing.T) {
+	other := &ppb.Patient{Id: fhir.ID("123")}
+	r := resolver.Chain(resolver.Resources(), resolver.Resources(patient), resolver.Resources(other))
+
+	testResolve(t, r, "Patient/123", patient)
+	testResolve(t, r, "Patient/456", nil)
+}
+
+func TestChain_Error_ReturnsError(t *testing.T) {
+	wantErr := errors.New("test error")
+	r := resolver.Chain(
+		resolver.Func(func(string) (fhir.Resource, error) {
+			return nil, wantErr
+		}),
+		resolver.Resources(patient),
+	)
+
+	if _, err := r.Resolve("Patient/123"); !errors.Is(err, wantErr) {
+		t.Errorf("Resolve: got err %v, want %v", err, wantErr)
+	}
+}
+
+func testResolve(t *testing.T, r evalopts.Resolver, url string, want fhir.Resource) {
+	t.Helper()
+	got, err := r.Resolve(url)
+	if err != nil {
+		t.Fatalf("Resolve(%q): unexpected err: %v", url, err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Resolve(%q) returned unexpected diff (-want, +got):\n%s", url, diff)
+	}
+}
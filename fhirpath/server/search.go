@@ -0,0 +1,229 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath"
+	"github.com/verily-src/fhirpath-go/fhirpath/system"
+	"github.com/verily-src/fhirpath-go/internal/bundle"
+	"github.com/verily-src/fhirpath-go/internal/element/reference"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/protofields"
+	"github.com/verily-src/fhirpath-go/internal/resource"
+)
+
+// searchParameters maps the names of the supported common search parameters
+// to the FHIRPath expressions that select their values from a resource.
+// Parameters not listed here select the field of the same name.
+var searchParameters = map[string]string{
+	"_id":          "id",
+	"_lastUpdated": "meta.lastUpdated",
+	"_profile":     "meta.profile",
+	"_tag":         "meta.tag",
+	"birthdate":    "birthDate",
+	"family":       "name.family",
+	"given":        "name.given",
+	"patient":      "subject",
+}
+
+// Search returns a searchset Bundle of the resources that match the given
+// search, which is of the form "Type?param=value&...". The type may be omitted
+// to search resources of all types, in which case resources of the types that
+// don't have the element of a parameter don't match. The entries of the Bundle
+// have the search mode "match".
+//
+// Values are matched according to the type of the searched element:
+//   - strings and names match if they start with the value, ignoring case, or
+//     equal the value with the ":exact" modifier;
+//   - codes, Codings, CodeableConcepts and Identifiers match a value of the
+//     form "code", "system|code" or "|code";
+//   - References match a value of the form "Type/id" or "id";
+//   - dates and times match if they start with the value, such as "2000-03"; and
+//   - any other value must be equal to the value.
+//
+// A parameter with comma-separated values matches any of them, and repeated
+// parameters must all match. The _count parameter limits the number of
+// entries in the Bundle, but not its total.
+func (m *Memory) Search(query string) (*bcrpb.Bundle, error) {
+	resourceType, rawQuery, _ := strings.Cut(strings.TrimPrefix(query, "/"), "?")
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSearch, err)
+	}
+	if resourceType != "" && !resource.IsType(resourceType) {
+		return nil, fmt.Errorf("%w: unknown resource type %s", ErrInvalidSearch, resourceType)
+	}
+	count := -1
+	if counts, ok := values["_count"]; ok {
+		if count, err = strconv.Atoi(counts[0]); err != nil || count < 0 {
+			return nil, fmt.Errorf("%w: _count must be a non-negative integer", ErrInvalidSearch)
+		}
+		delete(values, "_count")
+	}
+	filters, err := newFilters(values)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*bcrpb.Bundle_Entry
+	total := 0
+	for _, identity := range m.identities {
+		if resourceType != "" && string(identity.Type()) != resourceType {
+			continue
+		}
+		res := m.resources[identity]
+		ok, err := matchesAll(filters, res)
+		if resourceType == "" && errors.Is(err, fhirpath.ErrInvalidField) {
+			// A search across all types doesn't match the resources that lack
+			// the element of a parameter.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		total++
+		if count < 0 || len(entries) < count {
+			entries = append(entries, bundle.NewSearchsetEntry(res))
+		}
+	}
+	result := bundle.NewSearchset(bundle.WithEntries(entries...))
+	result.Total = fhir.UnsignedInt(uint32(total))
+	return result, nil
+}
+
+// filter is a single search parameter, which matches a resource if any of its
+// values match the elements selected by its expression.
+type filter struct {
+	name       string
+	expression *fhirpath.Expression
+	values     []string
+	exact      bool
+}
+
+func newFilters(values url.Values) ([]*filter, error) {
+	var filters []*filter
+	for param, occurrences := range values {
+		name, modifier, _ := strings.Cut(param, ":")
+		if modifier != "" && modifier != "exact" {
+			return nil, fmt.Errorf("%w: modifier %s", ErrUnsupportedParameter, modifier)
+		}
+		path, ok := searchParameters[name]
+		if !ok {
+			if strings.HasPrefix(name, "_") {
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedParameter, name)
+			}
+			path = name
+		}
+		expression, err := fhirpath.Compile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedParameter, name)
+		}
+		for _, occurrence := range occurrences {
+			filters = append(filters, &filter{
+				name:       name,
+				expression: expression,
+				values:     strings.Split(occurrence, ","),
+				exact:      modifier == "exact",
+			})
+		}
+	}
+	return filters, nil
+}
+
+func matchesAll(filters []*filter, res fhir.Resource) (bool, error) {
+	for _, f := range filters {
+		elements, err := f.expression.Evaluate([]fhir.Resource{res})
+		if err != nil {
+			return false, fmt.Errorf("%w: %s for %v: %w", ErrUnsupportedParameter, f.name, resource.TypeOf(res), err)
+		}
+		if !f.matchesAny(elements) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (f *filter) matchesAny(elements system.Collection) bool {
+	for _, element := range elements {
+		for _, value := range f.values {
+			if f.matches(element, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matches reports whether the element matches a single search value.
+func (f *filter) matches(element any, value string) bool {
+	switch v := element.(type) {
+	case *dtpb.String:
+		return f.matchesString(v.GetValue(), value)
+	case *dtpb.HumanName:
+		parts := append([]*dtpb.String{v.GetFamily(), v.GetText()}, v.GetGiven()...)
+		for _, part := range parts {
+			if f.matchesString(part.GetValue(), value) {
+				return true
+			}
+		}
+		return false
+	case *dtpb.Identifier:
+		return matchesToken(v.GetSystem().GetValue(), v.GetValue().GetValue(), value)
+	case *dtpb.Coding:
+		return matchesToken(v.GetSystem().GetValue(), v.GetCode().GetValue(), value)
+	case *dtpb.CodeableConcept:
+		for _, coding := range v.GetCoding() {
+			if f.matches(coding, value) {
+				return true
+			}
+		}
+		return false
+	case *dtpb.Reference:
+		info, err := reference.LiteralInfoOf(v)
+		if err != nil {
+			return false
+		}
+		identity, ok := info.Identity()
+		return ok && (identity.Unversioned().RelativeURIString() == value || identity.ID() == value)
+	case fhir.Base:
+		if code, ok := protofields.StringValueFromCodeField(v); ok {
+			return matchesToken("", code, value)
+		}
+	}
+	converted, err := system.From(element)
+	if err != nil {
+		return false
+	}
+	switch converted := converted.(type) {
+	case system.Date, system.DateTime, system.Time:
+		return strings.HasPrefix(fmt.Sprint(converted), value)
+	default:
+		return fmt.Sprint(converted) == value
+	}
+}
+
+func (f *filter) matchesString(s, value string) bool {
+	if f.exact {
+		return s == value
+	}
+	return strings.HasPrefix(strings.ToLower(s), strings.ToLower(value))
+}
+
+// matchesToken reports whether a code of a system matches a token search
+// value of the form "code", "system|code" or "|code".
+func matchesToken(system, code, value string) bool {
+	wantSystem, wantCode, hasSystem := strings.Cut(value, "|")
+	if !hasSystem {
+		return code == value
+	}
+	return system == wantSystem && code == wantCode
+}
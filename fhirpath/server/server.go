@@ -0,0 +1,183 @@
+/*
+Package server provides an in-memory implementation of evalopts.FHIRServer,
+which answers the queries of the methods of the FHIRPath %server constant, such
+as %server.read() and %server.search().
+
+The Memory server works entirely offline, over the resources that it is given:
+
+	store, err := server.NewMemory(patient, observation)
+	if err != nil {
+		return err
+	}
+	result, err := expression.Evaluate(input, evalopts.Server(store))
+*/
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
+	"github.com/verily-src/fhirpath-go/fhirpath/profile"
+	"github.com/verily-src/fhirpath-go/internal/element/reference"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/resource"
+)
+
+var (
+	ErrMissingID            = errors.New("resource has no id")
+	ErrUnsupportedMode      = errors.New("unsupported validation mode")
+	ErrUnsupportedParameter = errors.New("unsupported search parameter")
+	ErrInvalidSearch        = errors.New("invalid search")
+)
+
+// The modes of the $validate operation.
+const (
+	ModeCreate  = "create"
+	ModeUpdate  = "update"
+	ModeDelete  = "delete"
+	ModeProfile = "profile"
+)
+
+// Memory is a FHIRServer over in-memory resources, which are indexed by their
+// unversioned resource.Identity. It is also a Resolver for relative
+// references to those resources.
+//
+// Searches support a small set of common search parameters, along with any
+// parameter named after a field of the searched resources. See Search for
+// details.
+//
+// The StructureDefinition resources of the server are the profiles that
+// resources can be validated against.
+type Memory struct {
+	resources map[resource.Identity]fhir.Resource
+
+	// identities holds the identities of the resources, in the order that they
+	// were given, so that searches are deterministic.
+	identities []resource.Identity
+
+	profiles *profile.Registry
+}
+
+var (
+	_ evalopts.FHIRServer = (*Memory)(nil)
+	_ evalopts.Resolver   = (*Memory)(nil)
+)
+
+// NewMemory returns a Memory server over the given resources. A resource that
+// has the same identity as an earlier one replaces it. Resources without an id
+// result in an ErrMissingID error.
+func NewMemory(resources ...fhir.Resource) (*Memory, error) {
+	m := &Memory{resources: map[resource.Identity]fhir.Resource{}}
+	var definitions []*sdpb.StructureDefinition
+	for _, res := range resources {
+		identity, ok := resource.IdentityOf(res)
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", ErrMissingID, resource.TypeOf(res))
+		}
+		key := *identity.Unversioned()
+		if _, ok := m.resources[key]; !ok {
+			m.identities = append(m.identities, key)
+		}
+		m.resources[key] = res
+		if definition, ok := res.(*sdpb.StructureDefinition); ok {
+			definitions = append(definitions, definition)
+		}
+	}
+	profiles, err := profile.NewRegistry(definitions...)
+	if err != nil {
+		return nil, err
+	}
+	m.profiles = profiles
+	return m, nil
+}
+
+// Read returns the resource with the given type and id, or nil if there is no
+// such resource. An error is returned if the type is not a resource type.
+func (m *Memory) Read(resourceType, id string) (fhir.Resource, error) {
+	identity, err := resource.NewIdentity(resourceType, id, "")
+	if err != nil {
+		return nil, err
+	}
+	return m.resources[*identity], nil
+}
+
+// Resolve returns the resource identified by a relative or absolute URL of the
+// form "[base]/Type/id", or nil if there is no such resource.
+func (m *Memory) Resolve(url string) (fhir.Resource, error) {
+	if url == "" {
+		return nil, nil
+	}
+	info, err := reference.LiteralInfoFromURI(url)
+	if err != nil {
+		return nil, nil
+	}
+	identity, ok := info.Identity()
+	if !ok {
+		return nil, nil
+	}
+	return m.resources[*identity.Unversioned()], nil
+}
+
+// Validate validates the resource for the given mode of the $validate
+// operation. The resource must not exist on the server to be created, and
+// must exist to be updated or deleted. If a profile is given, the resource is
+// also validated against the StructureDefinition of the server with that
+// canonical URL.
+func (m *Memory) Validate(res fhir.Resource, mode, profile string) (*oopb.OperationOutcome, error) {
+	var issues []*oopb.OperationOutcome_Issue
+	identity, hasID := resource.IdentityOf(res)
+	var exists bool
+	if hasID {
+		_, exists = m.resources[*identity.Unversioned()]
+	}
+	switch mode {
+	case ModeCreate:
+		if exists {
+			issues = append(issues, issue(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_DUPLICATE,
+				fmt.Sprintf("resource %s already exists", identity.Unversioned().RelativeURIString())))
+		}
+	case ModeUpdate, ModeDelete:
+		if !hasID {
+			issues = append(issues, issue(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_REQUIRED,
+				fmt.Sprintf("resource must have an id to %s it", mode)))
+		} else if !exists {
+			issues = append(issues, issue(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_NOT_FOUND,
+				fmt.Sprintf("resource %s does not exist", identity.Unversioned().RelativeURIString())))
+		}
+	case ModeProfile, "":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMode, mode)
+	}
+	if profile != "" {
+		outcome, err := m.profiles.Validate(res, profile)
+		if err != nil {
+			return nil, err
+		}
+		if outcome == nil {
+			issues = append(issues, issue(cpb.IssueSeverityCode_ERROR, cpb.IssueTypeCode_NOT_FOUND,
+				fmt.Sprintf("profile %s is unknown", profile)))
+		}
+		for _, profileIssue := range outcome.GetIssue() {
+			if profileIssue.GetSeverity().GetValue() != cpb.IssueSeverityCode_INFORMATION {
+				issues = append(issues, profileIssue)
+			}
+		}
+	}
+	if len(issues) == 0 {
+		issues = append(issues, issue(cpb.IssueSeverityCode_INFORMATION, cpb.IssueTypeCode_INFORMATIONAL,
+			"validation successful"))
+	}
+	return &oopb.OperationOutcome{Issue: issues}, nil
+}
+
+func issue(severity cpb.IssueSeverityCode_Value, code cpb.IssueTypeCode_Value, diagnostics string) *oopb.OperationOutcome_Issue {
+	return &oopb.OperationOutcome_Issue{
+		Severity:    &oopb.OperationOutcome_Issue_SeverityCode{Value: severity},
+		Code:        &oopb.OperationOutcome_Issue_CodeType{Value: code},
+		Diagnostics: fhir.String(diagnostics),
+	}
+}
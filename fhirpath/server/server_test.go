@@ -0,0 +1,308 @@
+package server_test
+
+import (
+	"errors"
+	"testing"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/server"
+	"github.com/verily-src/fhirpath-go/internal/bundle"
+	"github.com/verily-src/fhirpath-go/internal/element/reference"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/resource"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+var (
+	alice = &ppb.Patient{
+		Id:        fhir.ID("alice"),
+		Meta:      &dtpb.Meta{VersionId: fhir.ID("3")},
+		Gender:    &ppb.Patient_GenderCode{Value: cpb.AdministrativeGenderCode_FEMALE},
+		BirthDate: fhir.MustParseDate("1990-05-17"),
+		Name: []*dtpb.HumanName{
+			{Family: fhir.String("Smith"), Given: []*dtpb.String{fhir.String("Alice")}},
+		},
+		Identifier: []*dtpb.Identifier{
+			{System: fhir.URI("http://example.com/mrn"), Value: fhir.String("A-1")},
+		},
+	}
+	bob = &ppb.Patient{
+		Id:        fhir.ID("bob"),
+		Gender:    &ppb.Patient_GenderCode{Value: cpb.AdministrativeGenderCode_MALE},
+		BirthDate: fhir.MustParseDate("1985-11-02"),
+		Name: []*dtpb.HumanName{
+			{Family: fhir.String("Smithers"), Given: []*dtpb.String{fhir.String("Bob")}},
+		},
+		Active: fhir.Boolean(true),
+	}
+	heartRate = &opb.Observation{
+		Id:      fhir.ID("heart-rate"),
+		Code:    fhir.CodeableConcept("", fhir.Coding("http://loinc.org", "8867-4")),
+		Subject: reference.Weak(resource.Patient, "Patient/alice"),
+	}
+	temperature = &opb.Observation{
+		Id:      fhir.ID("temperature"),
+		Code:    fhir.CodeableConcept("", fhir.Coding("http://loinc.org", "8310-5")),
+		Subject: reference.Weak(resource.Patient, "Patient/bob"),
+	}
+	named = &sdpb.StructureDefinition{
+		Id:   fhir.ID("named"),
+		Url:  fhir.URI(namedProfile),
+		Type: fhir.URI("Patient"),
+		Differential: &sdpb.StructureDefinition_Differential{
+			Element: []*dtpb.ElementDefinition{
+				{Path: fhir.String("Patient.name"), Min: fhir.UnsignedInt(1), Max: fhir.String("*")},
+			},
+		},
+	}
+)
+
+const namedProfile = "http://example.com/StructureDefinition/named"
+
+func newMemory(t *testing.T) *server.Memory {
+	t.Helper()
+	m, err := server.NewMemory(alice, bob, heartRate, temperature, named)
+	if err != nil {
+		t.Fatalf("NewMemory: unexpected err: %v", err)
+	}
+	return m
+}
+
+func TestNewMemory_MissingID_ReturnsError(t *testing.T) {
+	_, err := server.NewMemory(&ppb.Patient{})
+
+	if !errors.Is(err, server.ErrMissingID) {
+		t.Errorf("NewMemory: got err %v, want %v", err, server.ErrMissingID)
+	}
+}
+
+func TestMemory_Read(t *testing.T) {
+	m := newMemory(t)
+	testCases := []struct {
+		name         string
+		resourceType string
+		id           string
+		want         fhir.Resource
+	}{
+		{"versioned resource", "Patient", "alice", alice},
+		{"unversioned resource", "Observation", "temperature", temperature},
+		{"unknown id", "Patient", "carol", nil},
+		{"id of another type", "Observation", "alice", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := m.Read(tc.resourceType, tc.id)
+			if err != nil {
+				t.Fatalf("Read: unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Read(%v, %v) returned unexpected diff (-want, +got):\n%s", tc.resourceType, tc.id, diff)
+			}
+		})
+	}
+}
+
+func TestMemory_Read_UnknownType_ReturnsError(t *testing.T) {
+	m := newMemory(t)
+
+	if _, err := m.Read("NotAType", "alice"); err == nil {
+		t.Errorf("Read: expected error for unknown resource type")
+	}
+}
+
+func TestMemory_Resolve(t *testing.T) {
+	m := newMemory(t)
+	testCases := []struct {
+		name string
+		url  string
+		want fhir.Resource
+	}{
+		{"relative url", "Patient/bob", bob},
+		{"absolute url", "https://example.com/fhir/Patient/alice", alice},
+		{"versioned url", "Patient/alice/_history/1", alice},
+		{"unknown resource", "Patient/carol", nil},
+		{"fragment", "#alice", nil},
+		{"empty url", "", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := m.Resolve(tc.url)
+			if err != nil {
+				t.Fatalf("Resolve: unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Resolve(%v) returned unexpected diff (-want, +got):\n%s", tc.url, diff)
+			}
+		})
+	}
+}
+
+func TestMemory_Search(t *testing.T) {
+	m := newMemory(t)
+	testCases := []struct {
+		name      string
+		query     string
+		wantIDs   []string
+		wantTotal uint32
+	}{
+		{"all resources of a type", "Patient", []string{"alice", "bob"}, 2},
+		{"all resources", "?", []string{"alice", "bob", "heart-rate", "temperature", "named"}, 5},
+		{"leading slash", "/Observation", []string{"heart-rate", "temperature"}, 2},
+		{"by _id", "Patient?_id=bob", []string{"bob"}, 1},
+		{"by _id across types", "?_id=heart-rate", []string{"heart-rate"}, 1},
+		{"by parameter of some types", "?family=smith", []string{"alice", "bob"}, 2},
+		{"by parameters of different types", "?family=smith&code=8310-5", nil, 0},
+		{"by string prefix", "Patient?family=smith", []string{"alice", "bob"}, 2},
+		{"by exact string", "Patient?family:exact=Smith", []string{"alice"}, 1},
+		{"by name", "Patient?name=bo", []string{"bob"}, 1},
+		{"by code", "Patient?gender=female", []string{"alice"}, 1},
+		{"by identifier with system", "Patient?identifier=http://example.com/mrn|A-1", []string{"alice"}, 1},
+		{"by identifier of another system", "Patient?identifier=http://example.com/other|A-1", nil, 0},
+		{"by coding", "Observation?code=http://loinc.org|8310-5", []string{"temperature"}, 1},
+		{"by reference", "Observation?subject=Patient/alice", []string{"heart-rate"}, 1},
+		{"by reference id", "Observation?patient=bob", []string{"temperature"}, 1},
+		{"by date prefix", "Patient?birthdate=1990-05", []string{"alice"}, 1},
+		{"by boolean field", "Patient?active=true", []string{"bob"}, 1},
+		{"by any of several values", "Patient?given=alice,bob", []string{"alice", "bob"}, 2},
+		{"by all of several parameters", "Patient?family=smith&gender=male", []string{"bob"}, 1},
+		{"with count", "Patient?_count=1", []string{"alice"}, 2},
+		{"without match", "Patient?family=jones", nil, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := m.Search(tc.query)
+			if err != nil {
+				t.Fatalf("Search: unexpected err: %v", err)
+			}
+			if got.GetType().GetValue() != cpb.BundleTypeCode_SEARCHSET {
+				t.Errorf("Search(%v) returned Bundle of type %v, want searchset", tc.query, got.GetType().GetValue())
+			}
+			if diff := cmp.Diff(tc.wantIDs, ids(got)); diff != "" {
+				t.Errorf("Search(%v) returned unexpected diff (-want, +got):\n%s", tc.query, diff)
+			}
+			if total := got.GetTotal().GetValue(); total != tc.wantTotal {
+				t.Errorf("Search(%v) returned total %v, want %v", tc.query, total, tc.wantTotal)
+			}
+		})
+	}
+}
+
+func TestMemory_Search_EntriesAreMatches(t *testing.T) {
+	m := newMemory(t)
+
+	got, err := m.Search("Patient?_id=alice")
+	if err != nil {
+		t.Fatalf("Search: unexpected err: %v", err)
+	}
+
+	want := []*bcrpb.Bundle_Entry{
+		{
+			Resource: &bcrpb.ContainedResource{
+				OneofResource: &bcrpb.ContainedResource_Patient{Patient: alice},
+			},
+			FullUrl: fhir.URI("Patient/alice"),
+			Search: &bcrpb.Bundle_Entry_Search{
+				Mode: &bcrpb.Bundle_Entry_Search_ModeCode{Value: cpb.SearchEntryModeCode_MATCH},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got.GetEntry(), protocmp.Transform()); diff != "" {
+		t.Errorf("Search returned unexpected entries (-want, +got):\n%s", diff)
+	}
+}
+
+func ids(b *bcrpb.Bundle) []string {
+	var result []string
+	for _, res := range bundle.Unwrap(b) {
+		result = append(result, res.GetId().GetValue())
+	}
+	return result
+}
+
+func TestMemory_Search_ReturnsError(t *testing.T) {
+	m := newMemory(t)
+	testCases := []struct {
+		name    string
+		query   string
+		wantErr error
+	}{
+		{"unknown resource type", "NotAType?_id=1", server.ErrInvalidSearch},
+		{"invalid count", "Patient?_count=many", server.ErrInvalidSearch},
+		{"unsupported modifier", "Patient?family:contains=mit", server.ErrUnsupportedParameter},
+		{"unsupported result parameter", "Patient?_sort=family", server.ErrUnsupportedParameter},
+		{"parameter for another type", "Observation?family=smith", server.ErrUnsupportedParameter},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := m.Search(tc.query)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Search(%v): got err %v, want %v", tc.query, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMemory_Validate(t *testing.T) {
+	m := newMemory(t)
+	carol := &ppb.Patient{Id: fhir.ID("carol")}
+	testCases := []struct {
+		name     string
+		res      fhir.Resource
+		mode     string
+		profile  string
+		wantCode cpb.IssueTypeCode_Value
+	}{
+		{"create new resource", carol, server.ModeCreate, "", cpb.IssueTypeCode_INFORMATIONAL},
+		{"create resource without id", &ppb.Patient{}, server.ModeCreate, "", cpb.IssueTypeCode_INFORMATIONAL},
+		{"create existing resource", alice, server.ModeCreate, "", cpb.IssueTypeCode_DUPLICATE},
+		{"update existing resource", alice, server.ModeUpdate, "", cpb.IssueTypeCode_INFORMATIONAL},
+		{"update new resource", carol, server.ModeUpdate, "", cpb.IssueTypeCode_NOT_FOUND},
+		{"delete resource without id", &ppb.Patient{}, server.ModeDelete, "", cpb.IssueTypeCode_REQUIRED},
+		{"validate without mode", carol, "", "", cpb.IssueTypeCode_INFORMATIONAL},
+		{"validate conforming resource against profile", alice, server.ModeProfile, namedProfile, cpb.IssueTypeCode_INFORMATIONAL},
+		{"validate non-conforming resource against profile", carol, server.ModeProfile, namedProfile, cpb.IssueTypeCode_REQUIRED},
+		{"validate against unknown profile", carol, server.ModeProfile, "http://example.com/StructureDefinition/p", cpb.IssueTypeCode_NOT_FOUND},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := m.Validate(tc.res, tc.mode, tc.profile)
+			if err != nil {
+				t.Fatalf("Validate: unexpected err: %v", err)
+			}
+			if codes := issueCodes(got); len(codes) != 1 || codes[0] != tc.wantCode {
+				t.Errorf("Validate(%v) returned issues %v, want %v", tc.name, codes, tc.wantCode)
+			}
+		})
+	}
+}
+
+func issueCodes(outcome *oopb.OperationOutcome) []cpb.IssueTypeCode_Value {
+	var codes []cpb.IssueTypeCode_Value
+	for _, issue := range outcome.GetIssue() {
+		codes = append(codes, issue.GetCode().GetValue())
+	}
+	return codes
+}
+
+func TestMemory_Validate_UnsupportedMode_ReturnsError(t *testing.T) {
+	m := newMemory(t)
+
+	_, err := m.Validate(alice, "patch", "")
+
+	if !errors.Is(err, server.ErrUnsupportedMode) {
+		t.Errorf("Validate: got err %v, want %v", err, server.ErrUnsupportedMode)
+	}
+}
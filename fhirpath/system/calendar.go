@@ -0,0 +1,110 @@
+package system
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// calendarUnits maps the singular calendar duration keywords to the component
+// that a value must be precise to for the unit to apply.
+var calendarUnits = map[string]Component{
+	"year":        YearComponent,
+	"month":       MonthComponent,
+	"week":        DayComponent,
+	"day":         DayComponent,
+	"hour":        HourComponent,
+	"minute":      MinuteComponent,
+	"second":      SecondComponent,
+	"millisecond": MillisecondComponent,
+}
+
+// calendarUnit returns the singular form of the calendar duration keyword
+// unit, along with the component that it requires. Returns an error if unit is
+// not a calendar duration keyword.
+func calendarUnit(unit string) (string, Component, error) {
+	unit = normalizeUnit(unit)
+	c, ok := calendarUnits[unit]
+	if !ok {
+		return "", 0, fmt.Errorf("%w: '%s' is not a calendar duration", ErrMismatchedUnit, unit)
+	}
+	return unit, c, nil
+}
+
+// calendarQuantity returns a Quantity of value in the plural form of the
+// given calendar duration keyword.
+func calendarQuantity(value int, unit string) Quantity {
+	return Quantity{Decimal(decimal.NewFromInt(int64(value))), unit + "s"}
+}
+
+// calendarDuration returns the number of whole periods of the given unit that
+// elapse from "from" until "to". Years and months follow calendar semantics,
+// so that 2020-01-31 to 2020-02-29 is one month. The result is negative if
+// "to" is before "from".
+func calendarDuration(from, to time.Time, unit string) int {
+	if to.Before(from) {
+		return -calendarDuration(to, from, unit)
+	}
+	switch unit {
+	case "year":
+		years := to.Year() - from.Year()
+		if addYear(from, years).After(to) {
+			years--
+		}
+		return years
+	case "month":
+		months := monthsBetween(from, to)
+		if addMonth(from, months).After(to) {
+			months--
+		}
+		return months
+	case "week":
+		return int(to.Sub(from) / (7 * 24 * time.Hour))
+	case "day":
+		return int(to.Sub(from) / (24 * time.Hour))
+	case "hour":
+		return int(to.Sub(from) / time.Hour)
+	case "minute":
+		return int(to.Sub(from) / time.Minute)
+	case "second":
+		return int(to.Sub(from) / time.Second)
+	default:
+		return int(to.Sub(from) / time.Millisecond)
+	}
+}
+
+// calendarDifference returns the number of boundaries of the given unit that
+// are crossed from "from" until "to", such that 2020-12-31 to 2021-01-01 is a
+// difference of one year. The result is negative if "to" is before "from".
+func calendarDifference(from, to time.Time, unit string) int {
+	switch unit {
+	case "year":
+		return to.Year() - from.Year()
+	case "month":
+		return monthsBetween(from, to)
+	case "week":
+		return int(startOfDay(to).Sub(startOfDay(from)) / (7 * 24 * time.Hour))
+	case "day":
+		return int(startOfDay(to).Sub(startOfDay(from)) / (24 * time.Hour))
+	case "hour":
+		return int(to.Truncate(time.Hour).Sub(from.Truncate(time.Hour)) / time.Hour)
+	case "minute":
+		return int(to.Truncate(time.Minute).Sub(from.Truncate(time.Minute)) / time.Minute)
+	case "second":
+		return int(to.Truncate(time.Second).Sub(from.Truncate(time.Second)) / time.Second)
+	default:
+		return int(to.Truncate(time.Millisecond).Sub(from.Truncate(time.Millisecond)) / time.Millisecond)
+	}
+}
+
+// monthsBetween returns the difference between the months of the two times,
+// ignoring all smaller components.
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+// startOfDay returns midnight at the start of the day that t falls in.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
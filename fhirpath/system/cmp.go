@@ -79,3 +79,25 @@ func callBinaryComparator(name string, lhs, rhs any) (any, bool) {
 	}
 	return nil, false
 }
+
+// equivalenter is implemented by system types that define custom
+// equivalence semantics.
+type equivalenter interface {
+	Equivalent(input Any) bool
+}
+
+// Equivalent compares two FHIRPath System types for equivalence. Unlike
+// equality, equivalence always yields a value: values of mismatched precision
+// or type are simply not equivalent.
+//
+// See https://hl7.org/fhirpath/n1/#equivalence
+//
+// For system types that define a custom "Equivalent" function, this will call
+// the underlying function. Otherwise, this falls back to equality.
+func Equivalent(lhs, rhs Any) bool {
+	lhs, rhs = Normalize(lhs, rhs), Normalize(rhs, lhs)
+	if eq, ok := lhs.(equivalenter); ok {
+		return eq.Equivalent(rhs)
+	}
+	return Equal(lhs, rhs)
+}
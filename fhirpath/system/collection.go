@@ -9,6 +9,7 @@ import (
 	"github.com/verily-src/fhirpath-go/internal/fhir"
 	"github.com/verily-src/fhirpath-go/internal/narrow"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 var (
@@ -74,6 +75,105 @@ func (c Collection) TryEqual(other Collection) (bool, bool) {
 	return true, true
 }
 
+// Equivalent compares this collection to the supplied collection for
+// equivalence. Two empty collections are equivalent, and collections of
+// different lengths are not. Otherwise, every entry in c must be equivalent to
+// a distinct entry in other, regardless of order. Complex types are compared
+// recursively, by the equivalence of each of their child elements.
+//
+// See https://hl7.org/fhirpath/n1/#equivalence
+func (c Collection) Equivalent(other Collection) bool {
+	if len(c) != len(other) {
+		return false
+	}
+
+	matched := make([]bool, len(other))
+	for _, item := range c {
+		found := false
+		for j, candidate := range other {
+			if matched[j] || !equivalentItems(item, candidate) {
+				continue
+			}
+			matched[j], found = true, true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// equivalentItems compares two entries of a collection for equivalence.
+// Primitive values are compared with system equivalence, while complex
+// types are compared field by field.
+func equivalentItems(lhs, rhs any) bool {
+	lhsPrimitive, rhsPrimitive := IsPrimitive(lhs), IsPrimitive(rhs)
+	if lhsPrimitive != rhsPrimitive {
+		return false
+	}
+	if lhsPrimitive {
+		l, err := From(lhs)
+		if err != nil {
+			return false
+		}
+		r, err := From(rhs)
+		if err != nil {
+			return false
+		}
+		return Equivalent(l, r)
+	}
+
+	lhsMsg, ok := lhs.(proto.Message)
+	if !ok {
+		return false
+	}
+	rhsMsg, ok := rhs.(proto.Message)
+	if !ok {
+		return false
+	}
+	return equivalentMessages(lhsMsg.ProtoReflect(), rhsMsg.ProtoReflect())
+}
+
+// equivalentMessages recursively compares every field of the two messages
+// for equivalence.
+func equivalentMessages(lhs, rhs protoreflect.Message) bool {
+	if lhs.Descriptor().FullName() != rhs.Descriptor().FullName() {
+		return false
+	}
+	fields := lhs.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Kind() != protoreflect.MessageKind {
+			if !lhs.Get(field).Equal(rhs.Get(field)) {
+				return false
+			}
+			continue
+		}
+		if !messageValues(lhs, field).Equivalent(messageValues(rhs, field)) {
+			return false
+		}
+	}
+	return true
+}
+
+// messageValues returns the messages contained in the given field of msg as
+// a collection.
+func messageValues(msg protoreflect.Message, field protoreflect.FieldDescriptor) Collection {
+	if !msg.Has(field) {
+		return Collection{}
+	}
+	if !field.IsList() {
+		return Collection{msg.Get(field).Message().Interface()}
+	}
+	list := msg.Get(field).List()
+	result := make(Collection, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		result = append(result, list.Get(i).Message().Interface())
+	}
+	return result
+}
+
 // ToSingletonBoolean evaluates a collection as a boolean with singleton evaluation of
 // collection rules. Returns a collection containing a single Boolean, or empty if the
 // input is empty.
@@ -358,3 +358,98 @@ func TestContains(t *testing.T) {
 		})
 	}
 }
+
+func TestCollection_Equivalent(t *testing.T) {
+	testCases := []struct {
+		name  string
+		left  system.Collection
+		right system.Collection
+		want  bool
+	}{
+		{
+			name:  "empty collections",
+			left:  system.Collection{},
+			right: system.Collection{},
+			want:  true,
+		},
+		{
+			name:  "one empty collection",
+			left:  system.Collection{system.String("a")},
+			right: system.Collection{},
+			want:  false,
+		},
+		{
+			name:  "mismatched collection lengths",
+			left:  system.Collection{system.String("a")},
+			right: system.Collection{system.String("a"), system.String("a")},
+			want:  false,
+		},
+		{
+			name:  "collections in different order",
+			left:  system.Collection{system.String("a"), fhir.String("B")},
+			right: system.Collection{system.String("b"), system.String("A")},
+			want:  true,
+		},
+		{
+			name:  "collections with different duplicates",
+			left:  system.Collection{system.String("a"), system.String("a"), system.String("b")},
+			right: system.Collection{system.String("a"), system.String("b"), system.String("b")},
+			want:  false,
+		},
+		{
+			name: "complex types compared recursively",
+			left: system.Collection{&dtpb.Coding{
+				System:  fhir.URI("http://loinc.org"),
+				Code:    fhir.Code("1234-5"),
+				Display: fhir.String("Body  Weight"),
+			}},
+			right: system.Collection{&dtpb.Coding{
+				System:  fhir.URI("http://loinc.org"),
+				Code:    fhir.Code("1234-5"),
+				Display: fhir.String("body weight"),
+			}},
+			want: true,
+		},
+		{
+			name: "complex types with repeated fields in different order",
+			left: system.Collection{&dtpb.HumanName{
+				Given: []*dtpb.String{fhir.String("a"), fhir.String("b")},
+			}},
+			right: system.Collection{&dtpb.HumanName{
+				Given: []*dtpb.String{fhir.String("B"), fhir.String("A")},
+			}},
+			want: true,
+		},
+		{
+			name: "complex types with missing field",
+			left: system.Collection{&dtpb.Coding{
+				System: fhir.URI("http://loinc.org"),
+				Code:   fhir.Code("1234-5"),
+			}},
+			right: system.Collection{&dtpb.Coding{
+				Code: fhir.Code("1234-5"),
+			}},
+			want: false,
+		},
+		{
+			name:  "mismatched complex types",
+			left:  system.Collection{&dtpb.Coding{}},
+			right: system.Collection{&dtpb.HumanName{}},
+			want:  false,
+		},
+		{
+			name:  "primitive and complex type",
+			left:  system.Collection{system.String("a")},
+			right: system.Collection{&dtpb.Coding{}},
+			want:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.left.Equivalent(tc.right); got != tc.want {
+				t.Errorf("Collection.Equivalent() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -24,3 +24,7 @@ const (
 	quantityType = "Quantity"
 	anyType      = "Any"
 )
+
+// maxDecimalPrecision is the maximum number of digits after the decimal point
+// that a Decimal is required to support.
+const maxDecimalPrecision = 28
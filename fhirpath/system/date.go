@@ -211,6 +211,70 @@ func (d Date) Sub(input Quantity) (Date, error) {
 	return Date{result, d.l}, nil
 }
 
+// Equivalent returns true if input is a Date with the same precision and
+// value as d. Unlike equality, values of mismatched precision are never
+// equivalent.
+func (d Date) Equivalent(input Any) bool {
+	result, ok := d.TryEqual(input)
+	return result && ok
+}
+
+// Precision returns the number of digits of precision represented by d: 4 for
+// a year, 6 for a month and 8 for a day.
+func (d Date) Precision() int {
+	return precisionDigits[d.l]
+}
+
+// LowBoundary returns the earliest Date that d may represent, at the given
+// precision. Returns false if the precision is not 4, 6 or 8.
+func (d Date) LowBoundary(precision int) (Date, bool) {
+	l, ok := dateLayoutsByPrecision[precision]
+	if !ok {
+		return Date{}, false
+	}
+	date, err := truncate(d.date, l)
+	if err != nil {
+		return Date{}, false
+	}
+	return Date{date, l}, true
+}
+
+// HighBoundary returns the latest Date that d may represent, at the given
+// precision. Eg. the high boundary of 2020-02 is 2020-02-29. Returns false if
+// the precision is not 4, 6 or 8, or if the boundary is out of range.
+func (d Date) HighBoundary(precision int) (Date, bool) {
+	l, ok := dateLayoutsByPrecision[precision]
+	if !ok {
+		return Date{}, false
+	}
+	date, err := truncate(nextPeriod(d.date, d.l).AddDate(0, 0, -1), l)
+	if err != nil {
+		return Date{}, false
+	}
+	return Date{date, l}, true
+}
+
+// Component returns the value of the given component of d. Returns false if
+// the component is not a date component, or exceeds the precision of d.
+func (d Date) Component(c Component) (int, bool) {
+	if c > DayComponent || componentDigits[c] > d.Precision() {
+		return 0, false
+	}
+	return component(d.date, c), true
+}
+
+// Duration returns the number of whole calendar periods of the given unit
+// from d until input. See DateTime.Duration.
+func (d Date) Duration(input Date, unit string) (Quantity, bool, error) {
+	return d.ToDateTime().Duration(input.ToDateTime(), unit)
+}
+
+// Difference returns the number of boundaries of the given unit that are
+// crossed from d until input. See DateTime.Difference.
+func (d Date) Difference(input Date, unit string) (Quantity, bool, error) {
+	return d.ToDateTime().Difference(input.ToDateTime(), unit)
+}
+
 // Name returns the type name.
 func (d Date) Name() string {
 	return dateType
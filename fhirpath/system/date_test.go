@@ -436,3 +436,225 @@ func TestDate_ToDateTime(t *testing.T) {
 		})
 	}
 }
+
+func TestDate_Precision(t *testing.T) {
+	testCases := []struct {
+		name string
+		date system.Date
+		want int
+	}{
+		{"Year", system.MustParseDate("2020"), 4},
+		{"Month", system.MustParseDate("2020-03"), 6},
+		{"Day", system.MustParseDate("2020-03-15"), 8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.date.Precision(); got != tc.want {
+				t.Errorf("Date.Precision() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDate_Boundaries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		date      system.Date
+		precision int
+		wantLow   system.Date
+		wantHigh  system.Date
+	}{
+		{
+			name:      "partial month to day",
+			date:      system.MustParseDate("2020-03"),
+			precision: 8,
+			wantLow:   system.MustParseDate("2020-03-01"),
+			wantHigh:  system.MustParseDate("2020-03-31"),
+		},
+		{
+			name:      "leap year month to day",
+			date:      system.MustParseDate("2020-02"),
+			precision: 8,
+			wantLow:   system.MustParseDate("2020-02-01"),
+			wantHigh:  system.MustParseDate("2020-02-29"),
+		},
+		{
+			name:      "year to month",
+			date:      system.MustParseDate("2014"),
+			precision: 6,
+			wantLow:   system.MustParseDate("2014-01"),
+			wantHigh:  system.MustParseDate("2014-12"),
+		},
+		{
+			name:      "day to year",
+			date:      system.MustParseDate("2014-05-20"),
+			precision: 4,
+			wantLow:   system.MustParseDate("2014"),
+			wantHigh:  system.MustParseDate("2014"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			low, ok := tc.date.LowBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Date.LowBoundary(%v) returned not ok", tc.precision)
+			}
+			if !cmp.Equal(low, tc.wantLow) {
+				t.Errorf("Date.LowBoundary(%v) = %v, want %v", tc.precision, low, tc.wantLow)
+			}
+			high, ok := tc.date.HighBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Date.HighBoundary(%v) returned not ok", tc.precision)
+			}
+			if !cmp.Equal(high, tc.wantHigh) {
+				t.Errorf("Date.HighBoundary(%v) = %v, want %v", tc.precision, high, tc.wantHigh)
+			}
+		})
+	}
+}
+
+func TestDate_Boundaries_InvalidPrecision(t *testing.T) {
+	date := system.MustParseDate("2020-03")
+
+	if _, ok := date.LowBoundary(10); ok {
+		t.Errorf("Date.LowBoundary(10) returned ok, want not ok")
+	}
+	if _, ok := date.HighBoundary(5); ok {
+		t.Errorf("Date.HighBoundary(5) returned ok, want not ok")
+	}
+}
+
+func TestDate_Component(t *testing.T) {
+	testCases := []struct {
+		name      string
+		date      system.Date
+		component system.Component
+		want      int
+		wantOk    bool
+	}{
+		{"year", system.MustParseDate("2020-03-15"), system.YearComponent, 2020, true},
+		{"month", system.MustParseDate("2020-03-15"), system.MonthComponent, 3, true},
+		{"day", system.MustParseDate("2020-03-15"), system.DayComponent, 15, true},
+		{"day exceeds precision", system.MustParseDate("2020-03"), system.DayComponent, 0, false},
+		{"hour is not a date component", system.MustParseDate("2020-03-15"), system.HourComponent, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := tc.date.Component(tc.component)
+			if got != tc.want || ok != tc.wantOk {
+				t.Errorf("Date.Component(%v) = (%v, %v), want (%v, %v)", tc.component, got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestDate_DurationAndDifference(t *testing.T) {
+	testCases := []struct {
+		name           string
+		from           system.Date
+		to             system.Date
+		unit           string
+		wantDuration   system.Quantity
+		wantDifference system.Quantity
+	}{
+		{
+			name:           "years before birthday",
+			from:           system.MustParseDate("2000-03-22"),
+			to:             system.MustParseDate("2024-03-21"),
+			unit:           "years",
+			wantDuration:   system.MustParseQuantity("23", "years"),
+			wantDifference: system.MustParseQuantity("24", "years"),
+		},
+		{
+			name:           "years on birthday",
+			from:           system.MustParseDate("2000-03-22"),
+			to:             system.MustParseDate("2024-03-22"),
+			unit:           "year",
+			wantDuration:   system.MustParseQuantity("24", "years"),
+			wantDifference: system.MustParseQuantity("24", "years"),
+		},
+		{
+			name:           "leap day anniversary",
+			from:           system.MustParseDate("2000-02-29"),
+			to:             system.MustParseDate("2001-02-28"),
+			unit:           "years",
+			wantDuration:   system.MustParseQuantity("1", "years"),
+			wantDifference: system.MustParseQuantity("1", "years"),
+		},
+		{
+			name:           "months across end of month",
+			from:           system.MustParseDate("2020-01-31"),
+			to:             system.MustParseDate("2020-02-29"),
+			unit:           "months",
+			wantDuration:   system.MustParseQuantity("1", "months"),
+			wantDifference: system.MustParseQuantity("1", "months"),
+		},
+		{
+			name:           "month boundary crossed",
+			from:           system.MustParseDate("2020-01-31"),
+			to:             system.MustParseDate("2020-02-01"),
+			unit:           "months",
+			wantDuration:   system.MustParseQuantity("0", "months"),
+			wantDifference: system.MustParseQuantity("1", "months"),
+		},
+		{
+			name:           "negative days",
+			from:           system.MustParseDate("2020-03-10"),
+			to:             system.MustParseDate("2020-03-01"),
+			unit:           "days",
+			wantDuration:   system.MustParseQuantity("-9", "days"),
+			wantDifference: system.MustParseQuantity("-9", "days"),
+		},
+		{
+			name:           "weeks",
+			from:           system.MustParseDate("2020-03-01"),
+			to:             system.MustParseDate("2020-03-20"),
+			unit:           "weeks",
+			wantDuration:   system.MustParseQuantity("2", "weeks"),
+			wantDifference: system.MustParseQuantity("2", "weeks"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok, err := tc.from.Duration(tc.to, tc.unit)
+			if err != nil || !ok {
+				t.Fatalf("Date.Duration() returned (%v, %v), want ok", ok, err)
+			}
+			if !got.Equal(tc.wantDuration) {
+				t.Errorf("Date.Duration() = %v, want %v", got, tc.wantDuration)
+			}
+			got, ok, err = tc.from.Difference(tc.to, tc.unit)
+			if err != nil || !ok {
+				t.Fatalf("Date.Difference() returned (%v, %v), want ok", ok, err)
+			}
+			if !got.Equal(tc.wantDifference) {
+				t.Errorf("Date.Difference() = %v, want %v", got, tc.wantDifference)
+			}
+		})
+	}
+}
+
+func TestDate_Duration_ExceedsPrecision(t *testing.T) {
+	from := system.MustParseDate("2020-03")
+	to := system.MustParseDate("2021-05-01")
+
+	if _, ok, err := from.Duration(to, "days"); ok || err != nil {
+		t.Errorf("Date.Duration() returned (%v, %v), want (false, nil)", ok, err)
+	}
+	if _, ok, err := from.Difference(to, "hours"); ok || err != nil {
+		t.Errorf("Date.Difference() returned (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+func TestDate_Duration_InvalidUnit(t *testing.T) {
+	from := system.MustParseDate("2020-03-01")
+	to := system.MustParseDate("2021-05-01")
+
+	if _, _, err := from.Duration(to, "mg"); !errors.Is(err, system.ErrMismatchedUnit) {
+		t.Errorf("Date.Duration() returned error %v, want %v", err, system.ErrMismatchedUnit)
+	}
+}
@@ -243,6 +243,154 @@ func (dt DateTime) Sub(input Quantity) (DateTime, error) {
 	return DateTime{result, dt.l}, nil
 }
 
+// Equivalent returns true if input is a DateTime with the same precision and
+// value as dt. Unlike equality, values of mismatched precision are never
+// equivalent.
+func (dt DateTime) Equivalent(input Any) bool {
+	result, ok := dt.TryEqual(input)
+	return result && ok
+}
+
+// Precision returns the number of digits of precision represented by dt,
+// ranging from 4 for a year to 17 for a millisecond.
+func (dt DateTime) Precision() int {
+	return precisionDigits[dt.l]
+}
+
+// LowBoundary returns the earliest DateTime that dt may represent, at the
+// given precision. The timezone offset of dt, if any, is retained. Returns
+// false if the precision is not one of 4, 6, 8, 10, 12, 14 or 17.
+func (dt DateTime) LowBoundary(precision int) (DateTime, bool) {
+	l, ok := dt.layoutForPrecision(precision)
+	if !ok {
+		return DateTime{}, false
+	}
+	result, err := truncate(dt.dateTime, l)
+	if err != nil {
+		return DateTime{}, false
+	}
+	return DateTime{result, l}, true
+}
+
+// HighBoundary returns the latest DateTime that dt may represent, at the
+// given precision. Eg. the high boundary of 2020-03 at millisecond precision
+// is 2020-03-31T23:59:59.999. The timezone offset of dt, if any, is retained.
+// Returns false if the precision is not one of 4, 6, 8, 10, 12, 14 or 17, or
+// if the boundary is out of range.
+func (dt DateTime) HighBoundary(precision int) (DateTime, bool) {
+	l, ok := dt.layoutForPrecision(precision)
+	if !ok {
+		return DateTime{}, false
+	}
+	result, err := truncate(nextPeriod(dt.dateTime, dt.l).Add(-time.Millisecond), l)
+	if err != nil {
+		return DateTime{}, false
+	}
+	return DateTime{result, l}, true
+}
+
+// layoutForPrecision returns the layout representing the given precision,
+// including a timezone offset if dt has one.
+func (dt DateTime) layoutForPrecision(precision int) (layout, bool) {
+	if hasTimezone(dt.l) {
+		if l, ok := dateTimeLayoutsByPrecisionTZ[precision]; ok {
+			return l, true
+		}
+	}
+	l, ok := dateTimeLayoutsByPrecision[precision]
+	return l, ok
+}
+
+// Component returns the value of the given component of dt. Returns false if
+// the component exceeds the precision of dt.
+func (dt DateTime) Component(c Component) (int, bool) {
+	if componentDigits[c] > dt.Precision() {
+		return 0, false
+	}
+	return component(dt.dateTime, c), true
+}
+
+// TimezoneOffset returns the timezone offset of dt in hours. Returns false if
+// dt has no timezone offset.
+func (dt DateTime) TimezoneOffset() (Decimal, bool) {
+	if !hasTimezone(dt.l) {
+		return Decimal{}, false
+	}
+	_, offset := dt.dateTime.Zone()
+	hours := decimal.NewFromInt(int64(offset)).Div(decimal.NewFromInt(int64(time.Hour / time.Second)))
+	return Decimal(hours), true
+}
+
+// Date returns the date part of dt, with the precision of dt up to a day.
+// Returns false if the date part can't be represented.
+func (dt DateTime) Date() (Date, bool) {
+	var l layout
+	switch dt.l {
+	case dtYearLayout:
+		l = yearLayout
+	case dtMonthLayout:
+		l = monthLayout
+	default:
+		l = dayLayout
+	}
+	date, err := truncate(dt.dateTime, l)
+	if err != nil {
+		return Date{}, false
+	}
+	return Date{date, l}, true
+}
+
+// Time returns the time part of dt, with the precision of dt. Returns false
+// if dt has no time part, or if it can't be represented.
+func (dt DateTime) Time() (Time, bool) {
+	var l layout
+	switch dt.l {
+	case dtHourLayout, dtHourLayoutTZ:
+		l = hourLayout
+	case dtMinuteLayout, dtMinuteLayoutTZ:
+		l = minuteLayout
+	case dtSecondLayout, dtSecondLayoutTZ:
+		l = secondLayout
+	case dtMillisecondLayout, dtMillisecondLayoutTZ:
+		l = millisecondLayout
+	default:
+		return Time{}, false
+	}
+	result, err := truncate(dt.dateTime, l)
+	if err != nil {
+		return Time{}, false
+	}
+	return Time{result, l}, true
+}
+
+// Duration returns the number of whole calendar periods of the given unit
+// from dt until input, as a Quantity with a calendar duration unit. The result
+// is negative if input is before dt. Returns false if the unit is more precise
+// than either value, and an error if the unit is not a calendar duration.
+func (dt DateTime) Duration(input DateTime, unit string) (Quantity, bool, error) {
+	return dt.between(input, unit, calendarDuration)
+}
+
+// Difference returns the number of boundaries of the given unit that are
+// crossed from dt until input, as a Quantity with a calendar duration unit.
+// The result is negative if input is before dt. Returns false if the unit is
+// more precise than either value, and an error if the unit is not a calendar
+// duration.
+func (dt DateTime) Difference(input DateTime, unit string) (Quantity, bool, error) {
+	return dt.between(input, unit, calendarDifference)
+}
+
+func (dt DateTime) between(input DateTime, unit string, count func(from, to time.Time, unit string) int) (Quantity, bool, error) {
+	unit, c, err := calendarUnit(unit)
+	if err != nil {
+		return Quantity{}, false, err
+	}
+	if componentDigits[c] > min(dt.Precision(), input.Precision()) {
+		return Quantity{}, false, nil
+	}
+	return calendarQuantity(count(dt.dateTime.UTC(), input.dateTime.UTC(), unit), unit), true, nil
+}
+
 // Name returns the type name.
 func (dt DateTime) Name() string {
 	return dateTimeType
@@ -472,3 +472,269 @@ func TestDateTimeSub_ReturnsResults(t *testing.T) {
 		})
 	}
 }
+
+func TestDateTime_Precision(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dateTime system.DateTime
+		want     int
+	}{
+		{"Year", system.MustParseDateTime("2020T"), 4},
+		{"Day", system.MustParseDateTime("2020-03-15T"), 8},
+		{"Minute", system.MustParseDateTime("2020-03-15T10:30"), 12},
+		{"Second with timezone", system.MustParseDateTime("2020-03-15T10:30:00Z"), 14},
+		{"Millisecond", system.MustParseDateTime("2020-03-15T10:30:00.000"), 17},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.dateTime.Precision(); got != tc.want {
+				t.Errorf("DateTime.Precision() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDateTime_Boundaries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		dateTime  system.DateTime
+		precision int
+		wantLow   system.DateTime
+		wantHigh  system.DateTime
+	}{
+		{
+			name:      "partial month to millisecond",
+			dateTime:  system.MustParseDateTime("2020-03T"),
+			precision: 17,
+			wantLow:   system.MustParseDateTime("2020-03-01T00:00:00.000"),
+			wantHigh:  system.MustParseDateTime("2020-03-31T23:59:59.999"),
+		},
+		{
+			name:      "hour to minute",
+			dateTime:  system.MustParseDateTime("2014-01-01T08"),
+			precision: 12,
+			wantLow:   system.MustParseDateTime("2014-01-01T08:00"),
+			wantHigh:  system.MustParseDateTime("2014-01-01T08:59"),
+		},
+		{
+			name:      "retains timezone",
+			dateTime:  system.MustParseDateTime("2014-01-01T08:05+05:00"),
+			precision: 17,
+			wantLow:   system.MustParseDateTime("2014-01-01T08:05:00.000+05:00"),
+			wantHigh:  system.MustParseDateTime("2014-01-01T08:05:59.999+05:00"),
+		},
+		{
+			name:      "second to day",
+			dateTime:  system.MustParseDateTime("2014-01-01T08:05:10"),
+			precision: 8,
+			wantLow:   system.MustParseDateTime("2014-01-01T"),
+			wantHigh:  system.MustParseDateTime("2014-01-01T"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			low, ok := tc.dateTime.LowBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("DateTime.LowBoundary(%v) returned not ok", tc.precision)
+			}
+			if !cmp.Equal(low, tc.wantLow) {
+				t.Errorf("DateTime.LowBoundary(%v) = %v, want %v", tc.precision, low, tc.wantLow)
+			}
+			high, ok := tc.dateTime.HighBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("DateTime.HighBoundary(%v) returned not ok", tc.precision)
+			}
+			if !cmp.Equal(high, tc.wantHigh) {
+				t.Errorf("DateTime.HighBoundary(%v) = %v, want %v", tc.precision, high, tc.wantHigh)
+			}
+		})
+	}
+}
+
+func TestDateTime_Boundaries_InvalidPrecision(t *testing.T) {
+	dateTime := system.MustParseDateTime("2020-03T")
+
+	if _, ok := dateTime.LowBoundary(9); ok {
+		t.Errorf("DateTime.LowBoundary(9) returned ok, want not ok")
+	}
+	if _, ok := dateTime.HighBoundary(16); ok {
+		t.Errorf("DateTime.HighBoundary(16) returned ok, want not ok")
+	}
+}
+
+func TestDateTime_Component(t *testing.T) {
+	dateTime := system.MustParseDateTime("2012-01-02T13:30:45.123-07:00")
+	testCases := []struct {
+		name      string
+		dateTime  system.DateTime
+		component system.Component
+		want      int
+		wantOk    bool
+	}{
+		{"year", dateTime, system.YearComponent, 2012, true},
+		{"month", dateTime, system.MonthComponent, 1, true},
+		{"day", dateTime, system.DayComponent, 2, true},
+		{"hour", dateTime, system.HourComponent, 13, true},
+		{"minute", dateTime, system.MinuteComponent, 30, true},
+		{"second", dateTime, system.SecondComponent, 45, true},
+		{"millisecond", dateTime, system.MillisecondComponent, 123, true},
+		{"millisecond exceeds precision", system.MustParseDateTime("2012-01-02T13:30:45"), system.MillisecondComponent, 0, false},
+		{"hour exceeds precision", system.MustParseDateTime("2012-01-02T"), system.HourComponent, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := tc.dateTime.Component(tc.component)
+			if got != tc.want || ok != tc.wantOk {
+				t.Errorf("DateTime.Component(%v) = (%v, %v), want (%v, %v)", tc.component, got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestDateTime_TimezoneOffset(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dateTime system.DateTime
+		want     system.Decimal
+		wantOk   bool
+	}{
+		{"negative offset", system.MustParseDateTime("2012-01-01T12:30:00.000-07:00"), system.MustParseDecimal("-7"), true},
+		{"fractional offset", system.MustParseDateTime("2012-01-01T12:30+05:30"), system.MustParseDecimal("5.5"), true},
+		{"UTC", system.MustParseDateTime("2012-01-01T12:30:00Z"), system.MustParseDecimal("0"), true},
+		{"no offset", system.MustParseDateTime("2012-01-01T12:30:00"), system.Decimal{}, false},
+		{"date only", system.MustParseDateTime("2012-01-01T"), system.Decimal{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := tc.dateTime.TimezoneOffset()
+			if ok != tc.wantOk {
+				t.Fatalf("DateTime.TimezoneOffset() ok = %v, want %v", ok, tc.wantOk)
+			}
+			if ok && !got.Equal(tc.want) {
+				t.Errorf("DateTime.TimezoneOffset() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDateTime_DateAndTime(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dateTime system.DateTime
+		wantDate system.Date
+		wantTime system.Time
+		wantOk   bool
+	}{
+		{
+			name:     "millisecond precision",
+			dateTime: system.MustParseDateTime("2012-01-02T13:30:45.123-07:00"),
+			wantDate: system.MustParseDate("2012-01-02"),
+			wantTime: system.MustParseTime("13:30:45.123"),
+			wantOk:   true,
+		},
+		{
+			name:     "hour precision",
+			dateTime: system.MustParseDateTime("2012-01-02T13"),
+			wantDate: system.MustParseDate("2012-01-02"),
+			wantTime: system.MustParseTime("13"),
+			wantOk:   true,
+		},
+		{
+			name:     "month precision",
+			dateTime: system.MustParseDateTime("2012-01T"),
+			wantDate: system.MustParseDate("2012-01"),
+			wantOk:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, ok := tc.dateTime.Date(); !ok || !cmp.Equal(got, tc.wantDate) {
+				t.Errorf("DateTime.Date() = %v, %v, want %v", got, ok, tc.wantDate)
+			}
+			got, ok := tc.dateTime.Time()
+			if ok != tc.wantOk {
+				t.Fatalf("DateTime.Time() ok = %v, want %v", ok, tc.wantOk)
+			}
+			if ok && !cmp.Equal(got, tc.wantTime) {
+				t.Errorf("DateTime.Time() = %v, want %v", got, tc.wantTime)
+			}
+		})
+	}
+}
+
+func TestDateTime_DurationAndDifference(t *testing.T) {
+	testCases := []struct {
+		name           string
+		from           system.DateTime
+		to             system.DateTime
+		unit           string
+		wantDuration   system.Quantity
+		wantDifference system.Quantity
+	}{
+		{
+			name:           "hours across midnight",
+			from:           system.MustParseDateTime("2020-03-01T23:30"),
+			to:             system.MustParseDateTime("2020-03-02T00:15"),
+			unit:           "hours",
+			wantDuration:   system.MustParseQuantity("0", "hours"),
+			wantDifference: system.MustParseQuantity("1", "hours"),
+		},
+		{
+			name:           "days across midnight",
+			from:           system.MustParseDateTime("2020-03-01T23:30"),
+			to:             system.MustParseDateTime("2020-03-02T00:15"),
+			unit:           "days",
+			wantDuration:   system.MustParseQuantity("0", "days"),
+			wantDifference: system.MustParseQuantity("1", "days"),
+		},
+		{
+			name:           "normalizes timezones",
+			from:           system.MustParseDateTime("2020-03-01T10:00:00+02:00"),
+			to:             system.MustParseDateTime("2020-03-01T10:00:00Z"),
+			unit:           "minutes",
+			wantDuration:   system.MustParseQuantity("120", "minutes"),
+			wantDifference: system.MustParseQuantity("120", "minutes"),
+		},
+		{
+			name:           "milliseconds",
+			from:           system.MustParseDateTime("2020-03-01T10:00:00.250"),
+			to:             system.MustParseDateTime("2020-03-01T10:00:01.000"),
+			unit:           "milliseconds",
+			wantDuration:   system.MustParseQuantity("750", "milliseconds"),
+			wantDifference: system.MustParseQuantity("750", "milliseconds"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok, err := tc.from.Duration(tc.to, tc.unit)
+			if err != nil || !ok {
+				t.Fatalf("DateTime.Duration() returned (%v, %v), want ok", ok, err)
+			}
+			if !got.Equal(tc.wantDuration) {
+				t.Errorf("DateTime.Duration() = %v, want %v", got, tc.wantDuration)
+			}
+			got, ok, err = tc.from.Difference(tc.to, tc.unit)
+			if err != nil || !ok {
+				t.Fatalf("DateTime.Difference() returned (%v, %v), want ok", ok, err)
+			}
+			if !got.Equal(tc.wantDifference) {
+				t.Errorf("DateTime.Difference() = %v, want %v", got, tc.wantDifference)
+			}
+		})
+	}
+}
+
+func TestDateTime_Duration_ExceedsPrecision(t *testing.T) {
+	from := system.MustParseDateTime("2020-03-01T10:00")
+	to := system.MustParseDateTime("2020-03-01T10:00:30")
+
+	if _, ok, err := from.Duration(to, "seconds"); ok || err != nil {
+		t.Errorf("DateTime.Duration() returned (%v, %v), want (false, nil)", ok, err)
+	}
+}
@@ -1,5 +1,10 @@
 package system
 
+import (
+	"strings"
+	"time"
+)
+
 // datePrecision enumerates date precision constants.
 type datePrecision int
 
@@ -84,3 +89,135 @@ var dateTimeMap = map[layout]dateTimePrecision{
 	dtMonthLayout:         dtMonth,
 	dtYearLayout:          dtYear,
 }
+
+// precisionDigits maps each layout to the number of digits of precision
+// that it represents, as defined by the FHIRPath precision() function.
+var precisionDigits = map[layout]int{
+	yearLayout:            4,
+	monthLayout:           6,
+	dayLayout:             8,
+	hourLayout:            2,
+	minuteLayout:          4,
+	secondLayout:          6,
+	millisecondLayout:     9,
+	dtYearLayout:          4,
+	dtMonthLayout:         6,
+	dtDayLayout:           8,
+	dtHourLayout:          10,
+	dtHourLayoutTZ:        10,
+	dtMinuteLayout:        12,
+	dtMinuteLayoutTZ:      12,
+	dtSecondLayout:        14,
+	dtSecondLayoutTZ:      14,
+	dtMillisecondLayout:   17,
+	dtMillisecondLayoutTZ: 17,
+}
+
+var dateLayoutsByPrecision = map[int]layout{
+	4: yearLayout,
+	6: monthLayout,
+	8: dayLayout,
+}
+
+var timeLayoutsByPrecision = map[int]layout{
+	2: hourLayout,
+	4: minuteLayout,
+	6: secondLayout,
+	9: millisecondLayout,
+}
+
+var dateTimeLayoutsByPrecision = map[int]layout{
+	4:  dtYearLayout,
+	6:  dtMonthLayout,
+	8:  dtDayLayout,
+	10: dtHourLayout,
+	12: dtMinuteLayout,
+	14: dtSecondLayout,
+	17: dtMillisecondLayout,
+}
+
+var dateTimeLayoutsByPrecisionTZ = map[int]layout{
+	10: dtHourLayoutTZ,
+	12: dtMinuteLayoutTZ,
+	14: dtSecondLayoutTZ,
+	17: dtMillisecondLayoutTZ,
+}
+
+// hasTimezone returns true if values parsed with l carry a timezone offset.
+func hasTimezone(l layout) bool {
+	return strings.HasSuffix(string(l), "Z07:00")
+}
+
+// nextPeriod returns the start of the period that follows the one t falls
+// in, at the precision represented by l.
+func nextPeriod(t time.Time, l layout) time.Time {
+	switch l {
+	case yearLayout, dtYearLayout:
+		return t.AddDate(1, 0, 0)
+	case monthLayout, dtMonthLayout:
+		return t.AddDate(0, 1, 0)
+	case dayLayout, dtDayLayout:
+		return t.AddDate(0, 0, 1)
+	case hourLayout, dtHourLayout, dtHourLayoutTZ:
+		return t.Add(time.Hour)
+	case minuteLayout, dtMinuteLayout, dtMinuteLayoutTZ:
+		return t.Add(time.Minute)
+	case secondLayout, dtSecondLayout, dtSecondLayoutTZ:
+		return t.Add(time.Second)
+	default:
+		return t.Add(time.Millisecond)
+	}
+}
+
+// truncate reformats t with l, discarding any components that are more
+// precise than l. Returns an error if the reformatted time can't be parsed,
+// such as when its year is out of range.
+func truncate(t time.Time, l layout) (time.Time, error) {
+	return time.Parse(string(l), t.Format(string(l)))
+}
+
+// Component identifies a single component of a Date, DateTime or Time value.
+type Component int
+
+// Component constants.
+const (
+	YearComponent Component = iota
+	MonthComponent
+	DayComponent
+	HourComponent
+	MinuteComponent
+	SecondComponent
+	MillisecondComponent
+)
+
+// componentDigits holds the number of digits of DateTime precision required
+// for each component to be present. A Time requires 8 fewer digits.
+var componentDigits = map[Component]int{
+	YearComponent:        4,
+	MonthComponent:       6,
+	DayComponent:         8,
+	HourComponent:        10,
+	MinuteComponent:      12,
+	SecondComponent:      14,
+	MillisecondComponent: 17,
+}
+
+// component returns the value of the component c of t.
+func component(t time.Time, c Component) int {
+	switch c {
+	case YearComponent:
+		return t.Year()
+	case MonthComponent:
+		return int(t.Month())
+	case DayComponent:
+		return t.Day()
+	case HourComponent:
+		return t.Hour()
+	case MinuteComponent:
+		return t.Minute()
+	case SecondComponent:
+		return t.Second()
+	default:
+		return t.Nanosecond() / int(time.Millisecond)
+	}
+}
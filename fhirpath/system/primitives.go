@@ -37,6 +37,12 @@ func (b Boolean) Equal(input Any) bool {
 	return b == val
 }
 
+// Equivalent returns true if the input value is a System Boolean,
+// and contains the same value.
+func (b Boolean) Equivalent(input Any) bool {
+	return b.Equal(input)
+}
+
 // Less returns error for Boolean comparison.
 func (b Boolean) Less(input Any) (Boolean, error) {
 	return false, fmt.Errorf("%w: %T, %T", ErrTypeMismatch, b, input)
@@ -82,6 +88,22 @@ func (s String) Equal(input Any) bool {
 	return s == val
 }
 
+// Equivalent returns true if the input value is a System String that
+// matches s, ignoring case and normalizing whitespace.
+func (s String) Equivalent(input Any) bool {
+	val, ok := input.(String)
+	if !ok {
+		return false
+	}
+	return normalizeString(string(s)) == normalizeString(string(val))
+}
+
+// normalizeString lower-cases the input, trims leading and trailing whitespace,
+// and collapses all other whitespace runs into a single space.
+func normalizeString(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
 // Name returns the type name.
 func (s String) Name() string {
 	return stringType
@@ -126,6 +148,12 @@ func (i Integer) Equal(input Any) bool {
 	return i == val
 }
 
+// Equivalent returns true if the input value is a System Integer, and
+// contains the same int32 value.
+func (i Integer) Equivalent(input Any) bool {
+	return i.Equal(input)
+}
+
 // Name returns the type name.
 func (i Integer) Name() string {
 	return integerType
@@ -238,6 +266,62 @@ func (d Decimal) Equal(input Any) bool {
 	return decimal.Decimal(d).Equal(decimal.Decimal(val))
 }
 
+// Equivalent returns true if the input value is a System Decimal that is
+// equal to d when both values are rounded to the precision of the least
+// precise operand, as given by Precision.
+func (d Decimal) Equivalent(input Any) bool {
+	val, ok := input.(Decimal)
+	if !ok {
+		return false
+	}
+	precision := min(d.Precision(), val.Precision())
+	return d.Round(int32(precision)).Equal(val.Round(int32(precision)))
+}
+
+// Precision returns the number of digits after the decimal point of d,
+// including trailing zeroes.
+func (d Decimal) Precision() int {
+	if exp := decimal.Decimal(d).Exponent(); exp < 0 {
+		return int(-exp)
+	}
+	return 0
+}
+
+// LowBoundary returns the lowest value that d may represent, given the
+// implicit uncertainty of its precision, at the given precision.
+// Eg. the low boundary of 1.587 at precision 8 is 1.58650000. Returns false if
+// the precision is negative or greater than the maximum Decimal precision.
+func (d Decimal) LowBoundary(precision int) (Decimal, bool) {
+	if precision < 0 || precision > maxDecimalPrecision {
+		return Decimal{}, false
+	}
+	low := decimal.Decimal(d).Sub(d.uncertainty()).RoundFloor(int32(precision))
+	return withPrecision(low, precision), true
+}
+
+// HighBoundary returns the highest value that d may represent, given the
+// implicit uncertainty of its precision, at the given precision.
+// Eg. the high boundary of 1.587 at precision 8 is 1.58750000. Returns false
+// if the precision is negative or greater than the maximum Decimal precision.
+func (d Decimal) HighBoundary(precision int) (Decimal, bool) {
+	if precision < 0 || precision > maxDecimalPrecision {
+		return Decimal{}, false
+	}
+	high := decimal.Decimal(d).Add(d.uncertainty()).RoundCeil(int32(precision))
+	return withPrecision(high, precision), true
+}
+
+// uncertainty returns half of the least significant digit of d.
+func (d Decimal) uncertainty() decimal.Decimal {
+	return decimal.New(5, -int32(d.Precision())-1)
+}
+
+// withPrecision returns d with exactly the given number of digits after the
+// decimal point, padding with trailing zeroes where required.
+func withPrecision(d decimal.Decimal, precision int) Decimal {
+	return Decimal(decimal.RequireFromString(d.StringFixed(int32(precision))))
+}
+
 // Name returns the type name.
 func (d Decimal) Name() string {
 	return decimalType
@@ -400,3 +400,200 @@ func TestIntegerMul_ReturnsResult(t *testing.T) {
 		})
 	}
 }
+
+func TestEquivalent_ReturnsResult(t *testing.T) {
+	testCases := []struct {
+		name  string
+		left  system.Any
+		right system.Any
+		want  bool
+	}{
+		{
+			name:  "strings with different case",
+			left:  system.String("Hello World"),
+			right: system.String("hello world"),
+			want:  true,
+		},
+		{
+			name:  "strings with different whitespace",
+			left:  system.String("  hello\t\nworld "),
+			right: system.String("hello world"),
+			want:  true,
+		},
+		{
+			name:  "different strings",
+			left:  system.String("hello"),
+			right: system.String("world"),
+			want:  false,
+		},
+		{
+			name:  "decimals compared at lesser precision",
+			left:  system.MustParseDecimal("1.2"),
+			right: system.MustParseDecimal("1.23"),
+			want:  true,
+		},
+		{
+			name:  "decimals keep trailing zeroes when determining precision",
+			left:  system.MustParseDecimal("1.20"),
+			right: system.MustParseDecimal("1.23"),
+			want:  false,
+		},
+		{
+			name:  "decimals with trailing zeroes compared at lesser precision",
+			left:  system.MustParseDecimal("1.20"),
+			right: system.MustParseDecimal("1.2"),
+			want:  true,
+		},
+		{
+			name:  "decimals not equivalent at lesser precision",
+			left:  system.MustParseDecimal("1.3"),
+			right: system.MustParseDecimal("1.23"),
+			want:  false,
+		},
+		{
+			name:  "integer and decimal",
+			left:  system.Integer(1),
+			right: system.MustParseDecimal("1.4"),
+			want:  true,
+		},
+		{
+			name:  "dates of mismatched precision",
+			left:  system.MustParseDate("2020-01"),
+			right: system.MustParseDate("2020-01-01"),
+			want:  false,
+		},
+		{
+			name:  "equal dates",
+			left:  system.MustParseDate("2020-01"),
+			right: system.MustParseDate("2020-01"),
+			want:  true,
+		},
+		{
+			name:  "datetimes of mismatched precision",
+			left:  system.MustParseDateTime("2020-01-01T10:30"),
+			right: system.MustParseDateTime("2020-01-01T10:30:00"),
+			want:  false,
+		},
+		{
+			name:  "times of mismatched precision",
+			left:  system.MustParseTime("10:30"),
+			right: system.MustParseTime("10:30:00"),
+			want:  false,
+		},
+		{
+			name:  "quantities with equivalent values",
+			left:  system.MustParseQuantity("4.0", "kg"),
+			right: system.MustParseQuantity("4.01", "kg"),
+			want:  true,
+		},
+		{
+			name:  "quantities with mismatched units",
+			left:  system.MustParseQuantity("4", "kg"),
+			right: system.MustParseQuantity("4", "g"),
+			want:  false,
+		},
+		{
+			name:  "mismatched types",
+			left:  system.String("1"),
+			right: system.Integer(1),
+			want:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := system.Equivalent(tc.left, tc.right); got != tc.want {
+				t.Errorf("Equivalent(%v, %v) = %v, want %v", tc.left, tc.right, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_Precision(t *testing.T) {
+	testCases := []struct {
+		name    string
+		decimal system.Decimal
+		want    int
+	}{
+		{"integral", system.MustParseDecimal("12"), 0},
+		{"fractional", system.MustParseDecimal("1.587"), 3},
+		{"trailing zeroes", system.MustParseDecimal("1.58700"), 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.decimal.Precision(); got != tc.want {
+				t.Errorf("Decimal.Precision() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_Boundaries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		decimal   system.Decimal
+		precision int
+		wantLow   system.Decimal
+		wantHigh  system.Decimal
+	}{
+		{
+			name:      "default precision",
+			decimal:   system.MustParseDecimal("1.587"),
+			precision: 8,
+			wantLow:   system.MustParseDecimal("1.58650000"),
+			wantHigh:  system.MustParseDecimal("1.58750000"),
+		},
+		{
+			name:      "negative value",
+			decimal:   system.MustParseDecimal("-1.587"),
+			precision: 6,
+			wantLow:   system.MustParseDecimal("-1.587500"),
+			wantHigh:  system.MustParseDecimal("-1.586500"),
+		},
+		{
+			name:      "integral value",
+			decimal:   system.MustParseDecimal("1"),
+			precision: 1,
+			wantLow:   system.MustParseDecimal("0.5"),
+			wantHigh:  system.MustParseDecimal("1.5"),
+		},
+		{
+			name:      "lower precision than value",
+			decimal:   system.MustParseDecimal("1.587"),
+			precision: 2,
+			wantLow:   system.MustParseDecimal("1.58"),
+			wantHigh:  system.MustParseDecimal("1.59"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			low, ok := tc.decimal.LowBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Decimal.LowBoundary(%v) returned not ok", tc.precision)
+			}
+			if !low.Equal(tc.wantLow) || low.Precision() != tc.precision {
+				t.Errorf("Decimal.LowBoundary(%v) = %v, want %v", tc.precision, low, tc.wantLow)
+			}
+			high, ok := tc.decimal.HighBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Decimal.HighBoundary(%v) returned not ok", tc.precision)
+			}
+			if !high.Equal(tc.wantHigh) || high.Precision() != tc.precision {
+				t.Errorf("Decimal.HighBoundary(%v) = %v, want %v", tc.precision, high, tc.wantHigh)
+			}
+		})
+	}
+}
+
+func TestDecimal_Boundaries_InvalidPrecision(t *testing.T) {
+	decimal := system.MustParseDecimal("1.587")
+
+	if _, ok := decimal.LowBoundary(-1); ok {
+		t.Errorf("Decimal.LowBoundary(-1) returned ok, want not ok")
+	}
+	if _, ok := decimal.HighBoundary(29); ok {
+		t.Errorf("Decimal.HighBoundary(29) returned ok, want not ok")
+	}
+}
@@ -2,11 +2,14 @@ package system
 
 import (
 	"fmt"
+	"math/big"
+	"strings"
 	"time"
 
 	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
 	"github.com/shopspring/decimal"
 	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/units"
 )
 
 // Quantity type represents a decimal value along with a UCUM unit or
@@ -41,9 +44,10 @@ func MustParseQuantity(number string, unit string) Quantity {
 }
 
 // newQuantity constructs a system Quantity type, given a decimal
-// value and a UCUM unit identifier.
+// value and a UCUM unit identifier. Units that are neither UCUM units nor
+// calendar duration keywords are accepted, but can only be compared with the
+// same unit.
 func newQuantity(value Decimal, unit string) (Quantity, error) {
-	// check isValidUnit(unit) *To be implemented
 	return Quantity{value, unit}, nil
 }
 
@@ -56,42 +60,89 @@ func (q Quantity) TryEqual(input Any) (bool, bool) {
 	if !ok {
 		return false, true
 	}
-	if q.unit != val.unit {
+	lhs, rhs, ok := q.convert(val, false)
+	if !ok {
 		return false, false
 	}
-	return q.value.Equal(val.value), true
+	return lhs.value.Equal(rhs.value), true
+}
+
+// Equivalent returns true if input is a Quantity with a commensurable unit,
+// and an equivalent value once converted. Unlike equality, the calendar
+// durations of years and months are equivalent to the UCUM units 'a' and 'mo'.
+func (q Quantity) Equivalent(input Any) bool {
+	val, ok := input.(Quantity)
+	if !ok {
+		return false
+	}
+	lhs, rhs, ok := q.convert(val, true)
+	return ok && lhs.value.Equivalent(rhs.value)
 }
 
 // Less returns true if q is less than input.(Quantity). If the units
-// are mismatched, returns an error. If input is not a Quantity, returns
+// are not commensurable, returns an error. If input is not a Quantity, returns
 // an error.
 func (q Quantity) Less(input Any) (Boolean, error) {
 	val, ok := input.(Quantity)
 	if !ok {
 		return false, fmt.Errorf("%w: %T, %T", ErrTypeMismatch, q, input)
 	}
-	if q.unit != val.unit {
+	lhs, rhs, ok := q.convert(val, false)
+	if !ok {
 		return false, ErrMismatchedUnit
 	}
-	return q.value.Less(val.value)
+	return lhs.value.Less(rhs.value)
 }
 
-// Add returns q + input. Returns an error if the units are mismatched.
+// Add returns q + input, in the more granular of their units. Returns an
+// error if the units are not commensurable.
 func (q Quantity) Add(input Quantity) (Quantity, error) {
-	if q.unit != input.unit {
+	lhs, rhs, ok := q.convert(input, false)
+	if !ok {
 		return Quantity{}, ErrMismatchedUnit
 	}
-	value := Decimal(decimal.Decimal(q.value).Add(decimal.Decimal(input.value)))
-	return Quantity{value, q.unit}, nil
+	value := Decimal(decimal.Decimal(lhs.value).Add(decimal.Decimal(rhs.value)))
+	return Quantity{value, lhs.unit}, nil
 }
 
-// Sub returns q - input. Returns an error if the units are mismatched.
+// Sub returns q - input, in the more granular of their units. Returns an
+// error if the units are not commensurable.
 func (q Quantity) Sub(input Quantity) (Quantity, error) {
-	if q.unit != input.unit {
+	lhs, rhs, ok := q.convert(input, false)
+	if !ok {
 		return Quantity{}, ErrMismatchedUnit
 	}
-	value := Decimal(decimal.Decimal(q.value).Sub(decimal.Decimal(input.value)))
-	return Quantity{value, q.unit}, nil
+	value := Decimal(decimal.Decimal(lhs.value).Sub(decimal.Decimal(rhs.value)))
+	return Quantity{value, lhs.unit}, nil
+}
+
+// LowBoundary returns the quantity with the low boundary of its value at the
+// given precision, and the same unit. Returns false if the precision is not
+// valid for a Decimal.
+func (q Quantity) LowBoundary(precision int) (Quantity, bool) {
+	value, ok := q.value.LowBoundary(precision)
+	return Quantity{value, q.unit}, ok
+}
+
+// HighBoundary returns the quantity with the high boundary of its value at
+// the given precision, and the same unit. Returns false if the precision is
+// not valid for a Decimal.
+func (q Quantity) HighBoundary(precision int) (Quantity, bool) {
+	value, ok := q.value.HighBoundary(precision)
+	return Quantity{value, q.unit}, ok
+}
+
+// Comparable returns true if q and input have commensurable units, such that
+// they can be compared. Calendar duration keywords are compatible with their
+// plural forms.
+func (q Quantity) Comparable(input Quantity) bool {
+	_, _, ok := q.convert(input, false)
+	return ok
+}
+
+// Div returns q with its value divided by input. The unit is unchanged.
+func (q Quantity) Div(input Decimal) Quantity {
+	return Quantity{q.value.Div(input), q.unit}
 }
 
 // Name returns the type name.
@@ -209,3 +260,94 @@ func (q Quantity) toMonths() (int, error) {
 		return 0, fmt.Errorf("%w: not a time-valued unit", ErrMismatchedUnit)
 	}
 }
+
+// definiteDurations maps the calendar duration keywords of fixed length to
+// their UCUM units.
+var definiteDurations = map[string]string{
+	"year":        "a",
+	"month":       "mo",
+	"week":        "wk",
+	"day":         "d",
+	"hour":        "h",
+	"minute":      "min",
+	"second":      "s",
+	"millisecond": "ms",
+}
+
+// calendarMonths maps the calendar durations of years and months to their
+// number of months. Since their length varies, they are only comparable with
+// each other, and only equivalent to UCUM units.
+var calendarMonths = map[string]int64{
+	"year":  12,
+	"month": 1,
+}
+
+// convert returns q and input in the more granular of their units, which are
+// converted according to UCUM. Calendar duration keywords are converted to
+// their UCUM units, except for years and months, unless equivalent is true.
+// Returns false if the units are not commensurable.
+func (q Quantity) convert(input Quantity, equivalent bool) (Quantity, Quantity, bool) {
+	if q.unit == input.unit {
+		return q, input, true
+	}
+	ratio, ok := unitRatio(normalizeUnit(q.unit), normalizeUnit(input.unit), equivalent)
+	if !ok {
+		return Quantity{}, Quantity{}, false
+	}
+	if ratio.Cmp(big.NewRat(1, 1)) >= 0 {
+		return Quantity{convertValue(q.value, ratio), input.unit}, input, true
+	}
+	return q, Quantity{convertValue(input.value, new(big.Rat).Inv(ratio)), q.unit}, true
+}
+
+// convertValue returns the value multiplied by the conversion ratio, rounded
+// to the maximum Decimal precision, without trailing zeroes.
+func convertValue(value Decimal, ratio *big.Rat) Decimal {
+	product := new(big.Rat).Mul(decimal.Decimal(value).Rat(), ratio)
+	converted := decimal.NewFromBigRat(product, maxDecimalPrecision)
+	return Decimal(decimal.RequireFromString(converted.String()))
+}
+
+// unitRatio returns the factor by which values in the from unit are
+// multiplied to convert them to the to unit, or false if the units are not
+// commensurable.
+func unitRatio(from, to string, equivalent bool) (*big.Rat, bool) {
+	fromMonths, fromCalendar := calendarMonths[from]
+	toMonths, toCalendar := calendarMonths[to]
+	if !equivalent && (fromCalendar || toCalendar) {
+		if !fromCalendar || !toCalendar {
+			return nil, false
+		}
+		return big.NewRat(fromMonths, toMonths), true
+	}
+	fromUnit, err := parseUnit(from)
+	if err != nil {
+		return nil, false
+	}
+	toUnit, err := parseUnit(to)
+	if err != nil {
+		return nil, false
+	}
+	return fromUnit.Ratio(toUnit)
+}
+
+// parseUnit returns the UCUM unit of a quantity, for which calendar duration
+// keywords stand for their UCUM units, and no unit stands for the unit 1.
+func parseUnit(unit string) (units.UCUM, error) {
+	if code, ok := definiteDurations[unit]; ok {
+		unit = code
+	} else if unit == "" {
+		unit = "1"
+	}
+	return units.ParseUCUM(unit)
+}
+
+// normalizeUnit returns the singular form of plural calendar duration
+// keywords, and any other unit unchanged.
+func normalizeUnit(unit string) string {
+	switch unit {
+	case "years", "months", "weeks", "days", "hours", "minutes", "seconds", "milliseconds":
+		return strings.TrimSuffix(unit, "s")
+	}
+	return unit
+}
@@ -1,6 +1,7 @@
 package system_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/verily-src/fhirpath-go/fhirpath/system"
@@ -93,6 +94,26 @@ func TestQuantity_Equal(t *testing.T) {
 			shouldEqual: false,
 			wantOk:      true,
 		},
+		{
+			name:        "commensurable units",
+			quantityOne: oneKg,
+			quantityTwo: system.MustParseQuantity("1000", "g"),
+			shouldEqual: true,
+			wantOk:      true,
+		},
+		{
+			name:        "calendar duration and UCUM unit",
+			quantityOne: system.MustParseQuantity("2", "weeks"),
+			quantityTwo: system.MustParseQuantity("14", "d"),
+			shouldEqual: true,
+			wantOk:      true,
+		},
+		{
+			name:        "calendar year and UCUM year",
+			quantityOne: system.MustParseQuantity("1", "year"),
+			quantityTwo: system.MustParseQuantity("1", "a"),
+			wantOk:      false,
+		},
 		{
 			name:        "different type",
 			quantityOne: oneLb,
@@ -115,3 +136,267 @@ func TestQuantity_Equal(t *testing.T) {
 		})
 	}
 }
+
+func TestQuantity_Boundaries(t *testing.T) {
+	quantity := system.MustParseQuantity("1.5", "mg")
+
+	low, ok := quantity.LowBoundary(2)
+	if want := system.MustParseQuantity("1.45", "mg"); !ok || !low.Equal(want) {
+		t.Errorf("Quantity.LowBoundary(2) = %v, want %v", low, want)
+	}
+	high, ok := quantity.HighBoundary(2)
+	if want := system.MustParseQuantity("1.55", "mg"); !ok || !high.Equal(want) {
+		t.Errorf("Quantity.HighBoundary(2) = %v, want %v", high, want)
+	}
+}
+
+func TestQuantity_Comparable(t *testing.T) {
+	testCases := []struct {
+		name        string
+		quantityOne system.Quantity
+		quantityTwo system.Quantity
+		want        bool
+	}{
+		{
+			name:        "same unit",
+			quantityOne: system.MustParseQuantity("1", "kg"),
+			quantityTwo: system.MustParseQuantity("2", "kg"),
+			want:        true,
+		},
+		{
+			name:        "calendar duration plural",
+			quantityOne: system.MustParseQuantity("1", "year"),
+			quantityTwo: system.MustParseQuantity("2", "years"),
+			want:        true,
+		},
+		{
+			name:        "commensurable unit",
+			quantityOne: system.MustParseQuantity("1", "kg"),
+			quantityTwo: system.MustParseQuantity("1", "[lb_av]"),
+			want:        true,
+		},
+		{
+			name:        "different unit",
+			quantityOne: system.MustParseQuantity("1", "kg"),
+			quantityTwo: system.MustParseQuantity("1", "cm"),
+			want:        false,
+		},
+		{
+			name:        "calendar month and UCUM month",
+			quantityOne: system.MustParseQuantity("1", "month"),
+			quantityTwo: system.MustParseQuantity("1", "mo"),
+			want:        false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.quantityOne.Comparable(tc.quantityTwo); got != tc.want {
+				t.Errorf("Quantity.Comparable() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQuantity_Equivalent(t *testing.T) {
+	testCases := []struct {
+		name        string
+		quantityOne system.Quantity
+		quantityTwo system.Any
+		want        bool
+	}{
+		{
+			name:        "commensurable units",
+			quantityOne: system.MustParseQuantity("1.0", "m"),
+			quantityTwo: system.MustParseQuantity("100", "cm"),
+			want:        true,
+		},
+		{
+			name:        "calendar year and UCUM year",
+			quantityOne: system.MustParseQuantity("2", "years"),
+			quantityTwo: system.MustParseQuantity("2", "a"),
+			want:        true,
+		},
+		{
+			name:        "calendar month and UCUM year",
+			quantityOne: system.MustParseQuantity("12", "months"),
+			quantityTwo: system.MustParseQuantity("1", "a"),
+			want:        true,
+		},
+		{
+			name:        "different value",
+			quantityOne: system.MustParseQuantity("1", "g"),
+			quantityTwo: system.MustParseQuantity("1", "mg"),
+			want:        false,
+		},
+		{
+			name:        "incommensurable units",
+			quantityOne: system.MustParseQuantity("1", "g"),
+			quantityTwo: system.MustParseQuantity("1", "m"),
+			want:        false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.quantityOne.Equivalent(tc.quantityTwo); got != tc.want {
+				t.Errorf("Quantity.Equivalent() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQuantity_Less(t *testing.T) {
+	testCases := []struct {
+		name        string
+		quantityOne system.Quantity
+		quantityTwo system.Quantity
+		want        system.Boolean
+	}{
+		{
+			name:        "same unit",
+			quantityOne: system.MustParseQuantity("1", "mg"),
+			quantityTwo: system.MustParseQuantity("2", "mg"),
+			want:        true,
+		},
+		{
+			name:        "coarser unit",
+			quantityOne: system.MustParseQuantity("0.25", "g"),
+			quantityTwo: system.MustParseQuantity("100", "mg"),
+			want:        false,
+		},
+		{
+			name:        "finer unit",
+			quantityOne: system.MustParseQuantity("100", "mg"),
+			quantityTwo: system.MustParseQuantity("0.25", "g"),
+			want:        true,
+		},
+		{
+			name:        "calendar durations",
+			quantityOne: system.MustParseQuantity("11", "months"),
+			quantityTwo: system.MustParseQuantity("1", "year"),
+			want:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.quantityOne.Less(tc.quantityTwo)
+			if err != nil {
+				t.Fatalf("Quantity.Less() returned unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Quantity.Less() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQuantity_Less_IncommensurableUnits_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		quantityOne system.Quantity
+		quantityTwo system.Quantity
+	}{
+		{
+			name:        "different dimensions",
+			quantityOne: system.MustParseQuantity("1", "mg"),
+			quantityTwo: system.MustParseQuantity("1", "cm"),
+		},
+		{
+			name:        "unknown unit",
+			quantityOne: system.MustParseQuantity("1", "lbs"),
+			quantityTwo: system.MustParseQuantity("1", "kg"),
+		},
+		{
+			name:        "calendar year and UCUM day",
+			quantityOne: system.MustParseQuantity("1", "year"),
+			quantityTwo: system.MustParseQuantity("400", "d"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.quantityOne.Less(tc.quantityTwo); !errors.Is(err, system.ErrMismatchedUnit) {
+				t.Errorf("Quantity.Less() returned error %v, want %v", err, system.ErrMismatchedUnit)
+			}
+		})
+	}
+}
+
+func TestQuantity_AddSub(t *testing.T) {
+	testCases := []struct {
+		name        string
+		quantityOne system.Quantity
+		quantityTwo system.Quantity
+		wantSum     system.Quantity
+		wantDiff    system.Quantity
+	}{
+		{
+			name:        "same unit",
+			quantityOne: system.MustParseQuantity("3", "mg"),
+			quantityTwo: system.MustParseQuantity("1.5", "mg"),
+			wantSum:     system.MustParseQuantity("4.5", "mg"),
+			wantDiff:    system.MustParseQuantity("1.5", "mg"),
+		},
+		{
+			name:        "converts to the finer unit",
+			quantityOne: system.MustParseQuantity("1.5", "g"),
+			quantityTwo: system.MustParseQuantity("250", "mg"),
+			wantSum:     system.MustParseQuantity("1750", "mg"),
+			wantDiff:    system.MustParseQuantity("1250", "mg"),
+		},
+		{
+			name:        "calendar duration and UCUM unit",
+			quantityOne: system.MustParseQuantity("1", "day"),
+			quantityTwo: system.MustParseQuantity("2", "h"),
+			wantSum:     system.MustParseQuantity("26", "h"),
+			wantDiff:    system.MustParseQuantity("22", "h"),
+		},
+		{
+			name:        "calendar years and months",
+			quantityOne: system.MustParseQuantity("1", "year"),
+			quantityTwo: system.MustParseQuantity("3", "months"),
+			wantSum:     system.MustParseQuantity("15", "months"),
+			wantDiff:    system.MustParseQuantity("9", "months"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sum, err := tc.quantityOne.Add(tc.quantityTwo)
+			if err != nil {
+				t.Fatalf("Quantity.Add() returned unexpected error: %v", err)
+			}
+			if !sum.Equal(tc.wantSum) {
+				t.Errorf("Quantity.Add() = %v, want %v", sum, tc.wantSum)
+			}
+			diff, err := tc.quantityOne.Sub(tc.quantityTwo)
+			if err != nil {
+				t.Fatalf("Quantity.Sub() returned unexpected error: %v", err)
+			}
+			if !diff.Equal(tc.wantDiff) {
+				t.Errorf("Quantity.Sub() = %v, want %v", diff, tc.wantDiff)
+			}
+		})
+	}
+}
+
+func TestQuantity_Add_IncommensurableUnits_ReturnsError(t *testing.T) {
+	one, two := system.MustParseQuantity("1", "mg"), system.MustParseQuantity("2", "cm")
+
+	if _, err := one.Add(two); !errors.Is(err, system.ErrMismatchedUnit) {
+		t.Errorf("Quantity.Add() returned error %v, want %v", err, system.ErrMismatchedUnit)
+	}
+	if _, err := one.Sub(two); !errors.Is(err, system.ErrMismatchedUnit) {
+		t.Errorf("Quantity.Sub() returned error %v, want %v", err, system.ErrMismatchedUnit)
+	}
+}
+
+func TestQuantity_Div(t *testing.T) {
+	got := system.MustParseQuantity("3", "kg").Div(system.MustParseDecimal("2"))
+
+	if want := system.MustParseQuantity("1.5", "kg"); !got.Equal(want) {
+		t.Errorf("Quantity.Div() = %v, want %v", got, want)
+	}
+}
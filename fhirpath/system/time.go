@@ -105,6 +105,88 @@ func (t Time) TryEqual(input Any) (bool, bool) {
 	return false, false
 }
 
+// Equivalent returns true if input is a Time with the same precision and
+// value as t. Unlike equality, values of mismatched precision are never
+// equivalent.
+func (t Time) Equivalent(input Any) bool {
+	result, ok := t.TryEqual(input)
+	return result && ok
+}
+
+// Precision returns the number of digits of precision represented by t: 2 for
+// an hour, 4 for a minute, 6 for a second and 9 for a millisecond.
+func (t Time) Precision() int {
+	return precisionDigits[t.l]
+}
+
+// LowBoundary returns the earliest Time that t may represent, at the given
+// precision. Returns false if the precision is not 2, 4, 6 or 9.
+func (t Time) LowBoundary(precision int) (Time, bool) {
+	l, ok := timeLayoutsByPrecision[precision]
+	if !ok {
+		return Time{}, false
+	}
+	result, err := truncate(t.time, l)
+	if err != nil {
+		return Time{}, false
+	}
+	return Time{result, l}, true
+}
+
+// HighBoundary returns the latest Time that t may represent, at the given
+// precision. Eg. the high boundary of 10:30 is 10:30:59.999. Returns false
+// if the precision is not 2, 4, 6 or 9.
+func (t Time) HighBoundary(precision int) (Time, bool) {
+	l, ok := timeLayoutsByPrecision[precision]
+	if !ok {
+		return Time{}, false
+	}
+	result, err := truncate(nextPeriod(t.time, t.l).Add(-time.Millisecond), l)
+	if err != nil {
+		return Time{}, false
+	}
+	return Time{result, l}, true
+}
+
+// Component returns the value of the given component of t. Returns false if
+// the component is not a time component, or exceeds the precision of t.
+func (t Time) Component(c Component) (int, bool) {
+	if c < HourComponent || componentDigits[c]-componentDigits[DayComponent] > t.Precision() {
+		return 0, false
+	}
+	return component(t.time, c), true
+}
+
+// Duration returns the number of whole periods of the given unit from t until
+// input, as a Quantity with a calendar duration unit. Returns false if the
+// unit is more precise than either value, and an error if the unit is not a
+// calendar duration of hours or less.
+func (t Time) Duration(input Time, unit string) (Quantity, bool, error) {
+	return t.between(input, unit, calendarDuration)
+}
+
+// Difference returns the number of boundaries of the given unit that are
+// crossed from t until input, as a Quantity with a calendar duration unit.
+// Returns false if the unit is more precise than either value, and an error
+// if the unit is not a calendar duration of hours or less.
+func (t Time) Difference(input Time, unit string) (Quantity, bool, error) {
+	return t.between(input, unit, calendarDifference)
+}
+
+func (t Time) between(input Time, unit string, count func(from, to time.Time, unit string) int) (Quantity, bool, error) {
+	unit, c, err := calendarUnit(unit)
+	if err != nil {
+		return Quantity{}, false, err
+	}
+	if c < HourComponent {
+		return Quantity{}, false, fmt.Errorf("%w: '%s' is not a time-valued unit", ErrMismatchedUnit, unit)
+	}
+	if componentDigits[c]-componentDigits[DayComponent] > min(t.Precision(), input.Precision()) {
+		return Quantity{}, false, nil
+	}
+	return calendarQuantity(count(t.time, input.time, unit), unit), true, nil
+}
+
 // Name returns the type name.
 func (t Time) Name() string {
 	return timeType
@@ -381,3 +381,121 @@ func changeLocale(t *testing.T) {
 		}
 	})
 }
+
+func TestTime_Precision(t *testing.T) {
+	testCases := []struct {
+		name string
+		time system.Time
+		want int
+	}{
+		{"Hour", system.MustParseTime("10"), 2},
+		{"Minute", system.MustParseTime("10:30"), 4},
+		{"Second", system.MustParseTime("10:30:00"), 6},
+		{"Millisecond", system.MustParseTime("10:30:00.000"), 9},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.time.Precision(); got != tc.want {
+				t.Errorf("Time.Precision() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTime_Boundaries(t *testing.T) {
+	testCases := []struct {
+		name      string
+		time      system.Time
+		precision int
+		wantLow   system.Time
+		wantHigh  system.Time
+	}{
+		{
+			name:      "minute to millisecond",
+			time:      system.MustParseTime("10:30"),
+			precision: 9,
+			wantLow:   system.MustParseTime("10:30:00.000"),
+			wantHigh:  system.MustParseTime("10:30:59.999"),
+		},
+		{
+			name:      "last hour of the day",
+			time:      system.MustParseTime("23"),
+			precision: 6,
+			wantLow:   system.MustParseTime("23:00:00"),
+			wantHigh:  system.MustParseTime("23:59:59"),
+		},
+		{
+			name:      "second to hour",
+			time:      system.MustParseTime("10:30:15"),
+			precision: 2,
+			wantLow:   system.MustParseTime("10"),
+			wantHigh:  system.MustParseTime("10"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			low, ok := tc.time.LowBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Time.LowBoundary(%v) returned not ok", tc.precision)
+			}
+			if !cmp.Equal(low, tc.wantLow) {
+				t.Errorf("Time.LowBoundary(%v) = %v, want %v", tc.precision, low, tc.wantLow)
+			}
+			high, ok := tc.time.HighBoundary(tc.precision)
+			if !ok {
+				t.Fatalf("Time.HighBoundary(%v) returned not ok", tc.precision)
+			}
+			if !cmp.Equal(high, tc.wantHigh) {
+				t.Errorf("Time.HighBoundary(%v) = %v, want %v", tc.precision, high, tc.wantHigh)
+			}
+		})
+	}
+}
+
+func TestTime_Component(t *testing.T) {
+	testCases := []struct {
+		name      string
+		time      system.Time
+		component system.Component
+		want      int
+		wantOk    bool
+	}{
+		{"hour", system.MustParseTime("13:30:45.123"), system.HourComponent, 13, true},
+		{"minute", system.MustParseTime("13:30:45.123"), system.MinuteComponent, 30, true},
+		{"second", system.MustParseTime("13:30:45.123"), system.SecondComponent, 45, true},
+		{"millisecond", system.MustParseTime("13:30:45.123"), system.MillisecondComponent, 123, true},
+		{"second exceeds precision", system.MustParseTime("13:30"), system.SecondComponent, 0, false},
+		{"year is not a time component", system.MustParseTime("13:30"), system.YearComponent, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := tc.time.Component(tc.component)
+			if got != tc.want || ok != tc.wantOk {
+				t.Errorf("Time.Component(%v) = (%v, %v), want (%v, %v)", tc.component, got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestTime_DurationAndDifference(t *testing.T) {
+	from := system.MustParseTime("10:30:45")
+	to := system.MustParseTime("12:15:00")
+
+	duration, ok, err := from.Duration(to, "hours")
+	if want := system.MustParseQuantity("1", "hours"); err != nil || !ok || !duration.Equal(want) {
+		t.Errorf("Time.Duration() = (%v, %v, %v), want %v", duration, ok, err, want)
+	}
+	difference, ok, err := from.Difference(to, "hours")
+	if want := system.MustParseQuantity("2", "hours"); err != nil || !ok || !difference.Equal(want) {
+		t.Errorf("Time.Difference() = (%v, %v, %v), want %v", difference, ok, err, want)
+	}
+	if _, ok, err := system.MustParseTime("10:30").Duration(to, "seconds"); ok || err != nil {
+		t.Errorf("Time.Duration() returned (%v, %v), want (false, nil)", ok, err)
+	}
+	if _, _, err := from.Duration(to, "days"); !errors.Is(err, system.ErrMismatchedUnit) {
+		t.Errorf("Time.Duration() returned error %v, want %v", err, system.ErrMismatchedUnit)
+	}
+}
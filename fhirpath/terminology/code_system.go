@@ -0,0 +1,202 @@
+package terminology
+
+import (
+	"fmt"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/code_system_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
+)
+
+// codeSystem indexes the concepts of a CodeSystem resource by their code, and
+// records the subsumption hierarchy between them.
+type codeSystem struct {
+	url      string
+	version  string
+	name     string
+	concepts map[string]*cspb.CodeSystem_ConceptDefinition
+
+	// codes holds every code of the system, in definition order.
+	codes []string
+
+	// parents maps each code to the codes that directly subsume it. The
+	// hierarchy is given by nested concepts, and by the "parent" and "child"
+	// concept properties.
+	parents map[string][]string
+}
+
+func newCodeSystem(res *cspb.CodeSystem) *codeSystem {
+	cs := &codeSystem{
+		url:      res.GetUrl().GetValue(),
+		version:  res.GetVersion().GetValue(),
+		name:     res.GetName().GetValue(),
+		concepts: map[string]*cspb.CodeSystem_ConceptDefinition{},
+		parents:  map[string][]string{},
+	}
+	cs.addConcepts(res.GetConcept(), "")
+	return cs
+}
+
+// addConcepts adds the given concepts, which are nested within the parent
+// concept if it is not empty, and the concepts nested within them.
+func (cs *codeSystem) addConcepts(concepts []*cspb.CodeSystem_ConceptDefinition, parent string) {
+	for _, concept := range concepts {
+		code := concept.GetCode().GetValue()
+		if _, ok := cs.concepts[code]; !ok {
+			cs.codes = append(cs.codes, code)
+		}
+		cs.concepts[code] = concept
+		if parent != "" {
+			cs.parents[code] = append(cs.parents[code], parent)
+		}
+		for _, property := range concept.GetProperty() {
+			value := property.GetValue().GetCode().GetValue()
+			if value == "" {
+				continue
+			}
+			switch property.GetCode().GetValue() {
+			case "parent":
+				cs.parents[code] = append(cs.parents[code], value)
+			case "child":
+				cs.parents[value] = append(cs.parents[value], code)
+			}
+		}
+		cs.addConcepts(concept.GetConcept(), code)
+	}
+}
+
+// isA reports whether the ancestor code is the same as, or subsumes, the
+// given code.
+func (cs *codeSystem) isA(code, ancestor string) bool {
+	visited := map[string]bool{}
+	pending := []string{code}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if current == ancestor {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		pending = append(pending, cs.parents[current]...)
+	}
+	return false
+}
+
+// subsumption returns the relationship between the two codes, or an empty
+// outcome if either code is not in the code system.
+func (cs *codeSystem) subsumption(codeA, codeB string) evalopts.SubsumptionOutcome {
+	_, okA := cs.concepts[codeA]
+	_, okB := cs.concepts[codeB]
+	switch {
+	case !okA || !okB:
+		return ""
+	case codeA == codeB:
+		return evalopts.SubsumptionEquivalent
+	case cs.isA(codeB, codeA):
+		return evalopts.SubsumptionSubsumes
+	case cs.isA(codeA, codeB):
+		return evalopts.SubsumptionSubsumedBy
+	default:
+		return evalopts.SubsumptionNotSubsumed
+	}
+}
+
+// codeSystem returns the local code system with the given URL and optional
+// version.
+func (l *Local) codeSystem(url, version string) (*codeSystem, bool) {
+	if version != "" {
+		url += "|" + version
+	}
+	cs, ok := l.codeSystems[url]
+	return cs, ok
+}
+
+// knownCodeSystem returns the local code system with the given URL and
+// optional version, or an ErrUnknownCodeSystem error if there is none.
+func (l *Local) knownCodeSystem(url, version string) (*codeSystem, error) {
+	cs, ok := l.codeSystem(url, version)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCodeSystem, url)
+	}
+	return cs, nil
+}
+
+// Subsumes returns the relationship between two codes of the code system with
+// the given URL, where evalopts.SubsumptionSubsumes means that codeA is an
+// ancestor of codeB. An empty outcome is returned if the code system does not
+// define either code, and an ErrUnknownCodeSystem error if it is not available
+// locally.
+func (l *Local) Subsumes(system, codeA, codeB string) (evalopts.SubsumptionOutcome, error) {
+	cs, err := l.knownCodeSystem(system, "")
+	if err != nil {
+		return "", err
+	}
+	return cs.subsumption(codeA, codeB), nil
+}
+
+// Lookup returns the output parameters of the $lookup operation for the
+// coding: the name and version of its code system, the display of its concept,
+// and the codes of the concepts that directly subsume it as "parent"
+// properties. A nil result is returned if the code system does not define the
+// code, and an ErrUnknownCodeSystem error if it is not available locally.
+func (l *Local) Lookup(coding *dtpb.Coding) (*ppb.Parameters, error) {
+	cs, err := l.knownCodeSystem(coding.GetSystem().GetValue(), coding.GetVersion().GetValue())
+	if err != nil {
+		return nil, err
+	}
+	code := coding.GetCode().GetValue()
+	concept, ok := cs.concepts[code]
+	if !ok {
+		return nil, nil
+	}
+	name := cs.name
+	if name == "" {
+		name = cs.url
+	}
+	params := []*ppb.Parameters_Parameter{stringParameter("name", name)}
+	if cs.version != "" {
+		params = append(params, stringParameter("version", cs.version))
+	}
+	if display := concept.GetDisplay().GetValue(); display != "" {
+		params = append(params, stringParameter("display", display))
+	}
+	for _, parent := range cs.parents[code] {
+		params = append(params, partParameter("property", codeParameter("code", "parent"), codeParameter("value", parent)))
+	}
+	return &ppb.Parameters{Parameter: params}, nil
+}
+
+// ValidateCS returns the output parameters of the $validate-code operation of
+// the code system with the given canonical URL, whose result is true if any
+// coding of the concept is defined by the code system. Codings without a
+// system are matched by code alone. An ErrUnknownCodeSystem error is returned
+// if the code system is not available locally.
+func (l *Local) ValidateCS(codeSystem string, concept *dtpb.CodeableConcept) (*ppb.Parameters, error) {
+	cs, err := l.knownCodeSystem(codeSystem, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, coding := range concept.GetCoding() {
+		if system := coding.GetSystem().GetValue(); system != "" && system != cs.url {
+			continue
+		}
+		if definition, ok := cs.concepts[coding.GetCode().GetValue()]; ok {
+			return outcome(true, definition.GetDisplay().GetValue(), ""), nil
+		}
+	}
+	return outcome(false, "", fmt.Sprintf("no code is defined by code system %s", codeSystem)), nil
+}
+
+// display returns the display of the concept of the coding, if its code
+// system is available locally.
+func (l *Local) display(coding *dtpb.Coding) string {
+	cs, ok := l.codeSystem(coding.GetSystem().GetValue(), coding.GetVersion().GetValue())
+	if !ok {
+		return ""
+	}
+	return cs.concepts[coding.GetCode().GetValue()].GetDisplay().GetValue()
+}
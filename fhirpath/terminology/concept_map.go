@@ -0,0 +1,62 @@
+package terminology
+
+import (
+	"fmt"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/protofields"
+)
+
+// Translate translates the coding using the concept map with the given
+// canonical URL, returning the output parameters of the $translate operation.
+// Each target of the elements mapping the coding is returned as a "match"
+// parameter; the result is true if any match is neither unmatched nor
+// disjoint. A coding without a system is matched by code alone.
+func (l *Local) Translate(conceptMap string, coding *dtpb.Coding) (*ppb.Parameters, error) {
+	cm, ok := l.conceptMaps[conceptMap]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownConceptMap, conceptMap)
+	}
+	system, code := coding.GetSystem().GetValue(), coding.GetCode().GetValue()
+	result := false
+	var matches []*ppb.Parameters_Parameter
+	for _, group := range cm.GetGroup() {
+		if source := group.GetSource().GetValue(); system != "" && source != "" && source != system {
+			continue
+		}
+		for _, element := range group.GetElement() {
+			if element.GetCode().GetValue() != code {
+				continue
+			}
+			for _, target := range element.GetTarget() {
+				var parts []*ppb.Parameters_Parameter
+				if equivalence := target.GetEquivalence(); equivalence != nil {
+					value, _ := protofields.StringValueFromCodeField(equivalence)
+					parts = append(parts, codeParameter("equivalence", value))
+				}
+				if equivalence := target.GetEquivalence().GetValue(); equivalence != cpb.ConceptMapEquivalenceCode_UNMATCHED &&
+					equivalence != cpb.ConceptMapEquivalenceCode_DISJOINT {
+					result = true
+				}
+				if targetCode := target.GetCode().GetValue(); targetCode != "" {
+					concept := fhir.Coding(group.GetTarget().GetValue(), targetCode)
+					concept.Version = group.GetTargetVersion()
+					concept.Display = target.GetDisplay()
+					parts = append(parts, codingParameter("concept", concept))
+				}
+				parts = append(parts, uriParameter("source", cm.GetUrl().GetValue()))
+				matches = append(matches, partParameter("match", parts...))
+			}
+		}
+	}
+	message := ""
+	if !result {
+		message = fmt.Sprintf("no mapping found for code %s", code)
+	}
+	params := outcome(result, "", message)
+	params.Parameter = append(params.Parameter, matches...)
+	return params, nil
+}
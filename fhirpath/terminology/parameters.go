@@ -0,0 +1,58 @@
+package terminology
+
+import (
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+)
+
+// outcome returns the output parameters of an operation with a boolean result,
+// such as $validate-code or $translate. The display and message parameters are
+// omitted when empty.
+func outcome(result bool, display, message string) *ppb.Parameters {
+	params := []*ppb.Parameters_Parameter{
+		parameter("result", &ppb.Parameters_Parameter_ValueX{
+			Choice: &ppb.Parameters_Parameter_ValueX_Boolean{Boolean: fhir.Boolean(result)},
+		}),
+	}
+	if message != "" {
+		params = append(params, stringParameter("message", message))
+	}
+	if display != "" {
+		params = append(params, stringParameter("display", display))
+	}
+	return &ppb.Parameters{Parameter: params}
+}
+
+func parameter(name string, value *ppb.Parameters_Parameter_ValueX) *ppb.Parameters_Parameter {
+	return &ppb.Parameters_Parameter{Name: fhir.String(name), Value: value}
+}
+
+func stringParameter(name, value string) *ppb.Parameters_Parameter {
+	return parameter(name, &ppb.Parameters_Parameter_ValueX{
+		Choice: &ppb.Parameters_Parameter_ValueX_StringValue{StringValue: fhir.String(value)},
+	})
+}
+
+func codeParameter(name, value string) *ppb.Parameters_Parameter {
+	return parameter(name, &ppb.Parameters_Parameter_ValueX{
+		Choice: &ppb.Parameters_Parameter_ValueX_Code{Code: fhir.Code(value)},
+	})
+}
+
+func uriParameter(name, value string) *ppb.Parameters_Parameter {
+	return parameter(name, &ppb.Parameters_Parameter_ValueX{
+		Choice: &ppb.Parameters_Parameter_ValueX_Uri{Uri: fhir.URI(value)},
+	})
+}
+
+func codingParameter(name string, value *dtpb.Coding) *ppb.Parameters_Parameter {
+	return parameter(name, &ppb.Parameters_Parameter_ValueX{
+		Choice: &ppb.Parameters_Parameter_ValueX_Coding{Coding: value},
+	})
+}
+
+// partParameter returns a named parameter made up of the given parts.
+func partParameter(name string, parts ...*ppb.Parameters_Parameter) *ppb.Parameters_Parameter {
+	return &ppb.Parameters_Parameter{Name: fhir.String(name), Part: parts}
+}
@@ -0,0 +1,108 @@
+/*
+Package terminology provides an in-memory implementation of
+evalopts.TerminologyProvider, which answers the terminology queries of FHIRPath
+functions such as memberOf(), and of the methods of the %terminologies constant.
+
+The Local provider works entirely offline, over the ValueSet, CodeSystem and
+ConceptMap resources that it is given:
+
+	provider, err := terminology.NewLocal(valueSet, codeSystem)
+	if err != nil {
+		return err
+	}
+	result, err := expression.Evaluate(input, evalopts.Terminology(provider))
+*/
+package terminology
+
+import (
+	"errors"
+	"fmt"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/code_system_go_proto"
+	cmpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/concept_map_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"github.com/verily-src/fhirpath-go/internal/resource"
+)
+
+var (
+	ErrUnknownValueSet     = errors.New("unknown value set")
+	ErrUnknownCodeSystem   = errors.New("unknown code system")
+	ErrUnknownConceptMap   = errors.New("unknown concept map")
+	ErrUnsupportedFilter   = errors.New("unsupported value set filter")
+	ErrUnsupportedResource = errors.New("unsupported terminology resource")
+	ErrCircularValueSet    = errors.New("value set includes itself")
+)
+
+// Local is a TerminologyProvider over in-memory ValueSet, CodeSystem and
+// ConceptMap resources. Resources are looked up by their canonical URL, with or without a
+// "|version" suffix.
+//
+// Value sets are evaluated from their compose element, supporting included and
+// excluded concept lists, whole code systems, and imported value sets. A value
+// set without a compose element is evaluated from its expansion instead.
+// Filters are not supported.
+//
+// Code system hierarchies, used by Subsumes, are built from nested concepts and
+// from the "parent" and "child" concept properties.
+//
+// Concept maps, used by Translate, are evaluated from their groups of mapped
+// elements; unmapped elements are not supported.
+type Local struct {
+	valueSets   map[string]*vspb.ValueSet
+	codeSystems map[string]*codeSystem
+	conceptMaps map[string]*cmpb.ConceptMap
+}
+
+var _ evalopts.TerminologyProvider = (*Local)(nil)
+
+// NewLocal returns a Local provider over the given ValueSet, CodeSystem and
+// ConceptMap resources. Any other resource type results in an ErrUnsupportedResource
+// error.
+func NewLocal(resources ...fhir.Resource) (*Local, error) {
+	l := &Local{
+		valueSets:   map[string]*vspb.ValueSet{},
+		codeSystems: map[string]*codeSystem{},
+		conceptMaps: map[string]*cmpb.ConceptMap{},
+	}
+	for _, res := range resources {
+		switch res := res.(type) {
+		case *vspb.ValueSet:
+			addCanonical(l.valueSets, res, res)
+		case *cspb.CodeSystem:
+			addCanonical(l.codeSystems, res, newCodeSystem(res))
+		case *cmpb.ConceptMap:
+			addCanonical(l.conceptMaps, res, res)
+		default:
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedResource, resource.TypeOf(res))
+		}
+	}
+	return l, nil
+}
+
+// canonical is implemented by every resource with a canonical URL. Unlike
+// fhir.CanonicalResource, it includes ConceptMap, which has a single identifier.
+type canonical interface {
+	GetUrl() *dtpb.Uri
+	GetVersion() *dtpb.String
+}
+
+// addCanonical indexes the value by the canonical URL of the resource, both
+// with and without its version.
+func addCanonical[T any](index map[string]T, res canonical, value T) {
+	url := res.GetUrl().GetValue()
+	index[url] = value
+	if version := res.GetVersion().GetValue(); version != "" {
+		index[url+"|"+version] = value
+	}
+}
+
+func (l *Local) valueSet(url string) (*vspb.ValueSet, error) {
+	vs, ok := l.valueSets[url]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownValueSet, url)
+	}
+	return vs, nil
+}
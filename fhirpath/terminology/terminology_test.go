@@ -0,0 +1,691 @@
+package terminology_test
+
+import (
+	"errors"
+	"testing"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/code_system_go_proto"
+	cmpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/concept_map_go_proto"
+	parpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/verily-src/fhirpath-go/fhirpath/evalopts"
+	"github.com/verily-src/fhirpath-go/fhirpath/terminology"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+const (
+	colorSystem  = "http://example.com/CodeSystem/colors"
+	shapeSystem  = "http://example.com/CodeSystem/shapes"
+	animalSystem = "http://example.com/CodeSystem/animals"
+)
+
+var colors = &cspb.CodeSystem{
+	Url:     fhir.URI(colorSystem),
+	Version: fhir.String("1"),
+	Name:    fhir.String("Colors"),
+	Concept: []*cspb.CodeSystem_ConceptDefinition{
+		{
+			Code:    fhir.Code("red"),
+			Display: fhir.String("Red"),
+			Concept: []*cspb.CodeSystem_ConceptDefinition{
+				{Code: fhir.Code("crimson"), Display: fhir.String("Crimson")},
+			},
+		},
+		{Code: fhir.Code("green"), Display: fhir.String("Green")},
+		{Code: fhir.Code("blue"), Display: fhir.String("Blue")},
+	},
+}
+
+// animals has a hierarchy that is defined by nested concepts and by the parent
+// and child properties:
+//
+//	animal
+//	├── mammal
+//	│   ├── dog
+//	│   └── cat
+//	└── bird
+var animals = &cspb.CodeSystem{
+	Url: fhir.URI(animalSystem),
+	Concept: []*cspb.CodeSystem_ConceptDefinition{
+		{
+			Code: fhir.Code("animal"),
+			Concept: []*cspb.CodeSystem_ConceptDefinition{
+				{
+					Code:     fhir.Code("mammal"),
+					Concept:  []*cspb.CodeSystem_ConceptDefinition{{Code: fhir.Code("dog")}},
+					Property: []*cspb.CodeSystem_ConceptDefinition_ConceptProperty{codeProperty("child", "cat")},
+				},
+			},
+		},
+		{Code: fhir.Code("cat")},
+		{
+			Code:     fhir.Code("bird"),
+			Property: []*cspb.CodeSystem_ConceptDefinition_ConceptProperty{codeProperty("parent", "animal")},
+		},
+	},
+}
+
+const colorMap = "http://example.com/ConceptMap/colors-to-animals"
+
+// colorsToAnimals maps colors to the animals of that color.
+var colorsToAnimals = &cmpb.ConceptMap{
+	Url: fhir.URI(colorMap),
+	Group: []*cmpb.ConceptMap_Group{
+		{
+			Source: fhir.URI(colorSystem),
+			Target: fhir.URI(animalSystem),
+			Element: []*cmpb.ConceptMap_Group_SourceElement{
+				{
+					Code: fhir.Code("red"),
+					Target: []*cmpb.ConceptMap_Group_SourceElement_TargetElement{
+						mapTarget("bird", cpb.ConceptMapEquivalenceCode_RELATEDTO),
+						mapTarget("dog", cpb.ConceptMapEquivalenceCode_INEXACT),
+					},
+				},
+				{
+					Code: fhir.Code("blue"),
+					Target: []*cmpb.ConceptMap_Group_SourceElement_TargetElement{
+						mapTarget("", cpb.ConceptMapEquivalenceCode_UNMATCHED),
+					},
+				},
+			},
+		},
+	},
+}
+
+func mapTarget(code string, equivalence cpb.ConceptMapEquivalenceCode_Value) *cmpb.ConceptMap_Group_SourceElement_TargetElement {
+	target := &cmpb.ConceptMap_Group_SourceElement_TargetElement{
+		Equivalence: &cmpb.ConceptMap_Group_SourceElement_TargetElement_EquivalenceCode{Value: equivalence},
+	}
+	if code != "" {
+		target.Code = fhir.Code(code)
+	}
+	return target
+}
+
+func codeProperty(name, code string) *cspb.CodeSystem_ConceptDefinition_ConceptProperty {
+	return &cspb.CodeSystem_ConceptDefinition_ConceptProperty{
+		Code: fhir.Code(name),
+		Value: &cspb.CodeSystem_ConceptDefinition_ConceptProperty_ValueX{
+			Choice: &cspb.CodeSystem_ConceptDefinition_ConceptProperty_ValueX_Code{Code: fhir.Code(code)},
+		},
+	}
+}
+
+func filterSet(system string, op cpb.FilterOperatorCode_Value, value string) *vspb.ValueSet_Compose_ConceptSet {
+	return &vspb.ValueSet_Compose_ConceptSet{
+		System: fhir.URI(system),
+		Filter: []*vspb.ValueSet_Compose_ConceptSet_Filter{
+			{
+				Property: fhir.Code("concept"),
+				Op:       &vspb.ValueSet_Compose_ConceptSet_Filter_OpCode{Value: op},
+				Value:    fhir.String(value),
+			},
+		},
+	}
+}
+
+func valueSet(url string, include []*vspb.ValueSet_Compose_ConceptSet, exclude ...*vspb.ValueSet_Compose_ConceptSet) *vspb.ValueSet {
+	return &vspb.ValueSet{
+		Url: fhir.URI(url),
+		Compose: &vspb.ValueSet_Compose{
+			Include: include,
+			Exclude: exclude,
+		},
+	}
+}
+
+func conceptSet(system string, codes ...string) *vspb.ValueSet_Compose_ConceptSet {
+	set := &vspb.ValueSet_Compose_ConceptSet{System: fhir.URI(system)}
+	for _, code := range codes {
+		set.Concept = append(set.Concept, &vspb.ValueSet_Compose_ConceptSet_ConceptReference{
+			Code: fhir.Code(code),
+		})
+	}
+	return set
+}
+
+func importSet(urls ...string) *vspb.ValueSet_Compose_ConceptSet {
+	set := &vspb.ValueSet_Compose_ConceptSet{}
+	for _, url := range urls {
+		set.ValueSet = append(set.ValueSet, &dtpb.Canonical{Value: url})
+	}
+	return set
+}
+
+func newLocal(t *testing.T) *terminology.Local {
+	t.Helper()
+	local, err := terminology.NewLocal(
+		colors,
+		animals,
+		colorsToAnimals,
+		valueSet("http://example.com/ValueSet/all-colors", []*vspb.ValueSet_Compose_ConceptSet{
+			conceptSet(colorSystem),
+		}),
+		valueSet("http://example.com/ValueSet/warm", []*vspb.ValueSet_Compose_ConceptSet{
+			conceptSet(colorSystem, "red", "crimson"),
+			conceptSet(shapeSystem, "circle"),
+		}),
+		valueSet("http://example.com/ValueSet/cool", []*vspb.ValueSet_Compose_ConceptSet{
+			conceptSet(colorSystem),
+		}, conceptSet(colorSystem, "red", "crimson")),
+		valueSet("http://example.com/ValueSet/cool-and-warm", []*vspb.ValueSet_Compose_ConceptSet{
+			importSet("http://example.com/ValueSet/all-colors", "http://example.com/ValueSet/warm"),
+		}),
+		valueSet("http://example.com/ValueSet/any-shape", []*vspb.ValueSet_Compose_ConceptSet{
+			conceptSet(shapeSystem),
+		}),
+		&vspb.ValueSet{
+			Url: fhir.URI("http://example.com/ValueSet/expanded"),
+			Expansion: &vspb.ValueSet_Expansion{
+				Contains: []*vspb.ValueSet_Expansion_Contains{
+					{
+						System:   fhir.URI(colorSystem),
+						Abstract: fhir.Boolean(true),
+						Contains: []*vspb.ValueSet_Expansion_Contains{
+							{System: fhir.URI(colorSystem), Code: fhir.Code("green")},
+						},
+					},
+				},
+			},
+		},
+		valueSet("http://example.com/ValueSet/circular", []*vspb.ValueSet_Compose_ConceptSet{
+			importSet("http://example.com/ValueSet/circular"),
+		}),
+		valueSet("http://example.com/ValueSet/filtered", []*vspb.ValueSet_Compose_ConceptSet{
+			filterSet(colorSystem, cpb.FilterOperatorCode_IS_A, "red"),
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewLocal: unexpected err: %v", err)
+	}
+	return local
+}
+
+func TestNewLocal_UnsupportedResource_ReturnsError(t *testing.T) {
+	_, err := terminology.NewLocal(&ppb.Patient{})
+
+	if !errors.Is(err, terminology.ErrUnsupportedResource) {
+		t.Errorf("NewLocal: got err %v, want %v", err, terminology.ErrUnsupportedResource)
+	}
+}
+
+func TestLocal_MemberOf(t *testing.T) {
+	local := newLocal(t)
+	testCases := []struct {
+		name     string
+		valueSet string
+		coding   *dtpb.Coding
+		want     bool
+	}{
+		{"whole code system", "http://example.com/ValueSet/all-colors", fhir.Coding(colorSystem, "blue"), true},
+		{"nested code in whole code system", "http://example.com/ValueSet/all-colors", fhir.Coding(colorSystem, "crimson"), true},
+		{"code not in code system", "http://example.com/ValueSet/all-colors", fhir.Coding(colorSystem, "purple"), false},
+		{"other system", "http://example.com/ValueSet/all-colors", fhir.Coding(shapeSystem, "blue"), false},
+		{"code without system", "http://example.com/ValueSet/all-colors", &dtpb.Coding{Code: fhir.Code("blue")}, true},
+		{"listed concept", "http://example.com/ValueSet/warm", fhir.Coding(colorSystem, "crimson"), true},
+		{"concept not listed", "http://example.com/ValueSet/warm", fhir.Coding(colorSystem, "blue"), false},
+		{"listed concept in second include", "http://example.com/ValueSet/warm", fhir.Coding(shapeSystem, "circle"), true},
+		{"not excluded", "http://example.com/ValueSet/cool", fhir.Coding(colorSystem, "green"), true},
+		{"excluded", "http://example.com/ValueSet/cool", fhir.Coding(colorSystem, "red"), false},
+		{"in all imported value sets", "http://example.com/ValueSet/cool-and-warm", fhir.Coding(colorSystem, "red"), true},
+		{"in one imported value set", "http://example.com/ValueSet/cool-and-warm", fhir.Coding(colorSystem, "blue"), false},
+		{"nested in expansion", "http://example.com/ValueSet/expanded", fhir.Coding(colorSystem, "green"), true},
+		{"not in expansion", "http://example.com/ValueSet/expanded", fhir.Coding(colorSystem, "red"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := local.MemberOf(tc.valueSet, tc.coding)
+			if err != nil {
+				t.Fatalf("MemberOf: unexpected err: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("MemberOf(%v, %v) = %v, want %v", tc.valueSet, tc.coding, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLocal_MemberOf_ReturnsError(t *testing.T) {
+	local := newLocal(t)
+	testCases := []struct {
+		name     string
+		valueSet string
+		wantErr  error
+	}{
+		{"unknown value set", "http://example.com/ValueSet/unknown", terminology.ErrUnknownValueSet},
+		{"circular value set", "http://example.com/ValueSet/circular", terminology.ErrCircularValueSet},
+		{"unsupported filter", "http://example.com/ValueSet/filtered", terminology.ErrUnsupportedFilter},
+		{"whole code system not available locally", "http://example.com/ValueSet/any-shape", terminology.ErrUnknownCodeSystem},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := local.MemberOf(tc.valueSet, &dtpb.Coding{Code: fhir.Code("red")})
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("MemberOf(%v): got err %v, want %v", tc.valueSet, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocal_Expand(t *testing.T) {
+	local := newLocal(t)
+	contains := func(system, code, display string) *vspb.ValueSet_Expansion_Contains {
+		c := &vspb.ValueSet_Expansion_Contains{System: fhir.URI(system), Code: fhir.Code(code)}
+		if display != "" {
+			c.Display = fhir.String(display)
+		}
+		return c
+	}
+	testCases := []struct {
+		name     string
+		valueSet string
+		want     []*vspb.ValueSet_Expansion_Contains
+	}{
+		{
+			name:     "whole code system",
+			valueSet: "http://example.com/ValueSet/all-colors",
+			want: []*vspb.ValueSet_Expansion_Contains{
+				contains(colorSystem, "red", "Red"),
+				contains(colorSystem, "crimson", "Crimson"),
+				contains(colorSystem, "green", "Green"),
+				contains(colorSystem, "blue", "Blue"),
+			},
+		},
+		{
+			name:     "listed concepts",
+			valueSet: "http://example.com/ValueSet/warm",
+			want: []*vspb.ValueSet_Expansion_Contains{
+				contains(colorSystem, "red", "Red"),
+				contains(colorSystem, "crimson", "Crimson"),
+				contains(shapeSystem, "circle", ""),
+			},
+		},
+		{
+			name:     "excluded concepts",
+			valueSet: "http://example.com/ValueSet/cool",
+			want: []*vspb.ValueSet_Expansion_Contains{
+				contains(colorSystem, "green", "Green"),
+				contains(colorSystem, "blue", "Blue"),
+			},
+		},
+		{
+			name:     "imported value sets",
+			valueSet: "http://example.com/ValueSet/cool-and-warm",
+			want: []*vspb.ValueSet_Expansion_Contains{
+				contains(colorSystem, "red", "Red"),
+				contains(colorSystem, "crimson", "Crimson"),
+			},
+		},
+		{
+			name:     "existing expansion",
+			valueSet: "http://example.com/ValueSet/expanded",
+			want: []*vspb.ValueSet_Expansion_Contains{
+				contains(colorSystem, "green", ""),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := local.Expand(tc.valueSet)
+			if err != nil {
+				t.Fatalf("Expand: unexpected err: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got.GetExpansion().GetContains(), protocmp.Transform()); diff != "" {
+				t.Errorf("Expand(%v) returned unexpected diff (-want, +got):\n%s", tc.valueSet, diff)
+			}
+			if got, want := got.GetExpansion().GetTotal().GetValue(), int32(len(tc.want)); got != want {
+				t.Errorf("Expand(%v): got total %v, want %v", tc.valueSet, got, want)
+			}
+		})
+	}
+}
+
+func TestLocal_Expand_ReturnsError(t *testing.T) {
+	local := newLocal(t)
+	testCases := []struct {
+		name     string
+		valueSet string
+		wantErr  error
+	}{
+		{"unknown value set", "http://example.com/ValueSet/unknown", terminology.ErrUnknownValueSet},
+		{"unknown code system", "http://example.com/ValueSet/any-shape", terminology.ErrUnknownCodeSystem},
+		{"circular value set", "http://example.com/ValueSet/circular", terminology.ErrCircularValueSet},
+		{"unsupported filter", "http://example.com/ValueSet/filtered", terminology.ErrUnsupportedFilter},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := local.Expand(tc.valueSet)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Expand(%v): got err %v, want %v", tc.valueSet, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocal_Subsumes(t *testing.T) {
+	local := newLocal(t)
+	testCases := []struct {
+		name   string
+		system string
+		codeA  string
+		codeB  string
+		want   evalopts.SubsumptionOutcome
+	}{
+		{"same code", animalSystem, "dog", "dog", evalopts.SubsumptionEquivalent},
+		{"nested concept", animalSystem, "mammal", "dog", evalopts.SubsumptionSubsumes},
+		{"transitively nested concept", animalSystem, "animal", "dog", evalopts.SubsumptionSubsumes},
+		{"child property", animalSystem, "animal", "cat", evalopts.SubsumptionSubsumes},
+		{"parent property", animalSystem, "bird", "animal", evalopts.SubsumptionSubsumedBy},
+		{"unrelated concepts", animalSystem, "dog", "cat", evalopts.SubsumptionNotSubsumed},
+		{"unknown code", animalSystem, "dog", "fish", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := local.Subsumes(tc.system, tc.codeA, tc.codeB)
+			if err != nil {
+				t.Fatalf("Subsumes: unexpected err: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Subsumes(%v, %v) = %q, want %q", tc.codeA, tc.codeB, got, tc.want)
+			}
+		})
+	}
+}
+
+func parameters(params ...*parpb.Parameters_Parameter) *parpb.Parameters {
+	return &parpb.Parameters{Parameter: params}
+}
+
+func parameter(name string, value any) *parpb.Parameters_Parameter {
+	param := &parpb.Parameters_Parameter{Name: fhir.String(name), Value: &parpb.Parameters_Parameter_ValueX{}}
+	switch value := value.(type) {
+	case bool:
+		param.Value.Choice = &parpb.Parameters_Parameter_ValueX_Boolean{Boolean: fhir.Boolean(value)}
+	case string:
+		param.Value.Choice = &parpb.Parameters_Parameter_ValueX_StringValue{StringValue: fhir.String(value)}
+	case *dtpb.Code:
+		param.Value.Choice = &parpb.Parameters_Parameter_ValueX_Code{Code: value}
+	case *dtpb.Uri:
+		param.Value.Choice = &parpb.Parameters_Parameter_ValueX_Uri{Uri: value}
+	case *dtpb.Coding:
+		param.Value.Choice = &parpb.Parameters_Parameter_ValueX_Coding{Coding: value}
+	}
+	return param
+}
+
+func partParameter(name string, parts ...*parpb.Parameters_Parameter) *parpb.Parameters_Parameter {
+	return &parpb.Parameters_Parameter{Name: fhir.String(name), Part: parts}
+}
+
+func TestLocal_Lookup(t *testing.T) {
+	local := newLocal(t)
+	testCases := []struct {
+		name   string
+		coding *dtpb.Coding
+		want   *parpb.Parameters
+	}{
+		{
+			name:   "top-level concept",
+			coding: fhir.Coding(colorSystem, "red"),
+			want: parameters(
+				parameter("name", "Colors"),
+				parameter("version", "1"),
+				parameter("display", "Red"),
+			),
+		},
+		{
+			name:   "concept with parents",
+			coding: fhir.Coding(animalSystem, "cat"),
+			want: parameters(
+				parameter("name", animalSystem),
+				partParameter("property", parameter("code", fhir.Code("parent")), parameter("value", fhir.Code("mammal"))),
+			),
+		},
+		{
+			name:   "unknown code",
+			coding: fhir.Coding(colorSystem, "purple"),
+			want:   nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := local.Lookup(tc.coding)
+			if err != nil {
+				t.Fatalf("Lookup: unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Lookup(%v) returned unexpected diff (-want, +got):\n%s", tc.coding, diff)
+			}
+		})
+	}
+}
+
+func TestLocal_ValidateVS(t *testing.T) {
+	local := newLocal(t)
+	testCases := []struct {
+		name    string
+		concept *dtpb.CodeableConcept
+		want    *parpb.Parameters
+	}{
+		{
+			name:    "member coding",
+			concept: fhir.CodeableConcept("", fhir.Coding(colorSystem, "blue"), fhir.Coding(colorSystem, "red")),
+			want:    parameters(parameter("result", true), parameter("display", "Red")),
+		},
+		{
+			name:    "non-member coding",
+			concept: fhir.CodeableConcept("", fhir.Coding(colorSystem, "blue")),
+			want: parameters(
+				parameter("result", false),
+				parameter("message", "no code is a member of value set http://example.com/ValueSet/warm"),
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := local.ValidateVS("http://example.com/ValueSet/warm", tc.concept)
+			if err != nil {
+				t.Fatalf("ValidateVS: unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ValidateVS(%v) returned unexpected diff (-want, +got):\n%s", tc.name, diff)
+			}
+		})
+	}
+}
+
+func TestLocal_ValidateVS_ReturnsError(t *testing.T) {
+	local := newLocal(t)
+	testCases := []struct {
+		name     string
+		valueSet string
+		coding   *dtpb.Coding
+		wantErr  error
+	}{
+		{"unknown value set", "http://example.com/ValueSet/unknown", fhir.Coding(colorSystem, "red"), terminology.ErrUnknownValueSet},
+		{"whole code system not available locally", "http://example.com/ValueSet/any-shape", fhir.Coding(shapeSystem, "TOTALLY-BOGUS"), terminology.ErrUnknownCodeSystem},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := local.ValidateVS(tc.valueSet, fhir.CodeableConcept("", tc.coding))
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("ValidateVS(%v): got err %v, want %v", tc.valueSet, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocal_ValidateCS(t *testing.T) {
+	local := newLocal(t)
+	testCases := []struct {
+		name       string
+		codeSystem string
+		concept    *dtpb.CodeableConcept
+		want       *parpb.Parameters
+	}{
+		{
+			name:       "defined code",
+			codeSystem: colorSystem,
+			concept:    fhir.CodeableConcept("", fhir.Coding(colorSystem, "crimson")),
+			want:       parameters(parameter("result", true), parameter("display", "Crimson")),
+		},
+		{
+			name:       "code without system",
+			codeSystem: colorSystem,
+			concept:    fhir.CodeableConcept("", &dtpb.Coding{Code: fhir.Code("green")}),
+			want:       parameters(parameter("result", true), parameter("display", "Green")),
+		},
+		{
+			name:       "code of another system",
+			codeSystem: colorSystem,
+			concept:    fhir.CodeableConcept("", fhir.Coding(animalSystem, "red")),
+			want: parameters(
+				parameter("result", false),
+				parameter("message", "no code is defined by code system "+colorSystem),
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := local.ValidateCS(tc.codeSystem, tc.concept)
+			if err != nil {
+				t.Fatalf("ValidateCS: unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ValidateCS(%v) returned unexpected diff (-want, +got):\n%s", tc.name, diff)
+			}
+		})
+	}
+}
+
+func TestLocal_UnknownCodeSystem_ReturnsError(t *testing.T) {
+	local := newLocal(t)
+	testCases := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "Subsumes",
+			call: func() error {
+				_, err := local.Subsumes(shapeSystem, "circle", "circle")
+				return err
+			},
+		},
+		{
+			name: "Lookup",
+			call: func() error {
+				_, err := local.Lookup(fhir.Coding(shapeSystem, "circle"))
+				return err
+			},
+		},
+		{
+			name: "ValidateCS",
+			call: func() error {
+				_, err := local.ValidateCS(shapeSystem, fhir.CodeableConcept("", fhir.Coding(shapeSystem, "circle")))
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); !errors.Is(err, terminology.ErrUnknownCodeSystem) {
+				t.Errorf("%s: got err %v, want %v", tc.name, err, terminology.ErrUnknownCodeSystem)
+			}
+		})
+	}
+}
+
+func TestLocal_Translate(t *testing.T) {
+	local := newLocal(t)
+	match := func(code string, equivalence string) *parpb.Parameters_Parameter {
+		parts := []*parpb.Parameters_Parameter{parameter("equivalence", fhir.Code(equivalence))}
+		if code != "" {
+			parts = append(parts, parameter("concept", fhir.Coding(animalSystem, code)))
+		}
+		parts = append(parts, parameter("source", fhir.URI(colorMap)))
+		return partParameter("match", parts...)
+	}
+	testCases := []struct {
+		name   string
+		coding *dtpb.Coding
+		want   *parpb.Parameters
+	}{
+		{
+			name:   "mapped code",
+			coding: fhir.Coding(colorSystem, "red"),
+			want: parameters(
+				parameter("result", true),
+				match("bird", "relatedto"),
+				match("dog", "inexact"),
+			),
+		},
+		{
+			name:   "code without system",
+			coding: &dtpb.Coding{Code: fhir.Code("red")},
+			want: parameters(
+				parameter("result", true),
+				match("bird", "relatedto"),
+				match("dog", "inexact"),
+			),
+		},
+		{
+			name:   "unmatched code",
+			coding: fhir.Coding(colorSystem, "blue"),
+			want: parameters(
+				parameter("result", false),
+				parameter("message", "no mapping found for code blue"),
+				match("", "unmatched"),
+			),
+		},
+		{
+			name:   "code of another system",
+			coding: fhir.Coding(shapeSystem, "red"),
+			want: parameters(
+				parameter("result", false),
+				parameter("message", "no mapping found for code red"),
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := local.Translate(colorMap, tc.coding)
+			if err != nil {
+				t.Fatalf("Translate: unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Translate(%v) returned unexpected diff (-want, +got):\n%s", tc.coding, diff)
+			}
+		})
+	}
+}
+
+func TestLocal_Translate_UnknownConceptMap_ReturnsError(t *testing.T) {
+	local := newLocal(t)
+
+	_, err := local.Translate("http://example.com/ConceptMap/unknown", fhir.Coding(colorSystem, "red"))
+
+	if !errors.Is(err, terminology.ErrUnknownConceptMap) {
+		t.Errorf("Translate: got err %v, want %v", err, terminology.ErrUnknownConceptMap)
+	}
+}
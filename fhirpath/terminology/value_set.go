@@ -0,0 +1,315 @@
+package terminology
+
+import (
+	"fmt"
+
+	dtpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+	"github.com/verily-src/fhirpath-go/internal/fhir"
+	"google.golang.org/protobuf/proto"
+)
+
+// MemberOf reports whether the coding is a member of the value set with the
+// given canonical URL. A coding without a system is matched by code alone.
+//
+// Codes included from a whole code system must be checked against that code
+// system, so an ErrUnknownCodeSystem error is returned if it is not available
+// locally.
+func (l *Local) MemberOf(valueSet string, coding *dtpb.Coding) (bool, error) {
+	return l.memberOf(valueSet, coding, map[string]bool{})
+}
+
+// ValidateVS returns the output parameters of the $validate-code operation of
+// the value set with the given canonical URL, whose result is true if any
+// coding of the concept is a member of the value set.
+func (l *Local) ValidateVS(valueSet string, concept *dtpb.CodeableConcept) (*ppb.Parameters, error) {
+	for _, coding := range concept.GetCoding() {
+		member, err := l.MemberOf(valueSet, coding)
+		if err != nil {
+			return nil, err
+		}
+		if member {
+			return outcome(true, l.display(coding), ""), nil
+		}
+	}
+	if _, err := l.valueSet(valueSet); err != nil {
+		return nil, err
+	}
+	return outcome(false, "", fmt.Sprintf("no code is a member of value set %s", valueSet)), nil
+}
+
+// Expand returns a copy of the value set with the given canonical URL, whose
+// expansion lists every code in the value set. Whole code systems included by
+// the value set must be available locally.
+func (l *Local) Expand(valueSet string) (*vspb.ValueSet, error) {
+	vs, err := l.valueSet(valueSet)
+	if err != nil {
+		return nil, err
+	}
+	contains, err := l.expand(vs, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	expanded := proto.Clone(vs).(*vspb.ValueSet)
+	expanded.Expansion = &vspb.ValueSet_Expansion{
+		Timestamp: fhir.DateTimeNow(),
+		Total:     fhir.Integer(int32(len(contains))),
+		Contains:  contains,
+	}
+	return expanded, nil
+}
+
+// enter marks the value set as being evaluated, detecting value sets that
+// include themselves. The returned function must be called once evaluation of
+// the value set is done.
+func (l *Local) enter(url string, visiting map[string]bool) (*vspb.ValueSet, func(), error) {
+	if visiting[url] {
+		return nil, nil, fmt.Errorf("%w: %s", ErrCircularValueSet, url)
+	}
+	vs, err := l.valueSet(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	visiting[url] = true
+	return vs, func() { delete(visiting, url) }, nil
+}
+
+func (l *Local) memberOf(url string, coding *dtpb.Coding, visiting map[string]bool) (bool, error) {
+	vs, leave, err := l.enter(url, visiting)
+	if err != nil {
+		return false, err
+	}
+	defer leave()
+
+	compose := vs.GetCompose()
+	if compose == nil {
+		return expansionContains(vs.GetExpansion().GetContains(), coding), nil
+	}
+	included := false
+	for _, set := range compose.GetInclude() {
+		ok, err := l.inConceptSet(set, coding, visiting)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false, nil
+	}
+	for _, set := range compose.GetExclude() {
+		ok, err := l.inConceptSet(set, coding, visiting)
+		if err != nil || ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// inConceptSet reports whether the coding is selected by an include or exclude
+// element of a value set. The codes of a concept set are those selected from
+// its system, if any, that are also members of all of its value sets.
+func (l *Local) inConceptSet(set *vspb.ValueSet_Compose_ConceptSet, coding *dtpb.Coding, visiting map[string]bool) (bool, error) {
+	system := set.GetSystem().GetValue()
+	if system == "" && len(set.GetValueSet()) == 0 {
+		return false, nil
+	}
+	if system != "" {
+		if codingSystem := coding.GetSystem().GetValue(); codingSystem != "" && codingSystem != system {
+			return false, nil
+		}
+		ok, err := l.inSystem(set, coding.GetCode().GetValue())
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	for _, vs := range set.GetValueSet() {
+		ok, err := l.memberOf(vs.GetValue(), coding, visiting)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// inSystem reports whether the code is selected from the system of a concept
+// set, either by its concept list or as part of the whole system.
+func (l *Local) inSystem(set *vspb.ValueSet_Compose_ConceptSet, code string) (bool, error) {
+	if len(set.GetConcept()) > 0 {
+		for _, concept := range set.GetConcept() {
+			if concept.GetCode().GetValue() == code {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if len(set.GetFilter()) > 0 {
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedFilter, set.GetFilter()[0].GetOp().GetValue())
+	}
+	system := set.GetSystem().GetValue()
+	cs, ok := l.codeSystem(system, set.GetVersion().GetValue())
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrUnknownCodeSystem, system)
+	}
+	_, ok = cs.concepts[code]
+	return ok, nil
+}
+
+func (l *Local) expand(vs *vspb.ValueSet, visiting map[string]bool) ([]*vspb.ValueSet_Expansion_Contains, error) {
+	compose := vs.GetCompose()
+	if compose == nil {
+		return flatten(vs.GetExpansion().GetContains()), nil
+	}
+	var included []*vspb.ValueSet_Expansion_Contains
+	for _, set := range compose.GetInclude() {
+		contains, err := l.expandConceptSet(set, visiting)
+		if err != nil {
+			return nil, err
+		}
+		included = union(included, contains)
+	}
+	for _, set := range compose.GetExclude() {
+		contains, err := l.expandConceptSet(set, visiting)
+		if err != nil {
+			return nil, err
+		}
+		included = difference(included, contains)
+	}
+	return included, nil
+}
+
+func (l *Local) expandConceptSet(set *vspb.ValueSet_Compose_ConceptSet, visiting map[string]bool) ([]*vspb.ValueSet_Expansion_Contains, error) {
+	var result []*vspb.ValueSet_Expansion_Contains
+	system := set.GetSystem().GetValue()
+	if system != "" {
+		contains, err := l.expandSystem(set)
+		if err != nil {
+			return nil, err
+		}
+		result = contains
+	}
+	for i, canonical := range set.GetValueSet() {
+		url := canonical.GetValue()
+		vs, leave, err := l.enter(url, visiting)
+		if err != nil {
+			return nil, err
+		}
+		contains, err := l.expand(vs, visiting)
+		leave()
+		if err != nil {
+			return nil, err
+		}
+		if system == "" && i == 0 {
+			result = contains
+		} else {
+			result = intersection(result, contains)
+		}
+	}
+	return result, nil
+}
+
+// expandSystem lists the codes selected from the system of a concept set.
+func (l *Local) expandSystem(set *vspb.ValueSet_Compose_ConceptSet) ([]*vspb.ValueSet_Expansion_Contains, error) {
+	system, version := set.GetSystem().GetValue(), set.GetVersion().GetValue()
+	cs, hasCodeSystem := l.codeSystem(system, version)
+	var result []*vspb.ValueSet_Expansion_Contains
+	if len(set.GetConcept()) > 0 {
+		for _, concept := range set.GetConcept() {
+			code := concept.GetCode().GetValue()
+			display := concept.GetDisplay().GetValue()
+			if display == "" && hasCodeSystem {
+				display = cs.concepts[code].GetDisplay().GetValue()
+			}
+			result = append(result, newContains(system, version, code, display))
+		}
+		return result, nil
+	}
+	if len(set.GetFilter()) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFilter, set.GetFilter()[0].GetOp().GetValue())
+	}
+	if !hasCodeSystem {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCodeSystem, system)
+	}
+	for _, code := range cs.codes {
+		result = append(result, newContains(system, version, code, cs.concepts[code].GetDisplay().GetValue()))
+	}
+	return result, nil
+}
+
+func newContains(system, version, code, display string) *vspb.ValueSet_Expansion_Contains {
+	contains := &vspb.ValueSet_Expansion_Contains{
+		System: fhir.URI(system),
+		Code:   fhir.Code(code),
+	}
+	if version != "" {
+		contains.Version = fhir.String(version)
+	}
+	if display != "" {
+		contains.Display = fhir.String(display)
+	}
+	return contains
+}
+
+// expansionContains reports whether the coding is listed in an expansion,
+// including within nested contains elements.
+func expansionContains(contains []*vspb.ValueSet_Expansion_Contains, coding *dtpb.Coding) bool {
+	for _, c := range flatten(contains) {
+		if c.GetCode().GetValue() != coding.GetCode().GetValue() {
+			continue
+		}
+		if system := coding.GetSystem().GetValue(); system == "" || system == c.GetSystem().GetValue() {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten lists the codes of an expansion, moving nested contains elements to
+// the top level and omitting abstract entries, which are not codes of the value
+// set.
+func flatten(contains []*vspb.ValueSet_Expansion_Contains) []*vspb.ValueSet_Expansion_Contains {
+	var result []*vspb.ValueSet_Expansion_Contains
+	for _, c := range contains {
+		if !c.GetAbstract().GetValue() && c.GetCode() != nil {
+			flat := proto.Clone(c).(*vspb.ValueSet_Expansion_Contains)
+			flat.Contains = nil
+			result = append(result, flat)
+		}
+		result = append(result, flatten(c.GetContains())...)
+	}
+	return result
+}
+
+func key(c *vspb.ValueSet_Expansion_Contains) string {
+	return c.GetSystem().GetValue() + "|" + c.GetCode().GetValue()
+}
+
+func union(lhs, rhs []*vspb.ValueSet_Expansion_Contains) []*vspb.ValueSet_Expansion_Contains {
+	return append(lhs, difference(rhs, lhs)...)
+}
+
+func intersection(lhs, rhs []*vspb.ValueSet_Expansion_Contains) []*vspb.ValueSet_Expansion_Contains {
+	return filter(lhs, rhs, true)
+}
+
+func difference(lhs, rhs []*vspb.ValueSet_Expansion_Contains) []*vspb.ValueSet_Expansion_Contains {
+	return filter(lhs, rhs, false)
+}
+
+// filter returns the codes of lhs that are, or are not, also in rhs.
+func filter(lhs, rhs []*vspb.ValueSet_Expansion_Contains, inRHS bool) []*vspb.ValueSet_Expansion_Contains {
+	keys := map[string]bool{}
+	for _, c := range rhs {
+		keys[key(c)] = true
+	}
+	var result []*vspb.ValueSet_Expansion_Contains
+	for _, c := range lhs {
+		if keys[key(c)] == inRHS {
+			result = append(result, c)
+		}
+	}
+	return result
+}
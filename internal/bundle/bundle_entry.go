@@ -127,6 +127,18 @@ func NewCollectionEntry(res fhir.Resource) *bcrpb.Bundle_Entry {
 	}
 }
 
+// NewSearchsetEntry takes in a FHIR Resource that matched a search and creates
+// a BundleEntry for the resource, with the search mode "match".
+func NewSearchsetEntry(res fhir.Resource, opts ...EntryOption) *bcrpb.Bundle_Entry {
+	entry := NewCollectionEntry(res)
+	entry.Search = &bcrpb.Bundle_Entry_Search{
+		Mode: &bcrpb.Bundle_Entry_Search_ModeCode{
+			Value: cpb.SearchEntryModeCode_MATCH,
+		},
+	}
+	return applyOptions(entry, opts)
+}
+
 type patchOps []struct {
 	Op    string      `json:"op"`
 	Path  string      `json:"path"`
@@ -275,6 +275,22 @@ func TestCollectionEntry(t *testing.T) {
 	}
 }
 
+func TestSearchsetEntry(t *testing.T) {
+	wantEntry := &bcrpb.Bundle_Entry{
+		Resource: containedresource.Wrap(patient),
+		FullUrl:  &dtpb.Uri{Value: "http://example.com/fhir/Patient/IU"},
+		Search: &bcrpb.Bundle_Entry_Search{
+			Mode: &bcrpb.Bundle_Entry_Search_ModeCode{Value: cpb.SearchEntryModeCode_MATCH},
+		},
+	}
+	entry := bundle.NewSearchsetEntry(patient, bundle.WithFullURL("http://example.com/fhir/Patient/IU"))
+
+	got, want := entry, wantEntry
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("SearchsetEntry mismatch (-want, +got):\n%s", diff)
+	}
+}
+
 // Test helper to create a BundleEntry of a Patient
 func makePatientEntry(method cpb.HTTPVerbCode_Value, res fhir.Resource, uri *dtpb.Uri, header string) *bcrpb.Bundle_Entry {
 	requestURL := "Patient"
@@ -197,8 +197,9 @@ func Quantity(value float64, unit string) *dtpb.Quantity {
 // UCUMQuantity creates an R4 FHIR Quantity element representing a
 // value and UCUM unit.
 //
+// The unit is not validated, which may be done with units.ParseUCUM.
+//
 // See: http://hl7.org/fhir/R4/datatypes.html#quantity
-// TODO(PHP-9521): Add a unit package to validate against UCUM units.
 func UCUMQuantity(value float64, unit string) *dtpb.Quantity {
 	return &dtpb.Quantity{
 		Value:  Decimal(value),
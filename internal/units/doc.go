@@ -1,5 +1,6 @@
 /*
 Package units provides basic unit constants that are used for various FHIR
-Quantity types.
+Quantity types, along with a parser of UCUM units, which converts values
+between commensurable units.
 */
 package units
@@ -0,0 +1,259 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidUCUM is returned when a unit expression is not a valid UCUM unit,
+// or uses units that are not supported.
+var ErrInvalidUCUM = errors.New("invalid UCUM unit")
+
+// UCUM is a unit of the Unified Code for Units of Measure, in its canonical
+// form: a magnitude multiplied by a product of powers of base units. Arbitrary
+// units, such as [iU], are treated as base units of their own.
+//
+// Special units, which convert with a function rather than a factor (such as
+// Cel and [degF]), are not supported.
+//
+// For more details, see https://ucum.org/ucum
+type UCUM struct {
+	magnitude  *big.Rat
+	dimensions map[string]int
+}
+
+// unity is the dimensionless unit 1.
+var unity = UCUM{magnitude: big.NewRat(1, 1)}
+
+// ParseUCUM parses the UCUM unit expression, and returns its canonical form.
+// Returns an error wrapping ErrInvalidUCUM if the expression is not valid, or
+// refers to an unknown unit.
+func ParseUCUM(expression string) (UCUM, error) {
+	p := &ucumParser{expression: expression}
+	u, err := p.term()
+	if err != nil {
+		return UCUM{}, fmt.Errorf("%w '%s': %v", ErrInvalidUCUM, expression, err)
+	}
+	if p.pos != len(expression) {
+		return UCUM{}, fmt.Errorf("%w '%s': unexpected '%c'", ErrInvalidUCUM, expression, expression[p.pos])
+	}
+	return u, nil
+}
+
+// Commensurable reports whether u and other measure the same dimension, such
+// that values can be converted between them.
+func (u UCUM) Commensurable(other UCUM) bool {
+	if len(u.dimensions) != len(other.dimensions) {
+		return false
+	}
+	for base, exponent := range u.dimensions {
+		if other.dimensions[base] != exponent {
+			return false
+		}
+	}
+	return true
+}
+
+// Ratio returns the factor by which values in u are multiplied to convert them
+// to other. Returns false if the units are not commensurable.
+func (u UCUM) Ratio(other UCUM) (*big.Rat, bool) {
+	if !u.Commensurable(other) {
+		return nil, false
+	}
+	return new(big.Rat).Quo(u.magnitude, other.magnitude), true
+}
+
+func (u UCUM) mul(other UCUM) UCUM {
+	result := UCUM{magnitude: new(big.Rat).Mul(u.magnitude, other.magnitude)}
+	for _, dimensions := range []map[string]int{u.dimensions, other.dimensions} {
+		for base, exponent := range dimensions {
+			result = result.withDimension(base, exponent)
+		}
+	}
+	return result
+}
+
+func (u UCUM) pow(n int) UCUM {
+	magnitude := big.NewRat(1, 1)
+	factor := u.magnitude
+	if n < 0 {
+		factor = new(big.Rat).Inv(factor)
+	}
+	for i := 0; i < n || i < -n; i++ {
+		magnitude.Mul(magnitude, factor)
+	}
+	result := UCUM{magnitude: magnitude}
+	for base, exponent := range u.dimensions {
+		result = result.withDimension(base, exponent*n)
+	}
+	return result
+}
+
+func (u UCUM) scale(factor *big.Rat) UCUM {
+	return UCUM{magnitude: new(big.Rat).Mul(u.magnitude, factor), dimensions: u.dimensions}
+}
+
+// withDimension returns u multiplied by the base unit to the given power. The
+// dimensions of u are copied, since they may be shared with other units.
+func (u UCUM) withDimension(base string, exponent int) UCUM {
+	dimensions := make(map[string]int, len(u.dimensions)+1)
+	for b, e := range u.dimensions {
+		dimensions[b] = e
+	}
+	if dimensions[base] += exponent; dimensions[base] == 0 {
+		delete(dimensions, base)
+	}
+	return UCUM{magnitude: u.magnitude, dimensions: dimensions}
+}
+
+// ucumParser parses the terms of UCUM unit expressions. Terms are components
+// joined by the '.' and '/' operators, which apply from left to right.
+type ucumParser struct {
+	expression string
+	pos        int
+}
+
+func (p *ucumParser) term() (UCUM, error) {
+	result := unity
+	op := byte('.')
+	if p.peek() == '/' {
+		op = '/'
+		p.pos++
+	}
+	for {
+		component, err := p.component()
+		if err != nil {
+			return UCUM{}, err
+		}
+		if op == '/' {
+			component = component.pow(-1)
+		}
+		result = result.mul(component)
+		if op = p.peek(); op != '.' && op != '/' {
+			return result, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *ucumParser) component() (UCUM, error) {
+	if p.peek() == '(' {
+		p.pos++
+		result, err := p.term()
+		if err != nil {
+			return UCUM{}, err
+		}
+		if p.peek() != ')' {
+			return UCUM{}, errors.New("missing ')'")
+		}
+		p.pos++
+		return result, nil
+	}
+	start := p.pos
+	for p.pos < len(p.expression) {
+		switch p.expression[p.pos] {
+		case '.', '/', '(', ')':
+			return annotatable(p.expression[start:p.pos])
+		case '{', '[':
+			closing := byte('}')
+			if p.expression[p.pos] == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(p.expression[p.pos:], closing)
+			if end < 0 {
+				return UCUM{}, fmt.Errorf("missing '%c'", closing)
+			}
+			p.pos += end + 1
+		default:
+			p.pos++
+		}
+	}
+	return annotatable(p.expression[start:])
+}
+
+func (p *ucumParser) peek() byte {
+	if p.pos < len(p.expression) {
+		return p.expression[p.pos]
+	}
+	return 0
+}
+
+// annotatable returns the unit of a simple unit symbol, with an optional
+// exponent and an optional trailing annotation. Annotations alone stand for
+// the unit 1.
+func annotatable(component string) (UCUM, error) {
+	if start := strings.IndexByte(component, '{'); start >= 0 {
+		if !strings.HasSuffix(component, "}") {
+			return UCUM{}, fmt.Errorf("unexpected text after annotation '%s'", component)
+		}
+		if component = component[:start]; component == "" {
+			return unity, nil
+		}
+	}
+	if component == "" {
+		return UCUM{}, errors.New("missing unit")
+	}
+	if isDigits(component) {
+		factor, ok := new(big.Rat).SetString(component)
+		if !ok {
+			return UCUM{}, fmt.Errorf("invalid factor '%s'", component)
+		}
+		return unity.scale(factor), nil
+	}
+	symbol, exponent := splitExponent(component)
+	u, ok := lookupUnit(symbol)
+	if !ok {
+		return UCUM{}, fmt.Errorf("unknown unit '%s'", symbol)
+	}
+	return u.pow(exponent), nil
+}
+
+// splitExponent splits the trailing signed integer exponent from a simple unit
+// symbol, which is 1 if there is none.
+func splitExponent(component string) (string, int) {
+	end := len(component)
+	for end > 0 && isDigits(component[end-1:end]) {
+		end--
+	}
+	if end > 0 && end < len(component) && (component[end-1] == '+' || component[end-1] == '-') {
+		end--
+	}
+	if end == len(component) || end == 0 {
+		return component, 1
+	}
+	exponent, err := strconv.Atoi(component[end:])
+	if err != nil {
+		return component, 1
+	}
+	return component[:end], exponent
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// lookupUnit returns the canonical form of the unit symbol, which may be
+// preceded by a prefix if the unit is metric.
+func lookupUnit(symbol string) (UCUM, bool) {
+	if u, ok := ucumUnits[symbol]; ok {
+		return u, true
+	}
+	for prefix, factor := range ucumPrefixes {
+		name, ok := strings.CutPrefix(symbol, prefix)
+		if !ok || !metricUnits[name] {
+			continue
+		}
+		if u, ok := ucumUnits[name]; ok {
+			return u.scale(factor), true
+		}
+	}
+	return UCUM{}, false
+}
@@ -0,0 +1,203 @@
+package units
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ucumPrefixes maps the UCUM prefixes to their factors.
+var ucumPrefixes = map[string]*big.Rat{
+	"Y":  ratPow10(24),
+	"Z":  ratPow10(21),
+	"E":  ratPow10(18),
+	"P":  ratPow10(15),
+	"T":  ratPow10(12),
+	"G":  ratPow10(9),
+	"M":  ratPow10(6),
+	"k":  ratPow10(3),
+	"h":  ratPow10(2),
+	"da": ratPow10(1),
+	"d":  ratPow10(-1),
+	"c":  ratPow10(-2),
+	"m":  ratPow10(-3),
+	"u":  ratPow10(-6),
+	"n":  ratPow10(-9),
+	"p":  ratPow10(-12),
+	"f":  ratPow10(-15),
+	"a":  ratPow10(-18),
+	"z":  ratPow10(-21),
+	"y":  ratPow10(-24),
+}
+
+// ucumDefinition defines a UCUM unit as a value of another unit, or as a base
+// unit if the unit is empty. Prefixes only apply to metric units.
+type ucumDefinition struct {
+	symbol string
+	metric bool
+	value  string
+	unit   string
+}
+
+// ucumDefinitions are the supported UCUM units, which are defined in terms of
+// the units that precede them.
+var ucumDefinitions = []ucumDefinition{
+	// Base units.
+	{symbol: "m", metric: true},
+	{symbol: "s", metric: true},
+	{symbol: "g", metric: true},
+	{symbol: "rad", metric: true},
+	{symbol: "K", metric: true},
+	{symbol: "C", metric: true},
+	{symbol: "cd", metric: true},
+
+	// Dimensionless units.
+	{"10*", false, "10", "1"},
+	{"10^", false, "10", "1"},
+	{"[pi]", false, "3.1415926535897932384626433832795028841971693993751058209749445923", "1"},
+	{"%", false, "1", "10*-2"},
+	{"[ppth]", false, "1", "10*-3"},
+	{"[ppm]", false, "1", "10*-6"},
+	{"[ppb]", false, "1", "10*-9"},
+	{"[pptr]", false, "1", "10*-12"},
+
+	// SI units.
+	{"mol", true, "6.0221367", "10*23"},
+	{"sr", true, "1", "rad2"},
+	{"Hz", true, "1", "s-1"},
+	{"N", true, "1", "kg.m/s2"},
+	{"Pa", true, "1", "N/m2"},
+	{"J", true, "1", "N.m"},
+	{"W", true, "1", "J/s"},
+	{"A", true, "1", "C/s"},
+	{"V", true, "1", "J/C"},
+	{"F", true, "1", "C/V"},
+	{"Ohm", true, "1", "V/A"},
+	{"S", true, "1", "Ohm-1"},
+	{"Wb", true, "1", "V.s"},
+	{"T", true, "1", "Wb/m2"},
+	{"H", true, "1", "Wb/A"},
+	{"lm", true, "1", "cd.sr"},
+	{"lx", true, "1", "lm/m2"},
+	{"Bq", true, "1", "s-1"},
+	{"Gy", true, "1", "J/kg"},
+	{"Sv", true, "1", "J/kg"},
+
+	// Other units from ISO 1000, ISO 2955 and ANSI X3.50.
+	{"deg", false, "2", "[pi].rad/360"},
+	{"gon", false, "0.9", "deg"},
+	{"l", true, "1", "dm3"},
+	{"L", true, "1", "l"},
+	{"ar", true, "100", "m2"},
+	{"min", false, "60", "s"},
+	{"h", false, "60", "min"},
+	{"d", false, "24", "h"},
+	{"a_t", false, "365.24219", "d"},
+	{"a_j", false, "365.25", "d"},
+	{"a_g", false, "365.2425", "d"},
+	{"a", false, "1", "a_j"},
+	{"wk", false, "7", "d"},
+	{"mo_s", false, "29.53059", "d"},
+	{"mo_j", false, "1", "a_j/12"},
+	{"mo_g", false, "1", "a_g/12"},
+	{"mo", false, "1", "mo_j"},
+	{"t", true, "1000", "kg"},
+	{"bar", true, "100000", "Pa"},
+	{"u", true, "1.6605402e-24", "g"},
+	{"[e]", true, "1.60217733e-19", "C"},
+	{"eV", true, "1", "[e].V"},
+
+	// Natural units.
+	{"[g]", false, "9.80665", "m/s2"},
+	{"atm", false, "101325", "Pa"},
+
+	// CGS units.
+	{"dyn", true, "1", "g.cm/s2"},
+	{"erg", true, "1", "dyn.cm"},
+	{"G", true, "0.0001", "T"},
+	{"gf", true, "1", "g.[g]"},
+	{"Ci", true, "37000000000", "Bq"},
+
+	// International customary units.
+	{"[in_i]", false, "2.54", "cm"},
+	{"[ft_i]", false, "12", "[in_i]"},
+	{"[yd_i]", false, "3", "[ft_i]"},
+	{"[mi_i]", false, "5280", "[ft_i]"},
+	{"[nmi_i]", false, "1852", "m"},
+	{"[sin_i]", false, "1", "[in_i]2"},
+	{"[sft_i]", false, "1", "[ft_i]2"},
+	{"[cin_i]", false, "1", "[in_i]3"},
+	{"[cft_i]", false, "1", "[ft_i]3"},
+
+	// US volumes.
+	{"[gal_us]", false, "231", "[in_i]3"},
+	{"[qt_us]", false, "1/4", "[gal_us]"},
+	{"[pt_us]", false, "1/2", "[qt_us]"},
+	{"[foz_us]", false, "1/16", "[pt_us]"},
+	{"[cup_us]", false, "8", "[foz_us]"},
+	{"[tbs_us]", false, "1/2", "[foz_us]"},
+	{"[tsp_us]", false, "1/3", "[tbs_us]"},
+
+	// Avoirdupois weights.
+	{"[gr]", false, "64.79891", "mg"},
+	{"[lb_av]", false, "7000", "[gr]"},
+	{"[oz_av]", false, "1/16", "[lb_av]"},
+	{"[dr_av]", false, "1/16", "[oz_av]"},
+	{"[stone_av]", false, "14", "[lb_av]"},
+	{"[lbf_av]", false, "1", "[lb_av].[g]"},
+	{"[psi]", false, "1", "[lbf_av]/[in_i]2"},
+
+	// Units used in medicine.
+	{"m[Hg]", true, "133.322", "kPa"},
+	{"m[H2O]", true, "9.80665", "kPa"},
+	{"[drp]", false, "1/20", "mL"},
+	{"cal", true, "4.184", "J"},
+	{"[Cal]", false, "1", "kcal"},
+	{"eq", true, "1", "mol"},
+	{"osm", true, "1", "mol"},
+	{"kat", true, "1", "mol/s"},
+	{"U", true, "1", "umol/min"},
+	{"g%", true, "1", "g/dL"},
+
+	// Arbitrary units, which are only commensurable with themselves.
+	{symbol: "[iU]", metric: true},
+	{"[IU]", true, "1", "[iU]"},
+	{symbol: "[arb'U]", metric: true},
+	{symbol: "[USP'U]", metric: true},
+	{symbol: "[CFU]", metric: true},
+}
+
+var (
+	// ucumUnits maps the symbols of the supported UCUM units, without
+	// prefixes, to their canonical forms.
+	ucumUnits = map[string]UCUM{}
+
+	// metricUnits is the set of symbols of units that take prefixes.
+	metricUnits = map[string]bool{}
+)
+
+func init() {
+	for _, d := range ucumDefinitions {
+		metricUnits[d.symbol] = d.metric
+		if d.unit == "" {
+			ucumUnits[d.symbol] = unity.withDimension(d.symbol, 1)
+			continue
+		}
+		value, ok := new(big.Rat).SetString(d.value)
+		if !ok {
+			panic(fmt.Sprintf("invalid value of UCUM unit %s: %s", d.symbol, d.value))
+		}
+		unit, err := ParseUCUM(d.unit)
+		if err != nil {
+			panic(fmt.Sprintf("invalid definition of UCUM unit %s: %v", d.symbol, err))
+		}
+		ucumUnits[d.symbol] = unit.scale(value)
+	}
+}
+
+func ratPow10(exponent int) *big.Rat {
+	power := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(exponent, -exponent))), nil)
+	if exponent < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), power)
+	}
+	return new(big.Rat).SetInt(power)
+}
@@ -0,0 +1,118 @@
+package units_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/verily-src/fhirpath-go/internal/units"
+)
+
+func TestUCUM_Ratio(t *testing.T) {
+	testCases := []struct {
+		name string
+		from string
+		to   string
+		want *big.Rat
+	}{
+		{"prefixed mass", "g", "mg", big.NewRat(1000, 1)},
+		{"prefixed volume", "mL", "L", big.NewRat(1, 1000)},
+		{"liter as cubic decimeter", "L", "dm3", big.NewRat(1, 1)},
+		{"derived unit", "N", "kg.m/s2", big.NewRat(1, 1)},
+		{"division is left associative", "mg/dL/h", "g/L/min", big.NewRat(1, 6000)},
+		{"leading division", "/min", "s-1", big.NewRat(1, 60)},
+		{"parenthesized term", "kg/(m.s2)", "Pa", big.NewRat(1, 1)},
+		{"annotations are ignored", "mL/min/{1.73_m2}", "mL/min", big.NewRat(1, 1)},
+		{"annotation alone is unity", "{cells}/uL", "/uL", big.NewRat(1, 1)},
+		{"factor", "10*3/uL", "10*9/L", big.NewRat(1, 1)},
+		{"integer factor", "100", "%", big.NewRat(10000, 1)},
+		{"customary unit", "[lb_av]", "[oz_av]", big.NewRat(16, 1)},
+		{"customary to metric unit", "[in_i]", "cm", big.NewRat(254, 100)},
+		{"pressure", "mm[Hg]", "kPa", big.NewRat(133322, 1000000)},
+		{"julian year", "a", "d", big.NewRat(36525, 100)},
+		{"mean month", "a", "mo", big.NewRat(12, 1)},
+		{"week", "wk", "h", big.NewRat(168, 1)},
+		{"arbitrary unit", "[IU]/L", "m[iU]/mL", big.NewRat(1, 1)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			from, err := units.ParseUCUM(tc.from)
+			if err != nil {
+				t.Fatalf("ParseUCUM(%s) returned unexpected error: %v", tc.from, err)
+			}
+			to, err := units.ParseUCUM(tc.to)
+			if err != nil {
+				t.Fatalf("ParseUCUM(%s) returned unexpected error: %v", tc.to, err)
+			}
+
+			got, ok := from.Ratio(to)
+
+			if !ok || got.Cmp(tc.want) != 0 {
+				t.Errorf("Ratio(%s, %s) = %v, %v, want %v", tc.from, tc.to, got, ok, tc.want)
+			}
+		})
+	}
+}
+
+func TestUCUM_Ratio_NotCommensurable(t *testing.T) {
+	testCases := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{"mass and length", "g", "m"},
+		{"mass and mass concentration", "mg", "mg/dL"},
+		{"dimensionless and length", "%", "m"},
+		{"arbitrary units", "[iU]", "[CFU]"},
+		{"arbitrary and dimensionless units", "[iU]", "1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			from, err := units.ParseUCUM(tc.from)
+			if err != nil {
+				t.Fatalf("ParseUCUM(%s) returned unexpected error: %v", tc.from, err)
+			}
+			to, err := units.ParseUCUM(tc.to)
+			if err != nil {
+				t.Fatalf("ParseUCUM(%s) returned unexpected error: %v", tc.to, err)
+			}
+
+			if from.Commensurable(to) {
+				t.Errorf("Commensurable(%s, %s) = true, want false", tc.from, tc.to)
+			}
+			if _, ok := from.Ratio(to); ok {
+				t.Errorf("Ratio(%s, %s) returned ok, want not ok", tc.from, tc.to)
+			}
+		})
+	}
+}
+
+func TestParseUCUM_InvalidUnit_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expression string
+	}{
+		{"empty", ""},
+		{"unknown unit", "lbs"},
+		{"calendar keyword", "year"},
+		{"prefix on non-metric unit", "kmin"},
+		{"special unit", "Cel"},
+		{"missing operand", "mg/"},
+		{"unclosed parenthesis", "(mg/dL"},
+		{"unclosed bracket", "mm[Hg"},
+		{"unclosed annotation", "{cells"},
+		{"text after annotation", "{cells}g"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := units.ParseUCUM(tc.expression)
+
+			if !errors.Is(err, units.ErrInvalidUCUM) {
+				t.Errorf("ParseUCUM(%s) returned error %v, want %v", tc.expression, err, units.ErrInvalidUCUM)
+			}
+		})
+	}
+}